@@ -23,6 +23,9 @@ import (
 // +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:resource:scope="Cluster",shortName=cop
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ClusterOverridePolicy represents the cluster-wide policy that overrides a group of resources.
@@ -32,6 +35,10 @@ type ClusterOverridePolicy struct {
 
 	// Spec represents the desired behavior of ClusterOverridePolicy.
 	Spec OverridePolicySpec `json:"spec"`
+
+	// Status represents the observed state of ClusterOverridePolicy.
+	// +optional
+	Status PolicyStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:resource:scope="Cluster"
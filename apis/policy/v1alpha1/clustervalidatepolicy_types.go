@@ -29,9 +29,30 @@ type ClusterValidatePolicySpec struct {
 	// +optional
 	ResourceSelectors []ResourceSelector `json:"resourceSelectors,omitempty"`
 
+	// ExcludeResourceSelectors excludes resources matched by any of the selectors from this validate policy,
+	// it's evaluated after ResourceSelectors.
+	// +optional
+	ExcludeResourceSelectors []ResourceSelector `json:"excludeResourceSelectors,omitempty"`
+
 	// ValidateRules defines a collection of validate rules on target operations.
 	// +required
 	ValidateRules []ValidateRuleWithOperation `json:"validateRules"`
+
+	// EnforcementAction defines the action taken when a validate rule of this policy is violated.
+	// It can be overridden by the EnforcementAction of each rule.
+	// Defaults to deny.
+	// +kubebuilder:validation:Enum=deny;warn;audit
+	// +optional
+	EnforcementAction EnforcementAction `json:"enforcementAction,omitempty"`
+
+	// TimeoutSeconds limits the time of applying all validate rules of this policy to an object,
+	// including resolving references and executing cue and cel.
+	// The policy is cut off with an error once it's exceeded.
+	// nil means no timeout other than the one of the admission request.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // ValidateRuleWithOperation defines validate rules on operations.
@@ -42,10 +63,20 @@ type ValidateRuleWithOperation struct {
 	// Required.
 	TargetOperations []admissionv1.Operation `json:"targetOperations,omitempty"`
 
+	// RequestMatcher restricts the rule to admission requests matched.
+	RequestMatcher `json:",inline"`
+
 	// Cue represents validate rules defined with cue code.
 	// +optional
 	Cue string `json:"cue"`
 
+	// Cel represents validate rules defined with a CEL expression, which evaluates to
+	// a bool, true means allowed, or a string, empty means allowed and others are the reason of rejection,
+	// or a map in the form of `{"valid": bool, "reason": string, "fieldPath": string}`.
+	// Variables `object`, `oldObject`, `extraParams` and `request` are available.
+	// +optional
+	Cel string `json:"cel,omitempty"`
+
 	// Template of condition which defines validate cond, and
 	// it will be rendered to CUE and store in RenderedCue field, so
 	// if there are any data added manually will be erased.
@@ -56,8 +87,35 @@ type ValidateRuleWithOperation struct {
 	// Don't modify the value of this field, modify Rules instead of.
 	// +optional
 	RenderedCue string `json:"renderedCue,omitempty"`
+
+	// EnforcementAction defines the action taken when this rule is violated.
+	// Empty value means using the EnforcementAction of the policy.
+	// +kubebuilder:validation:Enum=deny;warn;audit
+	// +optional
+	EnforcementAction EnforcementAction `json:"enforcementAction,omitempty"`
+
+	// FailurePolicy defines how runtime errors of this rule are handled, e.g. referenced objects or
+	// http endpoints are unavailable, or cue or cel fails to execute.
+	// With Ignore, the policy is skipped as a whole and the others are still applied.
+	// Defaults to Fail.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +optional
+	FailurePolicy FailurePolicyType `json:"failurePolicy,omitempty"`
 }
 
+// EnforcementAction defines the action taken when a validate rule is violated.
+// +kubebuilder:validation:Enum=deny;warn;audit
+type EnforcementAction string
+
+const (
+	// EnforcementActionDeny - reject the operation.
+	EnforcementActionDeny EnforcementAction = "deny"
+	// EnforcementActionWarn - allow the operation and return admission warnings.
+	EnforcementActionWarn EnforcementAction = "warn"
+	// EnforcementActionAudit - allow the operation, only emit metrics and events.
+	EnforcementActionAudit EnforcementAction = "audit"
+)
+
 // ValidateRuleTemplate defines template for validate rule
 type ValidateRuleTemplate struct {
 	// Type represents current rule operate field type.
@@ -181,6 +239,9 @@ const (
 // +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:resource:scope="Cluster",shortName=cvp
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ClusterValidatePolicy represents the cluster-wide policy that validate a group of resources.
@@ -189,6 +250,10 @@ type ClusterValidatePolicy struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec ClusterValidatePolicySpec `json:"spec,omitempty"`
+
+	// Status represents the observed state of ClusterValidatePolicy.
+	// +optional
+	Status ValidatePolicyStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:resource:scope="Cluster"
@@ -32,9 +32,37 @@ type OverridePolicySpec struct {
 	// +optional
 	ResourceSelectors []ResourceSelector `json:"resourceSelectors,omitempty"`
 
+	// ExcludeResourceSelectors excludes resources matched by any of the selectors from this override policy,
+	// it's evaluated after ResourceSelectors.
+	// +optional
+	ExcludeResourceSelectors []ResourceSelector `json:"excludeResourceSelectors,omitempty"`
+
 	// OverrideRules defines a collection of override rules on target operations.
 	// +required
 	OverrideRules []RuleWithOperation `json:"overrideRules"`
+
+	// Priority defines the order in which matched policies are applied.
+	// Policies are applied in ascending order of priority, so overriders of a policy with higher priority
+	// are applied later and take precedence over the ones with lower priority.
+	// Policies with the same priority are applied in ascending order of name.
+	// Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// BackgroundApply indicates whether the policy is applied to existing resources in background
+	// when it's created, changed or deleted, instead of only taking effect on admission.
+	// It only works when the background reconciler is enabled.
+	// +optional
+	BackgroundApply bool `json:"backgroundApply,omitempty"`
+
+	// TimeoutSeconds limits the time of applying all overriders of this policy to an object,
+	// including resolving references and executing cue and cel.
+	// The policy is cut off with an error once it's exceeded.
+	// nil means no timeout other than the one of the admission request.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // RuleWithOperation defines the override rules on operations.
@@ -45,26 +73,123 @@ type RuleWithOperation struct {
 	// Required.
 	TargetOperations []admissionv1.Operation `json:"targetOperations,omitempty"`
 
+	// RequestMatcher restricts the rule to admission requests matched.
+	RequestMatcher `json:",inline"`
+
 	// Overriders represents the override rules that would apply on resources
 	// +required
 	Overriders Overriders `json:"overriders"`
+
+	// FailurePolicy defines how runtime errors of this rule are handled, e.g. referenced objects or
+	// http endpoints are unavailable, or cue or cel fails to execute.
+	// With Ignore, the policy is skipped as a whole and the others are still applied.
+	// Defaults to Fail.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +optional
+	FailurePolicy FailurePolicyType `json:"failurePolicy,omitempty"`
+}
+
+// FailurePolicyType defines how runtime errors of a rule are handled.
+// +kubebuilder:validation:Enum=Fail;Ignore
+type FailurePolicyType string
+
+const (
+	// FailurePolicyFail - fail the whole operation.
+	FailurePolicyFail FailurePolicyType = "Fail"
+	// FailurePolicyIgnore - skip the policy of the rule and continue with other policies.
+	FailurePolicyIgnore FailurePolicyType = "Ignore"
+)
+
+// RequestMatcher matches admission requests by the requesting user, subresource and dry-run flag.
+// All of the matchers set must match.
+// Requests not from admission, e.g. applying policies in background, are treated as made by an unknown user
+// on the main resource without dry-run.
+type RequestMatcher struct {
+	// UserInfo restricts the rule to requests made by users matching it.
+	// +optional
+	UserInfo *UserInfoMatcher `json:"userInfo,omitempty"`
+
+	// ExcludeUserInfo skips the rule for requests made by users matching it.
+	// +optional
+	ExcludeUserInfo *UserInfoMatcher `json:"excludeUserInfo,omitempty"`
+
+	// SubResources restricts the rule to requests on the subresources, e.g. 'scale' or 'status',
+	// "" means the main resource. Empty list means all subresources and the main resource.
+	// +optional
+	SubResources []string `json:"subResources,omitempty"`
+
+	// DryRun restricts the rule to dry-run requests if it's true, or requests without dry-run if it's false.
+	// nil means all requests.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+}
+
+// UserInfoMatcher matches the user of requests, a user matches if it matches any of the fields.
+// Glob patterns are supported in all fields.
+type UserInfoMatcher struct {
+	// Users is the usernames of the user.
+	// +optional
+	Users []string `json:"users,omitempty"`
+
+	// Groups is the groups of the user.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// ServiceAccounts is the service accounts of the user in the format of 'namespace/name'.
+	// +optional
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
 }
 
 // ResourceSelector the resources will be selected.
+// The type of target resources is selected by APIVersion, Kind, APIGroups, Versions, Kinds and Resources,
+// all of which that are set must match. At least one of them is required in resourceSelectors of policies,
+// and a selector without any of them in excludeResourceSelectors excludes all types of resources.
+// APIVersion and Kind are required when it refers to an object in k8s references.
 type ResourceSelector struct {
 	// APIVersion represents the API version of the target resources.
-	// +required
-	APIVersion string `json:"apiVersion"`
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
 
 	// Kind represents the Kind of the target resources.
-	// +required
-	Kind string `json:"kind"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// APIGroups is the API groups of the target resources, '*' means all groups and "" means the core group.
+	// Glob patterns are supported.
+	// +optional
+	APIGroups []string `json:"apiGroups,omitempty"`
+
+	// Versions is the API versions of the target resources, '*' means all versions.
+	// Glob patterns are supported.
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+
+	// Kinds is the kinds of the target resources, '*' means all kinds.
+	// Glob patterns are supported.
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Resources is the resource(plural) names of the target resources, e.g. 'deployments', '*' means all resources.
+	// Glob patterns are supported.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
 
 	// Namespace of the target resource.
 	// Default is empty, which means inherit from the parent object scope.
+	// Glob patterns are supported, e.g. `team-*` matches all namespaces starting with `team-`.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
+	// NamespaceRegex is a regular expression which the namespace of the target resource must fully match.
+	// +optional
+	NamespaceRegex string `json:"namespaceRegex,omitempty"`
+
+	// NamespaceSelector is a label query over the namespace of the target resource.
+	// For Namespace resource, it's evaluated against the Namespace itself.
+	// Cluster scoped resources never match a selector with namespaceSelector.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// Name of the target resource.
 	// Default is empty, which means selecting all resources.
 	// +optional
@@ -97,6 +222,12 @@ type Overriders struct {
 	// +optional
 	Cue string `json:"cue,omitempty"`
 
+	// Cel represents override rules defined with a CEL expression, which evaluates to a list of JSON patches,
+	// e.g. `[{"op": "add", "path": "/metadata/labels/foo", "value": "bar"}]`.
+	// Variables `object`, `oldObject`, `extraParams` and `request` are available.
+	// +optional
+	Cel string `json:"cel,omitempty"`
+
 	// Template of rule which defines override rule, and
 	// it will be rendered to CUE and store in RenderedCue field, so
 	//if there are any data added manually will be erased.
@@ -315,7 +446,7 @@ type ResourceRefer struct {
 	// Only when From is owner(means refer current object owner), the path can be empty.
 	// +optional
 	Path string `json:"path,omitempty"`
-	// K8s means refer another object from current cluster.
+	// K8s means refer another object from current cluster, apiVersion and kind are required.
 	// +optional
 	K8s *ResourceSelector `json:"k8s,omitempty"`
 	// Http means refer data from remote api.
@@ -346,6 +477,85 @@ type HttpDataRef struct {
 	//  and get token from response body. Response Body must be a valid json and contains token like this: `{"token": "xxx"} .
 	//	After get the token, the request will add a new key value to header, key is "Authorization" and value is "Bearer xxx".
 	Auth *HttpRequestAuth `json:"auth,omitempty"`
+	// CacheTTL enables caching the response for the duration, requests with the same method,
+	// rendered url, params and body share the cached response.
+	// nil means no cache, and the request is sent for every object.
+	// +optional
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+	// StaleWhileRevalidate is the duration after CacheTTL in which the expired response is still used
+	// while it's refreshed in background. It only works with CacheTTL.
+	// +optional
+	StaleWhileRevalidate *metav1.Duration `json:"staleWhileRevalidate,omitempty"`
+	// NegativeCacheTTL caches the error of failed requests for the duration, so the endpoint is not requested
+	// for every object while it's failing. It only works with CacheTTL.
+	// +optional
+	NegativeCacheTTL *metav1.Duration `json:"negativeCacheTTL,omitempty"`
+	// RetryPolicy defines how failed requests are retried.
+	// nil means no retry.
+	// +optional
+	RetryPolicy *HttpRetryPolicy `json:"retryPolicy,omitempty"`
+	// TLS defines the tls settings to request the url, e.g. custom CA and client certificate for mutual TLS.
+	// +optional
+	TLS *HttpTLSConfig `json:"tls,omitempty"`
+	// ProxyURL is the url of the proxy to request the url through, e.g. http://proxy.example.com:3128.
+	// Empty value means using the proxy from environment variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+}
+
+// HttpTLSConfig defines the tls settings of http requests,
+// certificates are read from Secrets rather than written in policies.
+type HttpTLSConfig struct {
+	// CA refers to the key of a Secret containing the PEM encoded CA bundle to verify the server certificate.
+	// The key defaults to ca.crt. nil means the system CA bundle.
+	// +optional
+	CA *SecretKeyReference `json:"ca,omitempty"`
+	// ClientCert refers to a Secret of type kubernetes.io/tls, whose tls.crt and tls.key
+	// are used as the client certificate and key for mutual TLS.
+	// +optional
+	ClientCert *SecretReference `json:"clientCert,omitempty"`
+	// ServerName is used to verify the hostname of the server certificate, it defaults to the host of the url.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables verifying the server certificate, it should only be used for testing.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// SecretReference refers to a Secret.
+type SecretReference struct {
+	// Namespace is the namespace of the Secret, it must be the namespace of the policy for namespaced policies.
+	// +required
+	Namespace string `json:"namespace"`
+	// Name is the name of the Secret.
+	// +required
+	Name string `json:"name"`
+}
+
+// SecretKeyReference refers to a key of a Secret.
+type SecretKeyReference struct {
+	SecretReference `json:",inline"`
+	// Key is the key in data of the Secret.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// HttpRetryPolicy defines how failed requests are retried, e.g. connection errors and 5xx or 429 responses.
+// Only requests with idempotent methods like GET are retried.
+type HttpRetryPolicy struct {
+	// MaxRetries is the max number of retries after the first attempt.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+	// Backoff is the interval before the first retry, it's doubled for every later retry.
+	// Defaults to 100ms.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+	// MaxBackoff caps the interval between retries.
+	// Defaults to 1s.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
 }
 
 // HttpRequestAuth defines basic info for get auth token from remote api
@@ -354,15 +564,39 @@ type HttpRequestAuth struct {
 	// StaticToken and other fields are mutually exclusive, staticToken is priority to take effect.
 	// +optional
 	StaticToken string `json:"staticToken,omitempty"`
+	// StaticTokenSecretRef refers to the key of a Secret containing the static token, the key defaults to token.
+	// It's the same as StaticToken but keeps the token out of policies.
+	// +optional
+	StaticTokenSecretRef *SecretKeyReference `json:"staticTokenSecretRef,omitempty"`
 	// Username represents username for auth.
 	// +optional
 	Username string `json:"username,omitempty"`
+	// UsernameSecretRef refers to the key of a Secret containing the username, the key defaults to username.
+	// +optional
+	UsernameSecretRef *SecretKeyReference `json:"usernameSecretRef,omitempty"`
 	// Password represents Password for auth.
 	// +optional
 	Password string `json:"password,omitempty"`
+	// PasswordSecretRef refers to the key of a Secret containing the password, the key defaults to password.
+	// +optional
+	PasswordSecretRef *SecretKeyReference `json:"passwordSecretRef,omitempty"`
 	// AuthURL represents remote url to request and get token.
 	// +optional
 	AuthURL string `json:"authUrl,omitempty"`
+	// OAuth2 requests tokens from AuthURL with the OAuth2 client credentials grant, the client id and secret are
+	// Username and Password or read from Secrets referred by UsernameSecretRef and PasswordSecretRef.
+	// OAuth2, ServiceAccountToken and JSON are mutually exclusive, tokens are requested from AuthURL with
+	// basic auth and a response like {"token": "xxx", "expireAt": 1663744200000} if none of them is set.
+	// +optional
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"`
+	// ServiceAccountToken sends the projected ServiceAccount token configured by the operator instead of
+	// requesting AuthURL, policies using it are rejected if the operator doesn't configure one.
+	// +optional
+	ServiceAccountToken *ServiceAccountTokenSource `json:"serviceAccountToken,omitempty"`
+	// JSON requests tokens from AuthURL with basic auth like default, and reads the token and expiry from
+	// the response with JSONPaths.
+	// +optional
+	JSON *JSONTokenSource `json:"json,omitempty"`
 	// ExpireDuration is providing for some auth api won't return exact expire time, so can you this field set
 	//  an expiry duration for token
 	// +optional
@@ -377,6 +611,54 @@ type HttpRequestAuth struct {
 	ExpireAt metav1.Time `json:"expireAt,omitempty"`
 }
 
+// OAuth2ClientCredentials defines the parameters of the OAuth2 client credentials grant.
+type OAuth2ClientCredentials struct {
+	// Scopes is the scopes of requested tokens.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// Audience is the intended audience of requested tokens, it's sent as parameter audience.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+}
+
+// ServiceAccountTokenSource sends the projected ServiceAccount token configured by the operator.
+// The path and audience of the token are operator configuration rather than fields of policies,
+// and the token is never written into policies.
+type ServiceAccountTokenSource struct {
+}
+
+// TokenExpiryFormat is the format of token expiry in responses.
+type TokenExpiryFormat string
+
+const (
+	// TokenExpiryUnixMilliseconds means the expiry is a unix timestamp in milliseconds.
+	TokenExpiryUnixMilliseconds TokenExpiryFormat = "UnixMilliseconds"
+	// TokenExpiryUnixSeconds means the expiry is a unix timestamp in seconds.
+	TokenExpiryUnixSeconds TokenExpiryFormat = "UnixSeconds"
+	// TokenExpiryRFC3339 means the expiry is a time in RFC3339 format.
+	TokenExpiryRFC3339 TokenExpiryFormat = "RFC3339"
+	// TokenExpiryExpiresInSeconds means the expiry is the number of seconds the token is valid for.
+	TokenExpiryExpiresInSeconds TokenExpiryFormat = "ExpiresInSeconds"
+)
+
+// JSONTokenSource defines how to read tokens from JSON responses.
+type JSONTokenSource struct {
+	// Method is the http method to request tokens, defaults to POST.
+	// +optional
+	Method string `json:"method,omitempty"`
+	// TokenPath is the JSONPath of the token in responses, e.g. {.data.accessToken}.
+	// +required
+	TokenPath string `json:"tokenPath"`
+	// ExpiryPath is the JSONPath of the expiry in responses, e.g. {.data.expiresIn}.
+	// ExpireDuration is used if it's empty.
+	// +optional
+	ExpiryPath string `json:"expiryPath,omitempty"`
+	// ExpiryFormat is the format of the expiry, defaults to UnixMilliseconds.
+	// +kubebuilder:validation:Enum=UnixMilliseconds;UnixSeconds;RFC3339;ExpiresInSeconds
+	// +optional
+	ExpiryFormat TokenExpiryFormat `json:"expiryFormat,omitempty"`
+}
+
 // ResourcesOversellRule defines factor of resource oversell
 type ResourcesOversellRule struct {
 	// CpuFactor factor of cup oversell, it is float number less than 1, the range of value is (0,1.0)
@@ -545,6 +827,9 @@ const (
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:resource:shortName=op
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // OverridePolicy represents the policy that overrides a group of resources.
 type OverridePolicy struct {
@@ -552,6 +837,10 @@ type OverridePolicy struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec OverridePolicySpec `json:"spec,omitempty"`
+
+	// Status represents the observed state of OverridePolicy.
+	// +optional
+	Status PolicyStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
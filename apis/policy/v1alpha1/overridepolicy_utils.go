@@ -29,3 +29,43 @@ func (p *ClusterOverridePolicy) GetNamespace() string {
 func (p *ClusterOverridePolicy) GetOverridePolicySpec() OverridePolicySpec {
 	return p.Spec
 }
+
+// GetPolicyStatus returns the status of OverridePolicy
+func (p *OverridePolicy) GetPolicyStatus() *PolicyStatus {
+	return &p.Status
+}
+
+// GetPolicyStatus returns the status of ClusterOverridePolicy
+func (p *ClusterOverridePolicy) GetPolicyStatus() *PolicyStatus {
+	return &p.Status
+}
+
+// GetPolicyStatus returns the status of ClusterValidatePolicy
+func (p *ClusterValidatePolicy) GetPolicyStatus() *PolicyStatus {
+	return &p.Status.PolicyStatus
+}
+
+// GetValidatePolicyStatus returns the status of ClusterValidatePolicy
+func (p *ClusterValidatePolicy) GetValidatePolicyStatus() *ValidatePolicyStatus {
+	return &p.Status
+}
+
+// GetPolicyStatus returns the status of ValidatePolicy
+func (p *ValidatePolicy) GetPolicyStatus() *PolicyStatus {
+	return &p.Status.PolicyStatus
+}
+
+// GetValidatePolicyStatus returns the status of ValidatePolicy
+func (p *ValidatePolicy) GetValidatePolicyStatus() *ValidatePolicyStatus {
+	return &p.Status
+}
+
+// GetValidatePolicySpec returns the ClusterValidatePolicySpec of ClusterValidatePolicy
+func (p *ClusterValidatePolicy) GetValidatePolicySpec() ClusterValidatePolicySpec {
+	return p.Spec
+}
+
+// GetValidatePolicySpec returns the ClusterValidatePolicySpec of ValidatePolicy
+func (p *ValidatePolicy) GetValidatePolicySpec() ClusterValidatePolicySpec {
+	return p.Spec
+}
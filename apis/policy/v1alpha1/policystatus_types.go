@@ -0,0 +1,141 @@
+/*
+Copyright 2022 by k-cloud-labs org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyStatus represents the observed state of a policy.
+type PolicyStatus struct {
+	// ObservedGeneration is the most recent generation observed for this policy.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represents the latest available observations of the policy's current state.
+	// Known condition types are `Ready`, `CueCompiled`, `TokenReady` and `ReferencesResolved`.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// MatchedCount is the number of admitted resources which matched this policy.
+	// +optional
+	MatchedCount int64 `json:"matchedCount,omitempty"`
+
+	// LastAppliedTime is the last time this policy was applied to a resource successfully.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// LastErrorTime is the last time this policy got an error when applied to a resource.
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+
+	// LastErrorMessage is the message of the last error.
+	// +optional
+	LastErrorMessage string `json:"lastErrorMessage,omitempty"`
+}
+
+// ValidatePolicyStatus represents the observed state of a validate policy.
+type ValidatePolicyStatus struct {
+	PolicyStatus `json:",inline"`
+
+	// Audit is the result of the last background audit of existing resources.
+	// +optional
+	Audit *AuditSummary `json:"audit,omitempty"`
+}
+
+// AuditSummary is the result of a background audit of existing resources matched by a validate policy.
+type AuditSummary struct {
+	// LastAuditTime is the time the audit finished.
+	LastAuditTime metav1.Time `json:"lastAuditTime"`
+
+	// PassCount is the number of resources which passed all rules of the policy.
+	PassCount int64 `json:"passCount"`
+
+	// FailCount is the number of resources which violated any rule of the policy.
+	FailCount int64 `json:"failCount"`
+
+	// SkipCount is the number of resources which were excluded by the policy or opted out.
+	// +optional
+	SkipCount int64 `json:"skipCount,omitempty"`
+
+	// ErrorCount is the number of resources which got an error when audited.
+	// +optional
+	ErrorCount int64 `json:"errorCount,omitempty"`
+
+	// Violations is the list of the first violating resources, the length is limited by the audit scanner.
+	// +optional
+	Violations []AuditViolation `json:"violations,omitempty"`
+
+	// Errors is the list of the first resources which got an error when audited, Message is the error.
+	// The length is limited by the audit scanner.
+	// +optional
+	Errors []AuditViolation `json:"errors,omitempty"`
+}
+
+// AuditViolation describes an existing resource which violated a validate policy.
+type AuditViolation struct {
+	// APIVersion of the resource.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource.
+	Kind string `json:"kind"`
+
+	// Namespace of the resource, empty for cluster scoped resource.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource.
+	Name string `json:"name"`
+
+	// Message is the messages of violated rules.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Condition types of policy status.
+const (
+	// PolicyConditionReady means all other conditions of the policy are satisfied.
+	PolicyConditionReady = "Ready"
+	// PolicyConditionCueCompiled means cue and rendered cue of the policy can be compiled.
+	PolicyConditionCueCompiled = "CueCompiled"
+	// PolicyConditionTokenReady means token of http references has been fetched by token manager.
+	PolicyConditionTokenReady = "TokenReady"
+	// PolicyConditionReferencesResolved means references(k8s object, owner or http) of the policy can be resolved.
+	PolicyConditionReferencesResolved = "ReferencesResolved"
+)
+
+// Condition reasons of policy status.
+const (
+	// PolicyReasonReady - all conditions are satisfied.
+	PolicyReasonReady = "PolicyReady"
+	// PolicyReasonCompiled - cue compiled successfully.
+	PolicyReasonCompiled = "Compiled"
+	// PolicyReasonCompileFailed - cue compiled with error.
+	PolicyReasonCompileFailed = "CompileFailed"
+	// PolicyReasonTokenFetched - token fetched successfully.
+	PolicyReasonTokenFetched = "TokenFetched"
+	// PolicyReasonTokenFetchFailed - token fetched with error.
+	PolicyReasonTokenFetchFailed = "TokenFetchFailed"
+	// PolicyReasonResolved - references resolved successfully.
+	PolicyReasonResolved = "Resolved"
+	// PolicyReasonResolveFailed - references resolved with error.
+	PolicyReasonResolveFailed = "ResolveFailed"
+)
@@ -12,8 +12,18 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/k-cloud-labs/pkg/builtin/registry"
+	"github.com/k-cloud-labs/pkg/utils/httpclient"
 )
 
+// requestTimeout is the timeout of each attempt of http tasks.
+const requestTimeout = 3 * time.Second
+
+// defaultClient is the client of http tasks without tls config.
+var defaultClient = httpclient.New(httpclient.Options{
+	Name:    "cue-http",
+	Timeout: requestTimeout,
+})
+
 func init() {
 	registry.RegisterRunner("http", newHTTPCmd)
 }
@@ -25,7 +35,9 @@ func newHTTPCmd(_ cue.Value) (registry.Runner, error) {
 	return &HTTPCmd{}, nil
 }
 
-// Run exec the actual http logic, and res represent the result of http task
+// Run exec the actual http logic, and res represent the result of http task.
+// The request is canceled once meta.Context is done.
+// It's retried by the optional retry field in the form of `{maxRetries: int, backoff: "100ms"}`.
 func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 	var header, trailer http.Header
 	var (
@@ -34,10 +46,7 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 	)
 	var (
 		r      io.Reader
-		client = &http.Client{
-			Transport: http.DefaultTransport,
-			Timeout:   time.Second * 3,
-		}
+		client = defaultClient
 	)
 	if obj := meta.Obj.Lookup("request"); obj.Exists() {
 		if v := obj.Lookup("body"); v.Exists() {
@@ -60,8 +69,17 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 	if meta.Err != nil {
 		return nil, meta.Err
 	}
+	retry, err := parseRetry(meta.Obj)
+	if err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequestWithContext(context.Background(), method, u, r)
+	ctx := meta.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +119,13 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 			tr.TLSClientConfig.Certificates = []tls.Certificate{cliCrt}
 		}
 
-		client.Transport = tr
+		client = httpclient.New(httpclient.Options{
+			Name:      "cue-http",
+			Timeout:   requestTimeout,
+			Transport: tr,
+		})
 	}
-	resp, err := client.Do(req)
+	resp, err := client.Do(req, retry)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +140,34 @@ func (c *HTTPCmd) Run(meta *registry.Meta) (res interface{}, err error) {
 	}, err
 }
 
+// parseRetry parses the retry policy of the task, nil means no retry.
+func parseRetry(obj cue.Value) (*httpclient.RetryPolicy, error) {
+	v := obj.Lookup("retry")
+	if !v.Exists() {
+		return nil, nil
+	}
+
+	policy := &httpclient.RetryPolicy{}
+	if maxRetries := v.Lookup("maxRetries"); maxRetries.Exists() {
+		n, err := maxRetries.Int64()
+		if err != nil {
+			return nil, errors.WithMessage(err, "parse retry")
+		}
+		policy.MaxRetries = int(n)
+	}
+	if backoff := v.Lookup("backoff"); backoff.Exists() {
+		str, err := backoff.String()
+		if err != nil {
+			return nil, errors.WithMessage(err, "parse retry")
+		}
+		if policy.Backoff, err = time.ParseDuration(str); err != nil {
+			return nil, errors.WithMessage(err, "parse retry")
+		}
+	}
+
+	return policy, nil
+}
+
 func parseHeaders(obj cue.Value, label string) (http.Header, error) {
 	m := obj.Lookup(label)
 	if !m.Exists() {
@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"cuelang.org/go/cue"
@@ -107,6 +108,39 @@ url: "https://127.0.0.1:8443/api/v1/token?val=test-token"`)
 	assert.Equal(t, "{\"token\":\"test-token\"}", body)
 }
 
+func TestHTTPCmdRunRetry(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"retried"}`))
+	}))
+	defer s.Close()
+
+	r := cue.Runtime{}
+	reqInst, err := r.Compile("-", fmt.Sprintf(`method: "GET"
+url: %q
+retry: {
+  maxRetries: 1
+  backoff: "10ms"
+}`, s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner, _ := newHTTPCmd(cue.Value{})
+	got, err := runner.Run(&registry.Meta{Obj: reqInst.Value()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := (got.(map[string]interface{}))["body"].(string)
+
+	assert.Equal(t, "{\"token\":\"retried\"}", body)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
 // newMockHttpServer mock the http server
 func newMockHttpServer() *httptest.Server {
 	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
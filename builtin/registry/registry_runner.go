@@ -12,6 +12,8 @@ import (
 
 // Meta provides context for running a task.
 type Meta struct {
+	// Context is the context of running the task, runners should stop once it's done.
+	// nil means context.Background().
 	Context context.Context
 	Stdin   io.Reader
 	Stdout  io.Writer
@@ -39,6 +39,7 @@ type ClusterOverridePoliciesGetter interface {
 type ClusterOverridePolicyInterface interface {
 	Create(ctx context.Context, clusterOverridePolicy *v1alpha1.ClusterOverridePolicy, opts v1.CreateOptions) (*v1alpha1.ClusterOverridePolicy, error)
 	Update(ctx context.Context, clusterOverridePolicy *v1alpha1.ClusterOverridePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterOverridePolicy, error)
+	UpdateStatus(ctx context.Context, clusterOverridePolicy *v1alpha1.ClusterOverridePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterOverridePolicy, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ClusterOverridePolicy, error)
@@ -127,6 +128,21 @@ func (c *clusterOverridePolicies) Update(ctx context.Context, clusterOverridePol
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *clusterOverridePolicies) UpdateStatus(ctx context.Context, clusterOverridePolicy *v1alpha1.ClusterOverridePolicy, opts v1.UpdateOptions) (result *v1alpha1.ClusterOverridePolicy, err error) {
+	result = &v1alpha1.ClusterOverridePolicy{}
+	err = c.client.Put().
+		Resource("clusteroverridepolicies").
+		Name(clusterOverridePolicy.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(clusterOverridePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the clusterOverridePolicy and deletes it. Returns an error if one occurs.
 func (c *clusterOverridePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
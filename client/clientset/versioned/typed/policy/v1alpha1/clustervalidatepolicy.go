@@ -39,6 +39,7 @@ type ClusterValidatePoliciesGetter interface {
 type ClusterValidatePolicyInterface interface {
 	Create(ctx context.Context, clusterValidatePolicy *v1alpha1.ClusterValidatePolicy, opts v1.CreateOptions) (*v1alpha1.ClusterValidatePolicy, error)
 	Update(ctx context.Context, clusterValidatePolicy *v1alpha1.ClusterValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterValidatePolicy, error)
+	UpdateStatus(ctx context.Context, clusterValidatePolicy *v1alpha1.ClusterValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterValidatePolicy, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ClusterValidatePolicy, error)
@@ -127,6 +128,21 @@ func (c *clusterValidatePolicies) Update(ctx context.Context, clusterValidatePol
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *clusterValidatePolicies) UpdateStatus(ctx context.Context, clusterValidatePolicy *v1alpha1.ClusterValidatePolicy, opts v1.UpdateOptions) (result *v1alpha1.ClusterValidatePolicy, err error) {
+	result = &v1alpha1.ClusterValidatePolicy{}
+	err = c.client.Put().
+		Resource("clustervalidatepolicies").
+		Name(clusterValidatePolicy.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(clusterValidatePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the clusterValidatePolicy and deletes it. Returns an error if one occurs.
 func (c *clusterValidatePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -95,6 +95,17 @@ func (c *FakeClusterOverridePolicies) Update(ctx context.Context, clusterOverrid
 	return obj.(*v1alpha1.ClusterOverridePolicy), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeClusterOverridePolicies) UpdateStatus(ctx context.Context, clusterOverridePolicy *v1alpha1.ClusterOverridePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterOverridePolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(clusteroverridepoliciesResource, "status", clusterOverridePolicy), &v1alpha1.ClusterOverridePolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterOverridePolicy), err
+}
+
 // Delete takes name of the clusterOverridePolicy and deletes it. Returns an error if one occurs.
 func (c *FakeClusterOverridePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -95,6 +95,17 @@ func (c *FakeClusterValidatePolicies) Update(ctx context.Context, clusterValidat
 	return obj.(*v1alpha1.ClusterValidatePolicy), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeClusterValidatePolicies) UpdateStatus(ctx context.Context, clusterValidatePolicy *v1alpha1.ClusterValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ClusterValidatePolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(clustervalidatepoliciesResource, "status", clusterValidatePolicy), &v1alpha1.ClusterValidatePolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterValidatePolicy), err
+}
+
 // Delete takes name of the clusterValidatePolicy and deletes it. Returns an error if one occurs.
 func (c *FakeClusterValidatePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
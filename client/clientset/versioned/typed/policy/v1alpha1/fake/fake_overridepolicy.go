@@ -101,6 +101,18 @@ func (c *FakeOverridePolicies) Update(ctx context.Context, overridePolicy *v1alp
 	return obj.(*v1alpha1.OverridePolicy), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeOverridePolicies) UpdateStatus(ctx context.Context, overridePolicy *v1alpha1.OverridePolicy, opts v1.UpdateOptions) (*v1alpha1.OverridePolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(overridepoliciesResource, "status", c.ns, overridePolicy), &v1alpha1.OverridePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.OverridePolicy), err
+}
+
 // Delete takes name of the overridePolicy and deletes it. Returns an error if one occurs.
 func (c *FakeOverridePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -39,6 +39,10 @@ func (c *FakePolicyV1alpha1) OverridePolicies(namespace string) v1alpha1.Overrid
 	return &FakeOverridePolicies{c, namespace}
 }
 
+func (c *FakePolicyV1alpha1) ValidatePolicies(namespace string) v1alpha1.ValidatePolicyInterface {
+	return &FakeValidatePolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePolicyV1alpha1) RESTClient() rest.Interface {
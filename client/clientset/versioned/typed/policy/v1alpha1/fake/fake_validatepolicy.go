@@ -0,0 +1,141 @@
+/*
+Copyright 2022 by k-cloud-labs org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeValidatePolicies implements ValidatePolicyInterface
+type FakeValidatePolicies struct {
+	Fake *FakePolicyV1alpha1
+	ns   string
+}
+
+var validatepoliciesResource = schema.GroupVersionResource{Group: "policy.kcloudlabs.io", Version: "v1alpha1", Resource: "validatepolicies"}
+
+var validatepoliciesKind = schema.GroupVersionKind{Group: "policy.kcloudlabs.io", Version: "v1alpha1", Kind: "ValidatePolicy"}
+
+// Get takes name of the validatePolicy, and returns the corresponding validatePolicy object, and an error if there is any.
+func (c *FakeValidatePolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(validatepoliciesResource, c.ns, name), &v1alpha1.ValidatePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ValidatePolicy), err
+}
+
+// List takes label and field selectors, and returns the list of ValidatePolicies that match those selectors.
+func (c *FakeValidatePolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ValidatePolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(validatepoliciesResource, validatepoliciesKind, c.ns, opts), &v1alpha1.ValidatePolicyList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ValidatePolicyList{ListMeta: obj.(*v1alpha1.ValidatePolicyList).ListMeta}
+	for _, item := range obj.(*v1alpha1.ValidatePolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested validatePolicies.
+func (c *FakeValidatePolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(validatepoliciesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a validatePolicy and creates it.  Returns the server's representation of the validatePolicy, and an error, if there is any.
+func (c *FakeValidatePolicies) Create(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.CreateOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(validatepoliciesResource, c.ns, validatePolicy), &v1alpha1.ValidatePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ValidatePolicy), err
+}
+
+// Update takes the representation of a validatePolicy and updates it. Returns the server's representation of the validatePolicy, and an error, if there is any.
+func (c *FakeValidatePolicies) Update(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(validatepoliciesResource, c.ns, validatePolicy), &v1alpha1.ValidatePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ValidatePolicy), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeValidatePolicies) UpdateStatus(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ValidatePolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(validatepoliciesResource, "status", c.ns, validatePolicy), &v1alpha1.ValidatePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ValidatePolicy), err
+}
+
+// Delete takes name of the validatePolicy and deletes it. Returns an error if one occurs.
+func (c *FakeValidatePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(validatepoliciesResource, c.ns, name, opts), &v1alpha1.ValidatePolicy{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeValidatePolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(validatepoliciesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ValidatePolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched validatePolicy.
+func (c *FakeValidatePolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ValidatePolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(validatepoliciesResource, c.ns, name, pt, data, subresources...), &v1alpha1.ValidatePolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ValidatePolicy), err
+}
@@ -22,3 +22,5 @@ type ClusterOverridePolicyExpansion interface{}
 type ClusterValidatePolicyExpansion interface{}
 
 type OverridePolicyExpansion interface{}
+
+type ValidatePolicyExpansion interface{}
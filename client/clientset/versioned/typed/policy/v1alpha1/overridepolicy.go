@@ -39,6 +39,7 @@ type OverridePoliciesGetter interface {
 type OverridePolicyInterface interface {
 	Create(ctx context.Context, overridePolicy *v1alpha1.OverridePolicy, opts v1.CreateOptions) (*v1alpha1.OverridePolicy, error)
 	Update(ctx context.Context, overridePolicy *v1alpha1.OverridePolicy, opts v1.UpdateOptions) (*v1alpha1.OverridePolicy, error)
+	UpdateStatus(ctx context.Context, overridePolicy *v1alpha1.OverridePolicy, opts v1.UpdateOptions) (*v1alpha1.OverridePolicy, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.OverridePolicy, error)
@@ -134,6 +135,22 @@ func (c *overridePolicies) Update(ctx context.Context, overridePolicy *v1alpha1.
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *overridePolicies) UpdateStatus(ctx context.Context, overridePolicy *v1alpha1.OverridePolicy, opts v1.UpdateOptions) (result *v1alpha1.OverridePolicy, err error) {
+	result = &v1alpha1.OverridePolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("overridepolicies").
+		Name(overridePolicy.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(overridePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the overridePolicy and deletes it. Returns an error if one occurs.
 func (c *overridePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
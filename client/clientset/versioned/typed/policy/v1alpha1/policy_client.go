@@ -30,6 +30,7 @@ type PolicyV1alpha1Interface interface {
 	ClusterOverridePoliciesGetter
 	ClusterValidatePoliciesGetter
 	OverridePoliciesGetter
+	ValidatePoliciesGetter
 }
 
 // PolicyV1alpha1Client is used to interact with features provided by the policy.kcloudlabs.io group.
@@ -49,6 +50,10 @@ func (c *PolicyV1alpha1Client) OverridePolicies(namespace string) OverridePolicy
 	return newOverridePolicies(c, namespace)
 }
 
+func (c *PolicyV1alpha1Client) ValidatePolicies(namespace string) ValidatePolicyInterface {
+	return newValidatePolicies(c, namespace)
+}
+
 // NewForConfig creates a new PolicyV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
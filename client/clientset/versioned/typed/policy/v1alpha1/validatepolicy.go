@@ -0,0 +1,194 @@
+/*
+Copyright 2022 by k-cloud-labs org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	scheme "github.com/k-cloud-labs/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ValidatePoliciesGetter has a method to return a ValidatePolicyInterface.
+// A group's client should implement this interface.
+type ValidatePoliciesGetter interface {
+	ValidatePolicies(namespace string) ValidatePolicyInterface
+}
+
+// ValidatePolicyInterface has methods to work with ValidatePolicy resources.
+type ValidatePolicyInterface interface {
+	Create(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.CreateOptions) (*v1alpha1.ValidatePolicy, error)
+	Update(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ValidatePolicy, error)
+	UpdateStatus(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (*v1alpha1.ValidatePolicy, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.ValidatePolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.ValidatePolicyList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ValidatePolicy, err error)
+	ValidatePolicyExpansion
+}
+
+// validatePolicies implements ValidatePolicyInterface
+type validatePolicies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newValidatePolicies returns a ValidatePolicies
+func newValidatePolicies(c *PolicyV1alpha1Client, namespace string) *validatePolicies {
+	return &validatePolicies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the validatePolicy, and returns the corresponding validatePolicy object, and an error if there is any.
+func (c *validatePolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	result = &v1alpha1.ValidatePolicy{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ValidatePolicies that match those selectors.
+func (c *validatePolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.ValidatePolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.ValidatePolicyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested validatePolicies.
+func (c *validatePolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a validatePolicy and creates it.  Returns the server's representation of the validatePolicy, and an error, if there is any.
+func (c *validatePolicies) Create(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.CreateOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	result = &v1alpha1.ValidatePolicy{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(validatePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a validatePolicy and updates it. Returns the server's representation of the validatePolicy, and an error, if there is any.
+func (c *validatePolicies) Update(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	result = &v1alpha1.ValidatePolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		Name(validatePolicy.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(validatePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *validatePolicies) UpdateStatus(ctx context.Context, validatePolicy *v1alpha1.ValidatePolicy, opts v1.UpdateOptions) (result *v1alpha1.ValidatePolicy, err error) {
+	result = &v1alpha1.ValidatePolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		Name(validatePolicy.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(validatePolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the validatePolicy and deletes it. Returns an error if one occurs.
+func (c *validatePolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *validatePolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched validatePolicy.
+func (c *validatePolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.ValidatePolicy, err error) {
+	result = &v1alpha1.ValidatePolicy{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("validatepolicies").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -29,6 +29,8 @@ type Interface interface {
 	ClusterValidatePolicies() ClusterValidatePolicyInformer
 	// OverridePolicies returns a OverridePolicyInformer.
 	OverridePolicies() OverridePolicyInformer
+	// ValidatePolicies returns a ValidatePolicyInformer.
+	ValidatePolicies() ValidatePolicyInformer
 }
 
 type version struct {
@@ -56,3 +58,8 @@ func (v *version) ClusterValidatePolicies() ClusterValidatePolicyInformer {
 func (v *version) OverridePolicies() OverridePolicyInformer {
 	return &overridePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// ValidatePolicies returns a ValidatePolicyInformer.
+func (v *version) ValidatePolicies() ValidatePolicyInformer {
+	return &validatePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
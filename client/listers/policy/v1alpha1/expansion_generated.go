@@ -32,3 +32,11 @@ type OverridePolicyListerExpansion interface{}
 // OverridePolicyNamespaceListerExpansion allows custom methods to be added to
 // OverridePolicyNamespaceLister.
 type OverridePolicyNamespaceListerExpansion interface{}
+
+// ValidatePolicyListerExpansion allows custom methods to be added to
+// ValidatePolicyLister.
+type ValidatePolicyListerExpansion interface{}
+
+// ValidatePolicyNamespaceListerExpansion allows custom methods to be added to
+// ValidatePolicyNamespaceLister.
+type ValidatePolicyNamespaceListerExpansion interface{}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 by k-cloud-labs org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ValidatePolicyLister helps list ValidatePolicies.
+// All objects returned here must be treated as read-only.
+type ValidatePolicyLister interface {
+	// List lists all ValidatePolicies in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ValidatePolicy, err error)
+	// ValidatePolicies returns an object that can list and get ValidatePolicies.
+	ValidatePolicies(namespace string) ValidatePolicyNamespaceLister
+	ValidatePolicyListerExpansion
+}
+
+// validatePolicyLister implements the ValidatePolicyLister interface.
+type validatePolicyLister struct {
+	indexer cache.Indexer
+}
+
+// NewValidatePolicyLister returns a new ValidatePolicyLister.
+func NewValidatePolicyLister(indexer cache.Indexer) ValidatePolicyLister {
+	return &validatePolicyLister{indexer: indexer}
+}
+
+// List lists all ValidatePolicies in the indexer.
+func (s *validatePolicyLister) List(selector labels.Selector) (ret []*v1alpha1.ValidatePolicy, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ValidatePolicy))
+	})
+	return ret, err
+}
+
+// ValidatePolicies returns an object that can list and get ValidatePolicies.
+func (s *validatePolicyLister) ValidatePolicies(namespace string) ValidatePolicyNamespaceLister {
+	return validatePolicyNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// ValidatePolicyNamespaceLister helps list and get ValidatePolicies.
+// All objects returned here must be treated as read-only.
+type ValidatePolicyNamespaceLister interface {
+	// List lists all ValidatePolicies in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.ValidatePolicy, err error)
+	// Get retrieves the ValidatePolicy from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.ValidatePolicy, error)
+	ValidatePolicyNamespaceListerExpansion
+}
+
+// validatePolicyNamespaceLister implements the ValidatePolicyNamespaceLister
+// interface.
+type validatePolicyNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all ValidatePolicies in the indexer for a given namespace.
+func (s validatePolicyNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.ValidatePolicy, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ValidatePolicy))
+	})
+	return ret, err
+}
+
+// Get retrieves the ValidatePolicy from the indexer for a given namespace and name.
+func (s validatePolicyNamespaceLister) Get(name string) (*v1alpha1.ValidatePolicy, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("validatepolicy"), name)
+	}
+	return obj.(*v1alpha1.ValidatePolicy), nil
+}
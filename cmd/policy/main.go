@@ -0,0 +1,66 @@
+// Command policy runs declarative tests of policies offline.
+//
+//	policy test ./policies
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/k-cloud-labs/pkg/utils/policytest"
+)
+
+const usage = `Usage:
+  policy test <path>...
+
+Load policies and PolicyTest files from yaml files in paths and run the tests.
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 || args[0] != "test" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	os.Exit(runTest(args[1:]))
+}
+
+func runTest(paths []string) int {
+	suite, err := policytest.LoadSuite(paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load policies err=%v\n", err)
+		return 1
+	}
+
+	results, err := policytest.NewRunner(suite).Run(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run tests err=%v\n", err)
+		return 1
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.Passed {
+			fmt.Printf("PASS %s/%s\n", result.Test, result.Case)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL %s/%s\n%s\n", result.Test, result.Case, result.Message)
+	}
+
+	fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
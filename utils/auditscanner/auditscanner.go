@@ -0,0 +1,229 @@
+package auditscanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
+	"github.com/k-cloud-labs/pkg/utils/metrics"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
+	"github.com/k-cloud-labs/pkg/utils/util"
+	"github.com/k-cloud-labs/pkg/utils/validatemanager"
+)
+
+const (
+	defaultScanInterval  = time.Hour
+	defaultMaxViolations = 20
+)
+
+// AuditScanner audits existing resources against ClusterValidatePolicy in background,
+// since validate policies only take effect on admission.
+// The result of each policy is written to status.audit of the policy and exported as metrics.
+type AuditScanner interface {
+	// Scan audits resources matched by resource selectors of all ClusterValidatePolicy once.
+	// Policies without resource selectors are skipped since they match all kinds of resources,
+	// and so are selectors which don't select a single kind, e.g. selectors with wildcards.
+	Scan(ctx context.Context) error
+	// Start scans periodically until ctx done.
+	Start(ctx context.Context)
+}
+
+type auditScannerImpl struct {
+	dynamicLister   dynamiclister.DynamicResourceLister
+	cvpLister       v1alpha1.ClusterValidatePolicyLister
+	validateManager validatemanager.ValidateManager
+	statusManager   statusmanager.StatusManager
+	interval        time.Duration
+	maxViolations   int
+
+	// audited is the name of policies audited in the last scan.
+	audited map[string]bool
+}
+
+// NewAuditScanner returns an implement of AuditScanner.
+// interval with value '0' means use default scan interval,
+// maxViolations limits the number of violating or failed resources recorded in status, '0' means use default limit.
+// If sm is nil, the result is only exported as metrics.
+func NewAuditScanner(dynamicLister dynamiclister.DynamicResourceLister, cvpLister v1alpha1.ClusterValidatePolicyLister,
+	vm validatemanager.ValidateManager, sm statusmanager.StatusManager, interval time.Duration, maxViolations int) AuditScanner {
+	if sm == nil {
+		sm = statusmanager.NewNopStatusManager()
+	}
+	if interval <= 0 {
+		interval = defaultScanInterval
+	}
+	if maxViolations <= 0 {
+		maxViolations = defaultMaxViolations
+	}
+
+	return &auditScannerImpl{
+		dynamicLister:   dynamicLister,
+		cvpLister:       cvpLister,
+		validateManager: vm,
+		statusManager:   sm,
+		interval:        interval,
+		maxViolations:   maxViolations,
+		audited:         make(map[string]bool),
+	}
+}
+
+func (s *auditScannerImpl) Start(ctx context.Context) {
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Scan(ctx); err != nil {
+			klog.ErrorS(err, "audit existing resources failed")
+		}
+	}, s.interval)
+}
+
+func (s *auditScannerImpl) Scan(ctx context.Context) error {
+	cvps, err := s.cvpLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	errs := util.NewErrorSet()
+	audited := make(map[string]bool, len(cvps))
+	for _, cvp := range cvps {
+		if len(cvp.Spec.ResourceSelectors) == 0 {
+			klog.V(2).InfoS("Skip audit policy without resource selectors.", "clustervalidatepolicy", cvp.Name)
+			continue
+		}
+
+		summary, err := s.auditPolicy(ctx, cvp)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("audit policy(%s) got error=%w", cvp.Name, err))
+			continue
+		}
+
+		audited[cvp.Name] = true
+		metrics.SetAuditResult(cvp.Name, summary.PassCount, summary.FailCount)
+		s.statusManager.SetAuditSummary(statusmanager.PolicyKey{Kind: statusmanager.KindClusterValidatePolicy, Name: cvp.Name}, summary)
+		klog.V(2).InfoS("Audited existing resources.", "clustervalidatepolicy", cvp.Name, "pass", summary.PassCount, "fail", summary.FailCount)
+	}
+
+	// policies deleted or no longer audited
+	for name := range s.audited {
+		if !audited[name] {
+			metrics.DeleteAuditResult(name)
+		}
+	}
+	s.audited = audited
+
+	return errs.Err()
+}
+
+func (s *auditScannerImpl) auditPolicy(ctx context.Context, cvp *policyv1alpha1.ClusterValidatePolicy) (*policyv1alpha1.AuditSummary, error) {
+	objs, err := s.listMatchedObjects(ctx, cvp.Spec.ResourceSelectors)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &policyv1alpha1.AuditSummary{}
+	for _, obj := range objs {
+		violations, skipped, err := s.validateManager.AuditValidatePolicy(ctx, cvp, obj)
+		switch {
+		case err != nil:
+			// keep auditing other resources
+			klog.ErrorS(err, "Failed to audit resource.", "clustervalidatepolicy", cvp.Name, "resource", klog.KObj(obj))
+			summary.ErrorCount++
+			if len(summary.Errors) < s.maxViolations {
+				summary.Errors = append(summary.Errors, auditViolation(obj, err.Error()))
+			}
+			continue
+		case skipped:
+			summary.SkipCount++
+			continue
+		case len(violations) == 0:
+			summary.PassCount++
+			continue
+		}
+
+		summary.FailCount++
+		if len(summary.Violations) >= s.maxViolations {
+			continue
+		}
+
+		msgs := make([]string, 0, len(violations))
+		for _, v := range violations {
+			msgs = append(msgs, v.String())
+		}
+		summary.Violations = append(summary.Violations, auditViolation(obj, strings.Join(msgs, "; ")))
+	}
+	summary.LastAuditTime = metav1.Now()
+
+	return summary, nil
+}
+
+func auditViolation(obj *unstructured.Unstructured, message string) policyv1alpha1.AuditViolation {
+	return policyv1alpha1.AuditViolation{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Message:    message,
+	}
+}
+
+// listMatchedObjects lists objects matched by any of selectors, each object is returned only once.
+func (s *auditScannerImpl) listMatchedObjects(ctx context.Context, selectors []policyv1alpha1.ResourceSelector) ([]*unstructured.Unstructured, error) {
+	var (
+		result []*unstructured.Unstructured
+		seen   = make(map[string]bool)
+		mc     = dynamiclister.NewMatchContext(ctx, s.dynamicLister)
+	)
+	for _, rs := range selectors {
+		gvk, ok := utils.SelectedGVK(rs)
+		if !ok {
+			klog.V(2).InfoS("Skip audit resource selector without a single kind.", "selector", rs)
+			continue
+		}
+
+		if err := s.dynamicLister.RegisterNewResource(true, gvk); err != nil {
+			return nil, err
+		}
+
+		lister, err := s.dynamicLister.GVKToResourceLister(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+
+		var objs []runtime.Object
+		if rs.Namespace != "" && !utils.IsGlobPattern(rs.Namespace) {
+			objs, err = lister.ByNamespace(rs.Namespace).List(labels.Everything())
+		} else {
+			objs, err = lister.List(labels.Everything())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok || !utils.ResourceMatchesWithContext(u, mc, rs) {
+				continue
+			}
+
+			key := fmt.Sprintf("%s/%s/%s", gvk.String(), u.GetNamespace(), u.GetName())
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			result = append(result, u)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package auditscanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/test/mock"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister/fake"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
+	"github.com/k-cloud-labs/pkg/utils/validatemanager"
+)
+
+type fakeStatusManager struct {
+	statusmanager.StatusManager
+	summaries map[statusmanager.PolicyKey]*policyv1alpha1.AuditSummary
+}
+
+func (f *fakeStatusManager) SetAuditSummary(key statusmanager.PolicyKey, summary *policyv1alpha1.AuditSummary) {
+	f.summaries[key] = summary
+}
+
+func TestAuditScannerImpl_Scan(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	var objects []runtime.Object
+	for name, version := range map[string]string{"a": "latest", "b": "latest", "c": "v1", "d": "excluded", "e": "error"} {
+		deployment := helper.NewDeployment(metav1.NamespaceDefault, name)
+		deployment.Labels = map[string]string{"version": version}
+		objects = append(objects, deployment)
+	}
+	dynamicLister, err := fake.NewFakeDynamicResourceLister(done, objects...)
+	if err != nil {
+		t.Fatalf("new fake dynamic lister err=%v", err)
+	}
+
+	cvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "forbid-latest"},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ResourceSelectors: []policyv1alpha1.ResourceSelector{
+				{APIVersion: "apps/v1", Kind: "Deployment"},
+			},
+			ExcludeResourceSelectors: []policyv1alpha1.ResourceSelector{
+				{
+					APIVersion:    "apps/v1",
+					Kind:          "Deployment",
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"version": "excluded"}},
+				},
+			},
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					EnforcementAction: policyv1alpha1.EnforcementActionAudit,
+					Cue: `
+object: _ @tag(object)
+
+validate: {
+	if object.metadata.labels.version == "latest" {
+		reason: "version latest is forbidden"
+		valid:  false
+	}
+	if object.metadata.labels.version == "error" {
+		valid: object.spec.missing
+	}
+	if object.metadata.labels.version != "latest" && object.metadata.labels.version != "error" {
+		valid: true
+	}
+}
+`,
+				},
+			},
+		},
+	}
+	noSelectorCvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-selector"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
+	cvpLister.EXPECT().List(gomock.Any()).Return([]*policyv1alpha1.ClusterValidatePolicy{cvp, noSelectorCvp}, nil).AnyTimes()
+
+	sm := &fakeStatusManager{
+		StatusManager: statusmanager.NewNopStatusManager(),
+		summaries:     make(map[statusmanager.PolicyKey]*policyv1alpha1.AuditSummary),
+	}
+	vm := validatemanager.NewValidateManager(dynamicLister, cvpLister, nil, validatemanager.ValidateManagerOptions{})
+	s := NewAuditScanner(dynamicLister, cvpLister, vm, sm, 0, 1)
+	if err := s.Scan(context.Background()); err != nil {
+		t.Fatalf("Scan() err=%v", err)
+	}
+
+	if len(sm.summaries) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(sm.summaries))
+	}
+
+	summary := sm.summaries[statusmanager.PolicyKey{Kind: statusmanager.KindClusterValidatePolicy, Name: cvp.Name}]
+	// the excluded resource is skipped, and the error of a resource doesn't abort the audit of others
+	if summary == nil || summary.PassCount != 1 || summary.FailCount != 2 || summary.SkipCount != 1 || summary.ErrorCount != 1 ||
+		len(summary.Violations) != 1 || len(summary.Errors) != 1 || summary.Errors[0].Name != "e" {
+		t.Fatalf("unexpected summary=%+v", summary)
+	}
+
+	if v := summary.Violations[0]; v.Kind != "Deployment" || v.Namespace != metav1.NamespaceDefault ||
+		v.Message != "policy forbid-latest rule[0]: version latest is forbidden" {
+		t.Errorf("unexpected violation=%+v", v)
+	}
+}
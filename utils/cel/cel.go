@@ -0,0 +1,213 @@
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cue"
+)
+
+// Define variable names of CEL expressions
+const (
+	// ObjectVariableName is the object in admission request
+	ObjectVariableName = "object"
+	// OldObjectVariableName is the old object in admission request, it's an empty map if not exist
+	OldObjectVariableName = "oldObject"
+	// ExtraParamsVariableName is the values resolved by valueRef of template
+	ExtraParamsVariableName = "extraParams"
+	// RequestVariableName is the metadata of admission request, see Request
+	RequestVariableName = "request"
+)
+
+// costLimit limits the cost of evaluating an expression to avoid expensive expressions blocking admission.
+const costLimit = 1000000
+
+// interruptCheckFrequency is the number of iterations of comprehensions between checks of context cancellation.
+const interruptCheckFrequency = 100
+
+// Parameters is the variables of CEL expressions, which is the same as the data parameter of cue.
+type Parameters struct {
+	Object      *unstructured.Unstructured
+	OldObject   *unstructured.Unstructured
+	ExtraParams map[string]any
+	Request     *Request
+}
+
+// Request is the metadata of admission request exposed to CEL expressions.
+type Request struct {
+	Operation   admissionv1.Operation     `json:"operation"`
+	UserInfo    authenticationv1.UserInfo `json:"userInfo"`
+	SubResource string                    `json:"subResource"`
+	DryRun      bool                      `json:"dryRun"`
+}
+
+// NewRequest returns Request of the operation, ri with value nil means the request info is unknown.
+func NewRequest(operation admissionv1.Operation, ri *utils.RequestInfo) *Request {
+	r := &Request{Operation: operation}
+	if ri != nil {
+		r.UserInfo = ri.UserInfo
+		r.SubResource = ri.SubResource
+		r.DryRun = ri.DryRun
+	}
+
+	return r
+}
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+
+	programs sync.Map // expression:cel.Program
+)
+
+func getEnv() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(
+			cel.Variable(ObjectVariableName, cel.DynType),
+			cel.Variable(OldObjectVariableName, cel.DynType),
+			cel.Variable(ExtraParamsVariableName, cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable(RequestVariableName, cel.DynType),
+			ext.Strings(),
+		)
+	})
+
+	return env, envErr
+}
+
+// Compile compiles the expression and caches the program, so an expression is compiled only once.
+func Compile(expression string) (cel.Program, error) {
+	if prg, ok := programs.Load(expression); ok {
+		return prg.(cel.Program), nil
+	}
+
+	e, err := getEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := e.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	prg, err := e.Program(ast, cel.EvalOptions(cel.OptOptimize), cel.CostLimit(costLimit),
+		cel.InterruptCheckFrequency(interruptCheckFrequency))
+	if err != nil {
+		return nil, err
+	}
+
+	programs.Store(expression, prg)
+	return prg, nil
+}
+
+// CelDoAndReturn evaluates the CEL expression and sets the result to output, the result is converted to output
+// through JSON, so output can be any type which the JSON of result can be unmarshalled to.
+// output must not be nil and must be settable, the same as cue.CueDoAndReturn.
+// The evaluation is interrupted once ctx is done.
+func CelDoAndReturn(ctx context.Context, expression string, params *Parameters, output interface{}) error {
+	if output == nil || (reflect.ValueOf(output).Kind() == reflect.Ptr && reflect.ValueOf(output).IsNil()) {
+		return cue.OutputNilErr
+	}
+
+	if reflect.ValueOf(output).Kind() != reflect.Ptr {
+		return cue.OutputNotSettableErr
+	}
+
+	prg, err := Compile(expression)
+	if err != nil {
+		return err
+	}
+
+	vars, err := activation(params)
+	if err != nil {
+		return err
+	}
+
+	val, _, err := prg.ContextEval(ctx, vars)
+	if err != nil {
+		return err
+	}
+
+	return convert(val, output)
+}
+
+func activation(params *Parameters) (map[string]interface{}, error) {
+	if params == nil {
+		params = &Parameters{}
+	}
+
+	vars := map[string]interface{}{
+		ObjectVariableName:      map[string]interface{}{},
+		OldObjectVariableName:   map[string]interface{}{},
+		ExtraParamsVariableName: map[string]interface{}{},
+	}
+	if params.Object != nil {
+		vars[ObjectVariableName] = params.Object.Object
+	}
+	if params.OldObject != nil {
+		vars[OldObjectVariableName] = params.OldObject.Object
+	}
+	if params.ExtraParams != nil {
+		extraParams, err := toJSONValue(params.ExtraParams)
+		if err != nil {
+			return nil, fmt.Errorf("convert extra params err=%w", err)
+		}
+		vars[ExtraParamsVariableName] = extraParams
+	}
+
+	request := params.Request
+	if request == nil {
+		request = &Request{}
+	}
+	r, err := toJSONValue(request)
+	if err != nil {
+		return nil, fmt.Errorf("convert request err=%w", err)
+	}
+	vars[RequestVariableName] = r
+
+	return vars, nil
+}
+
+// toJSONValue converts v to the value of its JSON, which consists of maps, slices and primitive types only.
+func toJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// convert converts the CEL value to output through JSON.
+func convert(val ref.Val, output interface{}) error {
+	native, err := val.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return fmt.Errorf("convert result of type %s err=%w", val.Type().TypeName(), err)
+	}
+
+	b, err := protojson.Marshal(native.(*structpb.Value))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, output)
+}
@@ -0,0 +1,144 @@
+package cel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cue"
+	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
+)
+
+type patch struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func TestCelDoAndReturn(t *testing.T) {
+	deploy, err := utilhelper.ToUnstructured(helper.NewDeployment(metav1.NamespaceDefault, "ut-cel"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		expression   string
+		params       *Parameters
+		output       interface{}
+		wantedOutput interface{}
+		wantErr      bool
+		wantedErr    error
+	}{
+		{
+			name:         "bool",
+			expression:   `object.metadata.name == "ut-cel"`,
+			params:       &Parameters{Object: deploy},
+			output:       new(bool),
+			wantedOutput: boolPtr(true),
+		},
+		{
+			name:         "old object is empty if not set",
+			expression:   `!has(oldObject.metadata)`,
+			params:       &Parameters{Object: deploy},
+			output:       new(bool),
+			wantedOutput: boolPtr(true),
+		},
+		{
+			name:         "patches",
+			expression:   `[{"op": "add", "path": "/metadata/labels", "value": {"app": object.metadata.name}}]`,
+			params:       &Parameters{Object: deploy},
+			output:       &[]patch{},
+			wantedOutput: &[]patch{{Op: "add", Path: "/metadata/labels", Value: map[string]interface{}{"app": "ut-cel"}}},
+		},
+		{
+			name:       "request and extra params",
+			expression: `request.operation + ":" + request.userInfo.username + ":" + extraParams.env`,
+			params: &Parameters{
+				Object:      deploy,
+				ExtraParams: map[string]any{"env": "prod"},
+				Request: NewRequest(admissionv1.Create, &utils.RequestInfo{
+					UserInfo: authenticationv1.UserInfo{Username: "alice"},
+				}),
+			},
+			output:       new(string),
+			wantedOutput: strPtr("CREATE:alice:prod"),
+		},
+		{
+			name:       "nil output",
+			expression: `true`,
+			output:     nil,
+			wantedErr:  cue.OutputNilErr,
+		},
+		{
+			name:       "output not settable",
+			expression: `true`,
+			output:     false,
+			wantedErr:  cue.OutputNotSettableErr,
+		},
+		{
+			name:       "compile error",
+			expression: `object.metadata.name ==`,
+			output:     new(bool),
+			wantErr:    true,
+		},
+		{
+			name:       "eval error",
+			expression: `object.spec.notExist == 1`,
+			params:     &Parameters{Object: deploy},
+			output:     new(bool),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CelDoAndReturn(context.Background(), tt.expression, tt.params, tt.output)
+			if tt.wantedErr != nil {
+				if !errors.Is(err, tt.wantedErr) {
+					t.Errorf("CelDoAndReturn() error = %v, wantedErr %v", err, tt.wantedErr)
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CelDoAndReturn() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.output, tt.wantedOutput) {
+				t.Errorf("CelDoAndReturn() output = %v, want %v", tt.output, tt.wantedOutput)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	expression := `object.metadata.name == "cached"`
+	p1, err := Compile(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p2, err := Compile(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p1 != p2 {
+		t.Errorf("Compile() should return the cached program")
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -9,6 +9,12 @@ const (
 	// AppliedClusterOverrides is the annotation which used to record override items an object applied.
 	// The overrides items should be sorted alphabetically in ascending order by ClusterOverridePolicy's name.
 	AppliedClusterOverrides = "policy.kcloudlabs.io/applied-cluster-overrides"
+
+	// SkipPolicies is the annotation which used to opt an object out of all override and validate policies,
+	// it only takes effect with value SkipPoliciesValue and when opt-out is enabled.
+	SkipPolicies = "policy.kcloudlabs.io/skip"
+	// SkipPoliciesValue is the value of SkipPolicies annotation to opt out.
+	SkipPoliciesValue = "true"
 )
 
 // Define resource filed
@@ -44,3 +44,24 @@ func TraceFromContext(ctx context.Context) *utiltrace.Trace {
 
 	return nil
 }
+
+type requestInfoCtxKey struct{}
+
+// ContextWithRequestInfo return a new context with the metadata of admission request as value.
+func ContextWithRequestInfo(ctx context.Context, ri *RequestInfo) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, requestInfoCtxKey{}, ri)
+}
+
+// RequestInfoFromContext returns the metadata of admission request in ctx, it returns nil if not found.
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	v, ok := ctx.Value(requestInfoCtxKey{}).(*RequestInfo)
+	if ok {
+		return v
+	}
+
+	return nil
+}
@@ -1,15 +1,13 @@
 package cue
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 
 	"cuelang.org/go/cue"
-	"cuelang.org/go/cue/build"
-	"cuelang.org/go/cue/cuecontext"
-	"cuelang.org/go/cue/parser"
 	"k8s.io/klog/v2"
 
 	_ "github.com/k-cloud-labs/pkg/builtin/http"
@@ -29,7 +27,9 @@ type Parameter struct {
 
 // CueDoAndReturn will execute cue code and set execution result to output.
 // output must not be nil and must be settable.
-func CueDoAndReturn(template string, parameters []Parameter, outputName string, output interface{}) error {
+// The template is compiled on every call, use ProgramCache to compile it only once.
+// It returns the error of ctx once ctx is done, even if the execution is still running.
+func CueDoAndReturn(ctx context.Context, template string, parameters []Parameter, outputName string, output interface{}) error {
 	// output check
 	if isNil(output) {
 		return OutputNilErr
@@ -39,71 +39,44 @@ func CueDoAndReturn(template string, parameters []Parameter, outputName string,
 		return OutputNotSettableErr
 	}
 
-	bi := build.NewContext().NewInstance("", nil)
-
-	// add template
-	fs, err := parser.ParseFile("-", template, parser.ParseComments)
+	p, err := Compile(template)
 	if err != nil {
 		return err
 	}
 
-	if err = bi.AddSyntax(fs); err != nil {
-		return err
-	}
-
-	// add parameters
-	for _, parameter := range parameters {
-		if !isNil(parameter) {
-			bt, err := json.Marshal(parameter.Object)
-			if err != nil {
-				return err
-			}
-
-			paramFile := fmt.Sprintf("%s: %s", parameter.Name, string(bt))
-			fs, err = parser.ParseFile("parameter", paramFile)
-			if err != nil {
-				return err
-			}
-
-			if err = bi.AddSyntax(fs); err != nil {
-				return err
-			}
-		}
-	}
+	return p.DoAndReturn(ctx, parameters, outputName, output)
+}
 
+// evaluate executes the cue value filled with parameters and returns the output value.
+func evaluate(ctx context.Context, value cue.Value, outputName string) (cue.Value, error) {
 	// execute cue
-	value := cuecontext.New().BuildInstance(bi)
-	if err = value.Validate(); err != nil {
-		return err
+	if err := value.Validate(); err != nil {
+		return cue.Value{}, err
 	}
 
 	// 1. execute http task
-	v, err := process(&value)
+	v, err := process(ctx, &value)
 	if err != nil {
-		return err
+		return cue.Value{}, err
 	}
 	value = *v
 
 	// 2. generate result
 	result := value.LookupPath(cue.ParsePath(outputName))
 	if !result.Exists() {
-		return OutputNotFoundErr
-	}
-
-	if err := result.Decode(output); err != nil {
-		return err
+		return cue.Value{}, OutputNotFoundErr
 	}
 
-	return nil
+	return result, nil
 }
 
-func process(v *cue.Value) (*cue.Value, error) {
+func process(ctx context.Context, v *cue.Value) (*cue.Value, error) {
 	taskVal := v.LookupPath(cue.ParsePath("processing.http"))
 	if !taskVal.Exists() {
 		klog.InfoS("there is no http in processing")
 		return v, nil
 	}
-	resp, err := exec(taskVal)
+	resp, err := exec(ctx, taskVal)
 	if err != nil {
 		return nil, fmt.Errorf("fail to exec http task, %w", err)
 	}
@@ -113,13 +86,13 @@ func process(v *cue.Value) (*cue.Value, error) {
 	return &value, nil
 }
 
-func exec(v cue.Value) (map[string]interface{}, error) {
+func exec(ctx context.Context, v cue.Value) (map[string]interface{}, error) {
 	runner, err := getRunnerByKey("http", v)
 	if err != nil {
 		return nil, err
 	}
 
-	got, err := runner.Run(&registry.Meta{Obj: v})
+	got, err := runner.Run(&registry.Meta{Context: ctx, Obj: v})
 	if err != nil {
 		return nil, err
 	}
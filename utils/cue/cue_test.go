@@ -1,6 +1,7 @@
 package cue
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -214,7 +215,7 @@ validate:{
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := CueDoAndReturn(tt.cue, tt.parameters, tt.outputName, tt.output); !reflect.DeepEqual(got, tt.wantedErr) ||
+			if got := CueDoAndReturn(context.Background(), tt.cue, tt.parameters, tt.outputName, tt.output); !reflect.DeepEqual(got, tt.wantedErr) ||
 				!reflect.DeepEqual(tt.output, tt.wantedOutput) {
 				t.Errorf("CueDoAndReturn() = %v, output = %v, want: %v, %v", got, tt.output, tt.wantedErr, tt.wantedOutput)
 			}
@@ -0,0 +1,195 @@
+package cue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/metrics"
+)
+
+const (
+	// defaultHTTPCacheSize is the max number of responses cached, the least recently used ones are evicted.
+	defaultHTTPCacheSize = 4096
+	// httpRevalidateTimeout is the timeout of refreshing a stale response in background.
+	httpRevalidateTimeout = 10 * time.Second
+)
+
+// defaultHTTPResponseCache is the cache of responses shared by all http references.
+var defaultHTTPResponseCache = newHTTPResponseCache(defaultHTTPCacheSize, clock.RealClock{})
+
+// httpCachePolicy is the cache settings of a http reference, see HttpDataRef.CacheTTL.
+type httpCachePolicy struct {
+	ttl         time.Duration
+	stale       time.Duration
+	negativeTTL time.Duration
+}
+
+// newHTTPCachePolicy returns the cache settings of ref, zero ttl means the response is not cached.
+func newHTTPCachePolicy(ref *policyv1alpha1.HttpDataRef) httpCachePolicy {
+	duration := func(d *metav1.Duration) time.Duration {
+		if d == nil || d.Duration < 0 {
+			return 0
+		}
+		return d.Duration
+	}
+
+	return httpCachePolicy{
+		ttl:         duration(ref.CacheTTL),
+		stale:       duration(ref.StaleWhileRevalidate),
+		negativeTTL: duration(ref.NegativeCacheTTL),
+	}
+}
+
+// httpCacheKey returns the key of the request of ref with method, rendered url with params, headers, body,
+// the identity of credentials and the hash of transport settings.
+func httpCacheKey(ref *policyv1alpha1.HttpDataRef, url string, transport string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.ToUpper(ref.Method)))
+	h.Write([]byte{0})
+	h.Write([]byte(url))
+	h.Write([]byte{0})
+	// keys of maps are sorted when encoded
+	header, _ := json.Marshal(ref.Header)
+	h.Write(header)
+	h.Write([]byte{0})
+	h.Write(ref.Body.Raw)
+	h.Write([]byte{0})
+	h.Write([]byte(httpAuthIdentity(ref.Auth)))
+	h.Write([]byte{0})
+	h.Write([]byte(transport))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// httpAuthIdentity identifies the credentials of a, so responses requested with different credentials are not shared.
+// Raw tokens are never part of it, inline static tokens are identified by their hash.
+func httpAuthIdentity(a *policyv1alpha1.HttpRequestAuth) string {
+	if a == nil {
+		return ""
+	}
+
+	secretKey := func(ref *policyv1alpha1.SecretKeyReference) string {
+		return fmt.Sprintf("secret/%s/%s/%s", ref.Namespace, ref.Name, ref.Key)
+	}
+	switch {
+	case a.StaticToken != "":
+		sum := sha256.Sum256([]byte(a.StaticToken))
+		return "static:" + hex.EncodeToString(sum[:])
+	case a.StaticTokenSecretRef != nil:
+		return "static:" + secretKey(a.StaticTokenSecretRef)
+	}
+
+	// tokens maintained by token manager are identified by where they are generated from
+	source := "basic"
+	switch {
+	case a.OAuth2 != nil:
+		source = "oauth2"
+	case a.ServiceAccountToken != nil:
+		source = "serviceaccount"
+	case a.JSON != nil:
+		source = "json"
+	}
+	username := a.Username
+	if a.UsernameSecretRef != nil {
+		username = secretKey(a.UsernameSecretRef)
+	}
+
+	return fmt.Sprintf("%s:%s:%s", source, a.AuthURL, username)
+}
+
+type httpCacheEntry struct {
+	response map[string]any
+	// err is the error of a failed request which is cached negatively
+	err error
+	// expireAt is the time after which the response is stale and refreshed in background
+	expireAt time.Time
+}
+
+// httpResponseCache caches responses of http references. Concurrent requests of the same key are merged,
+// and stale responses are refreshed once in background.
+type httpResponseCache struct {
+	entries *utilcache.LRUExpireCache
+	group   singleflight.Group
+	clock   clock.PassiveClock
+}
+
+func newHTTPResponseCache(size int, c clock.PassiveClock) *httpResponseCache {
+	return &httpResponseCache{
+		entries: utilcache.NewLRUExpireCacheWithClock(size, c),
+		clock:   c,
+	}
+}
+
+// get returns the cached response of key, or fetches it if it's not cached.
+// Responses are cached only if the ttl of policy is positive.
+func (c *httpResponseCache) get(ctx context.Context, key string, policy httpCachePolicy,
+	fetch func(ctx context.Context) (map[string]any, error)) (map[string]any, error) {
+	if policy.ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	if v, ok := c.entries.Get(key); ok {
+		entry := v.(*httpCacheEntry)
+		switch {
+		case entry.err != nil:
+			metrics.HTTPCacheRequest(metrics.HTTPCacheNegativeHit)
+			return nil, entry.err
+		case c.clock.Now().Before(entry.expireAt):
+			metrics.HTTPCacheRequest(metrics.HTTPCacheHit)
+			return entry.response, nil
+		default:
+			metrics.HTTPCacheRequest(metrics.HTTPCacheStale)
+			c.group.DoChan(key, func() (interface{}, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), httpRevalidateTimeout)
+				defer cancel()
+				return c.fetch(ctx, key, policy, fetch, true)
+			})
+			return entry.response, nil
+		}
+	}
+
+	metrics.HTTPCacheRequest(metrics.HTTPCacheMiss)
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		return c.fetch(ctx, key, policy, fetch, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(map[string]any), nil
+}
+
+// fetch requests the response and caches it, errors are cached for the negative ttl of policy
+// unless it's a revalidation, in which case the stale response is kept until it's expired.
+func (c *httpResponseCache) fetch(ctx context.Context, key string, policy httpCachePolicy,
+	fetch func(ctx context.Context) (map[string]any, error), revalidate bool) (map[string]any, error) {
+	response, err := fetch(ctx)
+	if err != nil {
+		if revalidate {
+			klog.ErrorS(err, "Failed to revalidate cached http response.")
+		}
+		// errors of canceled requests say nothing about the endpoint
+		if !revalidate && policy.negativeTTL > 0 && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			c.entries.Add(key, &httpCacheEntry{err: err}, policy.negativeTTL)
+		}
+		return nil, err
+	}
+
+	c.entries.Add(key, &httpCacheEntry{
+		response: response,
+		expireAt: c.clock.Now().Add(policy.ttl),
+	}, policy.ttl+policy.stale)
+	return response, nil
+}
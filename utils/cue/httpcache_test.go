@@ -0,0 +1,234 @@
+package cue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+// countingFetch returns responses with the number of times it's called, or err if it's set.
+type countingFetch struct {
+	count int32
+	err   error
+}
+
+func (f *countingFetch) fetch(_ context.Context) (map[string]any, error) {
+	n := atomic.AddInt32(&f.count, 1)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return map[string]any{"count": n}, nil
+}
+
+func (f *countingFetch) calls() int32 {
+	return atomic.LoadInt32(&f.count)
+}
+
+func TestHTTPResponseCache(t *testing.T) {
+	policy := httpCachePolicy{ttl: time.Minute, stale: time.Minute, negativeTTL: 10 * time.Second}
+
+	t.Run("no cache", func(t *testing.T) {
+		c := newHTTPResponseCache(10, clocktesting.NewFakeClock(time.Now()))
+		f := &countingFetch{}
+		for i := 0; i < 2; i++ {
+			_, _ = c.get(context.Background(), "key", httpCachePolicy{}, f.fetch)
+		}
+		if f.calls() != 2 {
+			t.Errorf("get() fetched %d times, want 2", f.calls())
+		}
+	})
+
+	t.Run("hit", func(t *testing.T) {
+		c := newHTTPResponseCache(10, clocktesting.NewFakeClock(time.Now()))
+		f := &countingFetch{}
+		for i := 0; i < 3; i++ {
+			got, err := c.get(context.Background(), "key", policy, f.fetch)
+			if err != nil || got["count"] != int32(1) {
+				t.Fatalf("get() = %v, %v, want the cached response", got, err)
+			}
+		}
+		if f.calls() != 1 {
+			t.Errorf("get() fetched %d times, want 1", f.calls())
+		}
+	})
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		clock := clocktesting.NewFakeClock(time.Now())
+		c := newHTTPResponseCache(10, clock)
+		f := &countingFetch{}
+		_, _ = c.get(context.Background(), "key", policy, f.fetch)
+
+		clock.Step(90 * time.Second)
+		got, err := c.get(context.Background(), "key", policy, f.fetch)
+		if err != nil || got["count"] != int32(1) {
+			t.Fatalf("get() = %v, %v, want the stale response", got, err)
+		}
+
+		if err := waitFor(func() bool {
+			got, _ := c.get(context.Background(), "key", policy, f.fetch)
+			return got["count"] == int32(2)
+		}); err != nil {
+			t.Errorf("stale response should be refreshed in background, fetched %d times", f.calls())
+		}
+
+		clock.Step(3 * time.Minute)
+		got, _ = c.get(context.Background(), "key", policy, f.fetch)
+		if got["count"] != int32(3) {
+			t.Errorf("get() = %v, want a new response after stale window", got)
+		}
+	})
+
+	t.Run("negative cache", func(t *testing.T) {
+		clock := clocktesting.NewFakeClock(time.Now())
+		c := newHTTPResponseCache(10, clock)
+		f := &countingFetch{err: errors.New("unavailable")}
+		for i := 0; i < 2; i++ {
+			if _, err := c.get(context.Background(), "key", policy, f.fetch); err == nil {
+				t.Fatalf("get() should return the error")
+			}
+		}
+		if f.calls() != 1 {
+			t.Errorf("get() fetched %d times, want 1", f.calls())
+		}
+
+		clock.Step(11 * time.Second)
+		f.err = nil
+		if got, err := c.get(context.Background(), "key", policy, f.fetch); err != nil || got["count"] != int32(2) {
+			t.Errorf("get() = %v, %v, want a new response after negative ttl", got, err)
+		}
+	})
+
+	t.Run("canceled requests are not cached", func(t *testing.T) {
+		c := newHTTPResponseCache(10, clocktesting.NewFakeClock(time.Now()))
+		f := &countingFetch{err: fmt.Errorf("request: %w", context.DeadlineExceeded)}
+		for i := 0; i < 2; i++ {
+			_, _ = c.get(context.Background(), "key", policy, f.fetch)
+		}
+		if f.calls() != 2 {
+			t.Errorf("get() fetched %d times, want 2", f.calls())
+		}
+	})
+}
+
+func Test_getHttpResponseCached(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprintf(w, `{"name":%q}`, r.URL.Query().Get("name"))
+	}))
+	defer s.Close()
+
+	ref := &policyv1alpha1.HttpDataRef{
+		URL:      s.URL,
+		Method:   "GET",
+		Params:   map[string]string{"name": "{{metadata.name}}"},
+		CacheTTL: &metav1.Duration{Duration: time.Minute},
+	}
+	for _, name := range []string{"a", "b", "a", "b"} {
+		got, err := getHttpResponse(context.Background(), nil, nil, newBasicObj(name, "ns"), ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := got["body"].(map[string]any); body["name"] != name {
+			t.Errorf("getHttpResponse() body = %v, want name %s", body, name)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("getHttpResponse() requested %d times, want once for each rendered url", requests)
+	}
+}
+
+func waitFor(condition func() bool) error {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return errors.New("timed out")
+}
+
+func Test_getHttpResponseCachedByCredentials(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprintf(w, `{"tenant":%q,"auth":%q}`, r.Header.Get("X-Tenant"), r.Header.Get("Authorization"))
+	}))
+	defer s.Close()
+
+	newRef := func(tenant string, auth *policyv1alpha1.HttpRequestAuth) *policyv1alpha1.HttpDataRef {
+		return &policyv1alpha1.HttpDataRef{
+			URL:      s.URL,
+			Method:   "GET",
+			Header:   map[string]string{"X-Tenant": tenant},
+			Auth:     auth,
+			CacheTTL: &metav1.Duration{Duration: time.Minute},
+		}
+	}
+	tests := []struct {
+		ref      *policyv1alpha1.HttpDataRef
+		wantBody map[string]any
+	}{
+		{
+			ref:      newRef("a", nil),
+			wantBody: map[string]any{"tenant": "a", "auth": ""},
+		},
+		{
+			ref:      newRef("b", nil),
+			wantBody: map[string]any{"tenant": "b", "auth": ""},
+		},
+		{
+			ref:      newRef("a", &policyv1alpha1.HttpRequestAuth{StaticToken: "token-a"}),
+			wantBody: map[string]any{"tenant": "a", "auth": "Bearer token-a"},
+		},
+		{
+			ref:      newRef("a", &policyv1alpha1.HttpRequestAuth{StaticToken: "token-b"}),
+			wantBody: map[string]any{"tenant": "a", "auth": "Bearer token-b"},
+		},
+		{
+			// cached
+			ref:      newRef("a", &policyv1alpha1.HttpRequestAuth{StaticToken: "token-a"}),
+			wantBody: map[string]any{"tenant": "a", "auth": "Bearer token-a"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := getHttpResponse(context.Background(), nil, nil, newBasicObj("name", "ns"), tt.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := got["body"].(map[string]any); !reflect.DeepEqual(body, tt.wantBody) {
+			t.Errorf("getHttpResponse() body = %v, want %v", body, tt.wantBody)
+		}
+	}
+
+	if requests != 4 {
+		t.Errorf("getHttpResponse() requested %d times, want once for each header and credentials", requests)
+	}
+}
+
+func Test_httpAuthIdentity(t *testing.T) {
+	ref := &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "auth"}}
+	auth := &policyv1alpha1.HttpRequestAuth{AuthURL: "http://auth", UsernameSecretRef: ref, Token: "token"}
+	if got, want := httpAuthIdentity(auth), "basic:http://auth:secret/ns/auth/"; got != want {
+		t.Errorf("httpAuthIdentity() = %v, want %v", got, want)
+	}
+
+	if got := httpAuthIdentity(&policyv1alpha1.HttpRequestAuth{StaticToken: "token"}); strings.Contains(got, "token") {
+		t.Errorf("httpAuthIdentity() = %v, should not contain the raw token", got)
+	}
+}
@@ -0,0 +1,156 @@
+package cue
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
+	"github.com/k-cloud-labs/pkg/utils/httpclient"
+)
+
+const (
+	// defaultCAKey is the key of CA bundle in Secrets if not specified.
+	defaultCAKey = "ca.crt"
+	// httpClientCacheSize is the max number of clients with custom transports cached.
+	httpClientCacheSize = 256
+	// httpClientCacheTTL is how long an unused client is kept, clients of rotated certificates expire after it.
+	httpClientCacheTTL = time.Hour
+)
+
+// httpClients caches clients by the hash of their transport settings, so connections are reused
+// across requests and a new client is created once the certificates in Secrets are rotated.
+var httpClients = utilcache.NewLRUExpireCache(httpClientCacheSize)
+
+// transportConfig is the resolved transport settings of a http reference.
+type transportConfig struct {
+	ca                 []byte
+	cert               []byte
+	key                []byte
+	serverName         string
+	insecureSkipVerify bool
+	proxyURL           string
+}
+
+// hash returns the hash of all settings, which identifies the transport.
+func (tc *transportConfig) hash() string {
+	h := sha256.New()
+	for _, b := range [][]byte{tc.ca, tc.cert, tc.key, []byte(tc.serverName), []byte(tc.proxyURL)} {
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+	if tc.insecureSkipVerify {
+		h.Write([]byte{1})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// httpClientFor returns the client to request ref with and the hash of its transport settings.
+// The default client and an empty hash are returned if ref has neither tls nor proxy settings.
+func httpClientFor(ctx context.Context, c dynamiclister.DynamicResourceLister, ref *policyv1alpha1.HttpDataRef) (httpclient.Client, string, error) {
+	if ref.TLS == nil && ref.ProxyURL == "" {
+		return defaultHTTPClient, "", nil
+	}
+
+	tc, err := resolveTransportConfig(ctx, c, ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := tc.hash()
+	if v, ok := httpClients.Get(key); ok {
+		return v.(httpclient.Client), key, nil
+	}
+
+	transport, err := newTransport(tc)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := httpclient.New(httpclient.Options{
+		Name:          "valueref",
+		Timeout:       time.Second,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	})
+	httpClients.Add(key, client, httpClientCacheTTL)
+	return client, key, nil
+}
+
+// resolveTransportConfig reads the certificates referred by ref from Secrets.
+func resolveTransportConfig(ctx context.Context, c dynamiclister.DynamicResourceLister, ref *policyv1alpha1.HttpDataRef) (*transportConfig, error) {
+	tc := &transportConfig{proxyURL: ref.ProxyURL}
+	if ref.TLS == nil {
+		return tc, nil
+	}
+
+	tc.serverName = ref.TLS.ServerName
+	tc.insecureSkipVerify = ref.TLS.InsecureSkipVerify
+	if ca := ref.TLS.CA; ca != nil {
+		var err error
+		if tc.ca, err = getSecretKeyData(ctx, c, ca, defaultCAKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if cert := ref.TLS.ClientCert; cert != nil {
+		data, err := getSecretData(ctx, c, cert)
+		if err != nil {
+			return nil, err
+		}
+
+		tc.cert, tc.key = data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey]
+		if len(tc.cert) == 0 || len(tc.key) == 0 {
+			return nil, fmt.Errorf("secret %s/%s has no %s or %s", cert.Namespace, cert.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+		}
+	}
+
+	return tc, nil
+}
+
+// newTransport returns a transport with tls and proxy settings of tc.
+func newTransport(tc *transportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tc.proxyURL != "" {
+		u, err := url.Parse(tc.proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         tc.serverName,
+		InsecureSkipVerify: tc.insecureSkipVerify,
+	}
+	if len(tc.ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(tc.ca) {
+			return nil, errors.New("no valid certificate in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(tc.cert) > 0 {
+		cert, err := tls.X509KeyPair(tc.cert, tc.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
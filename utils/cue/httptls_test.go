@@ -0,0 +1,141 @@
+package cue
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	fakedl "github.com/k-cloud-labs/pkg/utils/dynamiclister/fake"
+)
+
+func Test_getHttpResponseTLS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the server responds whether the client certificate is sent
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"clientCert":%t}`, len(r.TLS.PeerCertificates) > 0)
+	}))
+	s.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	s.StartTLS()
+	defer s.Close()
+
+	cert, key, err := certutil.GenerateSelfSignedCertKey("client", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc, err := fakedl.NewFakeDynamicResourceLister(ctx.Done(),
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "ns"},
+			Data: map[string][]byte{
+				"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}),
+			},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: "ns"},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: key,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "ca"}}
+	tests := []struct {
+		name           string
+		tls            *policyv1alpha1.HttpTLSConfig
+		wantClientCert bool
+		wantErr        bool
+	}{
+		{
+			name:    "unknown authority",
+			wantErr: true,
+		},
+		{
+			name: "ca",
+			tls:  &policyv1alpha1.HttpTLSConfig{CA: ca},
+		},
+		{
+			name: "mutual tls",
+			tls: &policyv1alpha1.HttpTLSConfig{
+				CA:         ca,
+				ClientCert: &policyv1alpha1.SecretReference{Namespace: "ns", Name: "client"},
+			},
+			wantClientCert: true,
+		},
+		{
+			name: "insecure",
+			tls:  &policyv1alpha1.HttpTLSConfig{InsecureSkipVerify: true},
+		},
+		{
+			name: "server name mismatched",
+			tls: &policyv1alpha1.HttpTLSConfig{
+				CA:         ca,
+				ServerName: "other.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "secret not found",
+			tls: &policyv1alpha1.HttpTLSConfig{
+				CA: &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "none"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "key not found",
+			tls: &policyv1alpha1.HttpTLSConfig{
+				CA: &policyv1alpha1.SecretKeyReference{SecretReference: ca.SecretReference, Key: "none"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := &policyv1alpha1.HttpDataRef{URL: s.URL, Method: "GET", TLS: tt.tls}
+			got, err := getHttpResponse(ctx, dc, nil, newBasicObj("name", "ns"), ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getHttpResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got["body"].(map[string]any)["clientCert"] != tt.wantClientCert {
+				t.Errorf("getHttpResponse() body = %v, want clientCert %t", got["body"], tt.wantClientCert)
+			}
+		})
+	}
+}
+
+func Test_getHttpResponseProxy(t *testing.T) {
+	var requests int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		// requests to proxies have absolute urls
+		_, _ = fmt.Fprintf(w, `{"host":%q}`, r.URL.Host)
+	}))
+	defer proxy.Close()
+
+	ref := &policyv1alpha1.HttpDataRef{URL: "http://example.com/api", Method: "GET", ProxyURL: proxy.URL}
+	got, err := getHttpResponse(context.Background(), nil, nil, newBasicObj("name", "ns"), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := got["body"].(map[string]any); body["host"] != "example.com" || requests != 1 {
+		t.Errorf("getHttpResponse() body = %v, requests = %d, want requested through the proxy", body, requests)
+	}
+}
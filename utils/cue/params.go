@@ -2,6 +2,7 @@ package cue
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -20,6 +22,7 @@ import (
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
+	"github.com/k-cloud-labs/pkg/utils/httpclient"
 )
 
 type CueParams struct {
@@ -29,7 +32,7 @@ type CueParams struct {
 	ExtraParams map[string]any `json:"extraParams"`
 }
 
-func BuildCueParamsViaOverridePolicy(c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, tmpl *policyv1alpha1.OverrideRuleTemplate) (*CueParams, error) {
+func BuildCueParamsViaOverridePolicy(ctx context.Context, c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, tmpl *policyv1alpha1.OverrideRuleTemplate) (*CueParams, error) {
 	var (
 		cp = &CueParams{
 			ExtraParams: make(map[string]any),
@@ -38,14 +41,14 @@ func BuildCueParamsViaOverridePolicy(c dynamiclister.DynamicResourceLister, curO
 	if tmpl.ValueRef != nil {
 		klog.V(2).InfoS("BuildCueParamsViaOverridePolicy value ref", "refFrom", tmpl.ValueRef.From)
 		if tmpl.ValueRef.From == policyv1alpha1.FromOwnerReference {
-			obj, err := getOwnerReference(c, curObject)
+			obj, err := getOwnerReference(ctx, c, curObject)
 			if err != nil {
 				return nil, fmt.Errorf("getOwnerReference got error=%w", err)
 			}
 			cp.ExtraParams["otherObject"] = obj
 		}
 		if tmpl.ValueRef.From == policyv1alpha1.FromK8s {
-			obj, err := getObject(c, curObject, tmpl.ValueRef.K8s)
+			obj, err := getObject(ctx, c, curObject, tmpl.ValueRef.K8s)
 			if err != nil {
 				return nil, fmt.Errorf("getObject got error=%w", err)
 			}
@@ -53,7 +56,7 @@ func BuildCueParamsViaOverridePolicy(c dynamiclister.DynamicResourceLister, curO
 		}
 
 		if tmpl.ValueRef.From == policyv1alpha1.FromHTTP {
-			obj, err := getHttpResponse(nil, curObject, tmpl.ValueRef.Http)
+			obj, err := getHttpResponse(ctx, c, nil, curObject, tmpl.ValueRef.Http)
 			if err != nil {
 				return nil, fmt.Errorf("getHttpResponse got error=%w", err)
 			}
@@ -64,30 +67,30 @@ func BuildCueParamsViaOverridePolicy(c dynamiclister.DynamicResourceLister, curO
 	return cp, nil
 }
 
-func BuildCueParamsViaValidatePolicy(c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, tmpl *policyv1alpha1.ValidateRuleTemplate) (*CueParams, error) {
+func BuildCueParamsViaValidatePolicy(ctx context.Context, c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, tmpl *policyv1alpha1.ValidateRuleTemplate) (*CueParams, error) {
 	switch tmpl.Type {
 	case policyv1alpha1.ValidateRuleTypeCondition:
-		return buildCueParamsForValidateCondition(c, curObject, tmpl.Condition)
+		return buildCueParamsForValidateCondition(ctx, c, curObject, tmpl.Condition)
 	default:
 		return nil, fmt.Errorf("unknown template type(%v)", tmpl.Type)
 	}
 }
 
-func buildCueParamsForValidateCondition(c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, condition *policyv1alpha1.ValidateCondition) (*CueParams, error) {
+func buildCueParamsForValidateCondition(ctx context.Context, c dynamiclister.DynamicResourceLister, curObject *unstructured.Unstructured, condition *policyv1alpha1.ValidateCondition) (*CueParams, error) {
 	var cp = &CueParams{
 		ExtraParams: make(map[string]any),
 	}
 
 	if condition.ValueRef != nil {
 		if condition.ValueRef.From == policyv1alpha1.FromOwnerReference {
-			obj, err := getOwnerReference(c, curObject)
+			obj, err := getOwnerReference(ctx, c, curObject)
 			if err != nil {
 				return nil, err
 			}
 			cp.ExtraParams["otherObject"] = obj
 		}
 		if condition.ValueRef.From == policyv1alpha1.FromK8s {
-			obj, err := getObject(c, curObject, condition.ValueRef.K8s)
+			obj, err := getObject(ctx, c, curObject, condition.ValueRef.K8s)
 			if err != nil {
 				return nil, err
 			}
@@ -95,7 +98,7 @@ func buildCueParamsForValidateCondition(c dynamiclister.DynamicResourceLister, c
 		}
 
 		if condition.ValueRef.From == policyv1alpha1.FromHTTP {
-			obj, err := getHttpResponse(nil, curObject, condition.ValueRef.Http)
+			obj, err := getHttpResponse(ctx, c, nil, curObject, condition.ValueRef.Http)
 			if err != nil {
 				return nil, err
 			}
@@ -105,14 +108,14 @@ func buildCueParamsForValidateCondition(c dynamiclister.DynamicResourceLister, c
 
 	if condition.DataRef != nil {
 		if condition.DataRef.From == policyv1alpha1.FromOwnerReference {
-			obj, err := getOwnerReference(c, curObject)
+			obj, err := getOwnerReference(ctx, c, curObject)
 			if err != nil {
 				return nil, err
 			}
 			cp.ExtraParams["otherObject_d"] = obj
 		}
 		if condition.DataRef.From == policyv1alpha1.FromK8s {
-			obj, err := getObject(c, curObject, condition.DataRef.K8s)
+			obj, err := getObject(ctx, c, curObject, condition.DataRef.K8s)
 			if err != nil {
 				return nil, err
 			}
@@ -121,7 +124,7 @@ func buildCueParamsForValidateCondition(c dynamiclister.DynamicResourceLister, c
 		}
 
 		if condition.DataRef.From == policyv1alpha1.FromHTTP {
-			obj, err := getHttpResponse(nil, curObject, condition.DataRef.Http)
+			obj, err := getHttpResponse(ctx, c, nil, curObject, condition.DataRef.Http)
 			if err != nil {
 				return nil, err
 			}
@@ -132,10 +135,13 @@ func buildCueParamsForValidateCondition(c dynamiclister.DynamicResourceLister, c
 	return cp, nil
 }
 
-func getObject(c dynamiclister.DynamicResourceLister, obj *unstructured.Unstructured, rs *policyv1alpha1.ResourceSelector) (*unstructured.Unstructured, error) {
-	gvk := schema.FromAPIVersionAndKind(rs.APIVersion, rs.Kind)
+func getObject(ctx context.Context, c dynamiclister.DynamicResourceLister, obj *unstructured.Unstructured, rs *policyv1alpha1.ResourceSelector) (*unstructured.Unstructured, error) {
+	if c == nil {
+		return nil, ErrNoLister
+	}
 
-	lister, err := c.GVKToResourceLister(gvk)
+	gvk := schema.FromAPIVersionAndKind(rs.APIVersion, rs.Kind)
+	lister, err := c.GVKToResourceLister(ctx, gvk)
 	if err != nil {
 		klog.ErrorS(err, "GetGroupVersionResource got error",
 			"apiVersion", rs.APIVersion, "kind", rs.Kind, "name", rs.Name)
@@ -251,17 +257,20 @@ func handleRefSelectLabels(ls *metav1.LabelSelector, obj *unstructured.Unstructu
 	return result, true, nil
 }
 
-func getOwnerReference(c dynamiclister.DynamicResourceLister, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+func getOwnerReference(ctx context.Context, c dynamiclister.DynamicResourceLister, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	list := obj.GetOwnerReferences()
 	if len(list) == 0 {
 		return nil, errors.New("object has no owner reference")
 	}
+	if c == nil {
+		return nil, ErrNoLister
+	}
 
 	or := list[0]
 	gvk := schema.FromAPIVersionAndKind(or.APIVersion, or.Kind)
 	klog.V(4).InfoS("get owner reference", "apiVersion", or.APIVersion, "kind", or.Kind, "name", or.Name)
 
-	lister, err := c.GVKToResourceLister(gvk)
+	lister, err := c.GVKToResourceLister(ctx, gvk)
 	if err != nil {
 		klog.ErrorS(err, "GetGroupVersionResource got error", "apiVersion", or.APIVersion, "kind", or.Kind, "name", or.Name)
 		return nil, err
@@ -293,37 +302,40 @@ var secHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie
 
 var ErrTooManyRedirects = errors.New("too many redirects")
 
+// ErrNoLister means objects referred by templates can't be resolved since there is no lister, e.g. in simulations.
+var ErrNoLister = errors.New("referred objects can't be resolved without a lister")
+
 // support direct
-var defaultHTTPClient = &http.Client{
-	Timeout: time.Second,
-	// Workaround security behavior in client where it may
-	// discard certain security-related header on redirect.
-	CheckRedirect: func(req *http.Request, via []*http.Request) error {
-		if len(via) > 10 {
-			// Emulate default redirect check.
-			return ErrTooManyRedirects
-		}
-		if len(via) > 0 {
-			for _, header := range secHeaders {
-				if req.Header.Get(header) == "" {
-					req.Header.Set(header, via[len(via)-1].Header.Get(header))
-				}
+var defaultHTTPClient = httpclient.New(httpclient.Options{
+	Name:          "valueref",
+	Timeout:       time.Second,
+	CheckRedirect: checkRedirect,
+})
+
+// checkRedirect works around security behavior in client where it may
+// discard certain security-related header on redirect.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > 10 {
+		// Emulate default redirect check.
+		return ErrTooManyRedirects
+	}
+	if len(via) > 0 {
+		for _, header := range secHeaders {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, via[len(via)-1].Header.Get(header))
 			}
 		}
+	}
 
-		return nil
-	},
+	return nil
 }
 
-func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policyv1alpha1.HttpDataRef) (map[string]any, error) {
-	if c == nil {
-		c = defaultHTTPClient
-	}
-
+// getHttpResponse requests ref with hc, or the client built with the tls and proxy settings of ref if hc is nil,
+// certificates of which are read from Secrets with c.
+func getHttpResponse(ctx context.Context, c dynamiclister.DynamicResourceLister, hc httpclient.Client, obj *unstructured.Unstructured, ref *policyv1alpha1.HttpDataRef) (map[string]any, error) {
 	var (
-		params  string
-		query   = url.Values{}
-		reqBody io.Reader
+		params string
+		query  = url.Values{}
 	)
 	for k, v := range ref.Params {
 		refVal, ok, err := parseAndGetRefValue(v, obj)
@@ -337,10 +349,6 @@ func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policy
 		query.Set(k, refVal)
 	}
 
-	if len(ref.Body.Raw) > 0 {
-		reqBody = bytes.NewBuffer(ref.Body.Raw)
-	}
-
 	if len(ref.Params) > 0 {
 		params = "?" + query.Encode()
 	}
@@ -353,7 +361,30 @@ func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policy
 		// ref not found
 		return map[string]any{}, nil
 	}
-	req, err := http.NewRequest(strings.ToUpper(ref.Method), refUrl+params, reqBody)
+
+	// responses might be different with different client certificates
+	var transport string
+	if hc == nil {
+		if hc, transport, err = httpClientFor(ctx, c, ref); err != nil {
+			klog.ErrorS(err, "build http client failed", "url", refUrl, "method", ref.Method)
+			return nil, err
+		}
+	}
+
+	key := httpCacheKey(ref, refUrl+params, transport)
+	return defaultHTTPResponseCache.get(ctx, key, newHTTPCachePolicy(ref), func(ctx context.Context) (map[string]any, error) {
+		return doHttpRequest(ctx, c, hc, refUrl+params, ref)
+	})
+}
+
+// doHttpRequest requests the rendered url of ref and returns the response.
+func doHttpRequest(ctx context.Context, c dynamiclister.DynamicResourceLister, hc httpclient.Client, refUrl string, ref *policyv1alpha1.HttpDataRef) (map[string]any, error) {
+	var reqBody io.Reader
+	if len(ref.Body.Raw) > 0 {
+		reqBody = bytes.NewBuffer(ref.Body.Raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(ref.Method), refUrl, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -363,7 +394,7 @@ func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policy
 
 	// check if request need auth
 	if ref.Auth != nil {
-		token, err := httpAuth(ref.Auth)
+		token, err := httpAuth(ctx, c, ref.Auth)
 		if err != nil {
 			klog.ErrorS(err, "auth http failed", "url", refUrl, "method", ref.Method)
 			return nil, err
@@ -373,7 +404,7 @@ func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policy
 	}
 
 	klog.V(4).InfoS("requesting http api", "url", refUrl, "method", ref.Method)
-	resp, err := c.Do(req)
+	resp, err := hc.Do(req, retryPolicy(ref.RetryPolicy))
 	if err != nil {
 		klog.ErrorS(err, "request http api failed", "url", refUrl, "method", ref.Method)
 		return nil, err
@@ -400,11 +431,30 @@ func getHttpResponse(c *http.Client, obj *unstructured.Unstructured, ref *policy
 	}, nil
 }
 
+// retryPolicy converts the retry policy of http references, nil means no retry.
+func retryPolicy(p *policyv1alpha1.HttpRetryPolicy) *httpclient.RetryPolicy {
+	if p == nil {
+		return &httpclient.RetryPolicy{}
+	}
+
+	result := &httpclient.RetryPolicy{MaxRetries: int(p.MaxRetries)}
+	if p.Backoff != nil {
+		result.Backoff = p.Backoff.Duration
+	}
+	if p.MaxBackoff != nil {
+		result.MaxBackoff = p.MaxBackoff.Duration
+	}
+
+	return result
+}
+
 type Auth struct {
 	Token string `json:"token"`
 }
 
-func httpAuth(a *policyv1alpha1.HttpRequestAuth) (token string, err error) {
+// httpAuth returns the token to request with, the static token in Secrets is read with c,
+// and the ServiceAccount token is read from the generator set by SetServiceAccountTokenGenerator.
+func httpAuth(ctx context.Context, c dynamiclister.DynamicResourceLister, a *policyv1alpha1.HttpRequestAuth) (token string, err error) {
 	if a == nil {
 		return "", errors.New("invalid auth")
 	}
@@ -413,6 +463,20 @@ func httpAuth(a *policyv1alpha1.HttpRequestAuth) (token string, err error) {
 		return a.StaticToken, nil
 	}
 
+	if a.StaticTokenSecretRef != nil {
+		b, err := getSecretKeyData(ctx, c, a.StaticTokenSecretRef, corev1.ServiceAccountTokenKey)
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	}
+
+	// never written into policies
+	if a.ServiceAccountToken != nil {
+		return serviceAccountToken(ctx)
+	}
+
 	// maintain by token manager
 	if a.Token != "" {
 		return a.Token, nil
@@ -2,17 +2,24 @@ package cue
 
 import (
 	"context"
-	"net/http"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
 	fakedl "github.com/k-cloud-labs/pkg/utils/dynamiclister/fake"
+	"github.com/k-cloud-labs/pkg/utils/httpclient"
+	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
 )
 
 func newEmptyObj() *unstructured.Unstructured {
@@ -252,7 +259,7 @@ func Test_getHttpResponse(t *testing.T) {
 	s := newMockHttpServer()
 	defer s.Close()
 	type args struct {
-		c   *http.Client
+		c   httpclient.Client
 		obj *unstructured.Unstructured
 		ref *policyv1alpha1.HttpDataRef
 	}
@@ -325,7 +332,7 @@ func Test_getHttpResponse(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getHttpResponse(tt.args.c, tt.args.obj, tt.args.ref)
+			got, err := getHttpResponse(context.Background(), nil, tt.args.c, tt.args.obj, tt.args.ref)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getHttpResponse() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -338,6 +345,100 @@ func Test_getHttpResponse(t *testing.T) {
 	}
 }
 
+func Test_httpAuth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dc, err := fakedl.NewFakeDynamicResourceLister(ctx.Done(), &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "ns"},
+		Data: map[string][]byte{
+			"token": []byte("secret-token"),
+			"other": []byte("other-token"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretRef := policyv1alpha1.SecretReference{Namespace: "ns", Name: "auth"}
+	tests := []struct {
+		name    string
+		auth    *policyv1alpha1.HttpRequestAuth
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "static token",
+			auth: &policyv1alpha1.HttpRequestAuth{StaticToken: "static", Token: "dynamic"},
+			want: "static",
+		},
+		{
+			name: "static token in secret",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				StaticTokenSecretRef: &policyv1alpha1.SecretKeyReference{SecretReference: secretRef},
+				Token:                "dynamic",
+			},
+			want: "secret-token",
+		},
+		{
+			name: "static token in secret with key",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				StaticTokenSecretRef: &policyv1alpha1.SecretKeyReference{SecretReference: secretRef, Key: "other"},
+			},
+			want: "other-token",
+		},
+		{
+			name: "key not found",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				StaticTokenSecretRef: &policyv1alpha1.SecretKeyReference{SecretReference: secretRef, Key: "none"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic token",
+			auth: &policyv1alpha1.HttpRequestAuth{Token: "dynamic"},
+			want: "dynamic",
+		},
+		{
+			name:    "no token",
+			auth:    &policyv1alpha1.HttpRequestAuth{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := httpAuth(ctx, dc, tt.auth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("httpAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("httpAuth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("service account token", func(t *testing.T) {
+		defer SetServiceAccountTokenGenerator(nil)
+		// tokens written into policies are never used
+		auth := &policyv1alpha1.HttpRequestAuth{ServiceAccountToken: &policyv1alpha1.ServiceAccountTokenSource{}, Token: "written"}
+		if _, err := httpAuth(ctx, dc, auth); err == nil {
+			t.Errorf("httpAuth() should fail if the service account token is not configured")
+		}
+
+		enc := base64.RawURLEncoding
+		token := fmt.Sprintf("%s.%s.sig", enc.EncodeToString([]byte(`{"alg":"RS256"}`)), enc.EncodeToString([]byte(`{"aud":"api"}`)))
+		path := filepath.Join(t.TempDir(), "token")
+		if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+			t.Fatal(err)
+		}
+		SetServiceAccountTokenGenerator(tokenmanager.NewServiceAccountTokenGenerator(
+			tokenmanager.ServiceAccountTokenOptions{Path: path, Audience: "api"}, time.Minute))
+		if got, err := httpAuth(ctx, dc, auth); err != nil || got != token {
+			t.Errorf("httpAuth() = %v, %v, want the token of the file", got, err)
+		}
+	})
+}
+
 func Test_getOwnerReference(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -386,14 +487,24 @@ func Test_getOwnerReference(t *testing.T) {
 			want:    deployObj,
 			wantErr: false,
 		},
+		{
+			name: "no lister",
+			args: args{
+				obj: pod,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getOwnerReference(tt.args.c, tt.args.obj)
+			got, err := getOwnerReference(context.Background(), tt.args.c, tt.args.obj)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getOwnerReference() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 
 			if !equalObj(got, tt.want) {
 				t.Errorf("getOwnerReference() got = %v, want %v", got, tt.want)
@@ -496,7 +607,7 @@ func Test_getObject(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getObject(tt.args.c, tt.args.obj, tt.args.rs)
+			got, err := getObject(context.Background(), tt.args.c, tt.args.obj, tt.args.rs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getObject() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -627,7 +738,7 @@ func TestBuildCueParamsViaOverridePolicy(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := BuildCueParamsViaOverridePolicy(tt.args.c, tt.args.curObject, tt.args.tmpl)
+			got, err := BuildCueParamsViaOverridePolicy(context.Background(), tt.args.c, tt.args.curObject, tt.args.tmpl)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BuildCueParamsViaOverridePolicy() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -867,7 +978,7 @@ func TestBuildCueParamsViaValidatePolicy(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := BuildCueParamsViaValidatePolicy(tt.args.c, tt.args.curObject, tt.args.condition)
+			got, err := BuildCueParamsViaValidatePolicy(context.Background(), tt.args.c, tt.args.curObject, tt.args.condition)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BuildCueParamsViaValidatePolicy() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,272 @@
+package cue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Program is a compiled cue template, parameters are filled into it on every execution.
+// It's safe for concurrent use.
+type Program struct {
+	// values holds compiled instances of the template, every instance is built with its own context
+	// and used by one execution at a time, since cue values are not safe for concurrent use.
+	values sync.Pool
+}
+
+// Compile compiles the template into a Program.
+func Compile(template string) (*Program, error) {
+	value, err := compile(template)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Program{}
+	p.values.New = func() any {
+		// the template has been compiled successfully once, so no error here.
+		v, _ := compile(template)
+		return &v
+	}
+	p.values.Put(&value)
+
+	return p, nil
+}
+
+func compile(template string) (cue.Value, error) {
+	value := cuecontext.New().CompileString(template)
+	return value, value.Err()
+}
+
+// DoAndReturn executes the program with parameters and sets execution result to output,
+// with the same semantics as CueDoAndReturn.
+func (p *Program) DoAndReturn(ctx context.Context, parameters []Parameter, outputName string, output interface{}) error {
+	if isNil(output) {
+		return OutputNilErr
+	}
+
+	if !isSettable(output) {
+		return OutputNotSettableErr
+	}
+
+	// fill all parameters at once, since the value is evaluated on every fill
+	fields := make(map[string]interface{}, len(parameters))
+	for _, parameter := range parameters {
+		obj, err := toValue(parameter.Object)
+		if err != nil {
+			return err
+		}
+
+		fields[parameter.Name] = obj
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	v := p.values.Get().(*cue.Value)
+	if ctx.Done() == nil {
+		// never canceled
+		defer p.values.Put(v)
+		result, err := evaluate(ctx, v.FillPath(cue.Path{}, fields), outputName)
+		if err != nil {
+			return err
+		}
+
+		return result.Decode(output)
+	}
+
+	type evaluation struct {
+		result cue.Value
+		err    error
+	}
+	done := make(chan evaluation, 1)
+	go func() {
+		result, err := evaluate(ctx, v.FillPath(cue.Path{}, fields), outputName)
+		done <- evaluation{result: result, err: err}
+	}()
+
+	select {
+	case e := <-done:
+		defer p.values.Put(v)
+		if e.err != nil {
+			return e.err
+		}
+
+		return e.result.Decode(output)
+	case <-ctx.Done():
+		// evaluation of cue can not be interrupted, so leave it running and drop the instance in use.
+		return ctx.Err()
+	}
+}
+
+// toValue converts v to the value of its JSON, which consists of maps, slices and primitive types only,
+// so it's filled into cue in the same way as it's written in cue as JSON.
+func toValue(v interface{}) (interface{}, error) {
+	switch obj := v.(type) {
+	case nil:
+		return nil, nil
+	case *unstructured.Unstructured:
+		if obj == nil {
+			return nil, nil
+		}
+		return normalize(obj.Object), nil
+	case map[string]interface{}:
+		return normalize(obj), nil
+	case *CueParams:
+		if obj == nil {
+			return nil, nil
+		}
+		object, _ := toValue(obj.Object)
+		oldObject, _ := toValue(obj.OldObject)
+		extraParams, err := toValue(obj.ExtraParams)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"object":      object,
+			"oldObject":   oldObject,
+			"extraParams": extraParams,
+		}, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return normalize(result), nil
+}
+
+// normalize copies v and converts numbers to int64 if they're integers, as JSON does not distinguish them.
+func normalize(v interface{}) interface{} {
+	switch obj := v.(type) {
+	case map[string]interface{}:
+		if obj == nil {
+			return nil
+		}
+		m := make(map[string]interface{}, len(obj))
+		for key, val := range obj {
+			m[key] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		if obj == nil {
+			return nil
+		}
+		s := make([]interface{}, len(obj))
+		for i := range obj {
+			s[i] = normalize(obj[i])
+		}
+		return s
+	case float64:
+		if obj == math.Trunc(obj) && math.Abs(obj) < 1<<63 {
+			return int64(obj)
+		}
+	case json.Number:
+		if i, err := obj.Int64(); err == nil {
+			return i
+		}
+		if f, err := obj.Float64(); err == nil {
+			return normalize(f)
+		}
+		return obj.String()
+	}
+
+	return v
+}
+
+// ProgramKey identifies the policy which the cached programs belong to.
+type ProgramKey struct {
+	UID        types.UID
+	Generation int64
+}
+
+// ProgramCache caches compiled programs of policies.
+type ProgramCache interface {
+	// Program returns the compiled program of the template of the policy identified by key,
+	// the template is compiled only once for a generation of the policy.
+	// Programs of other generations of the policy are dropped, and it's not cached if key has no UID.
+	Program(key ProgramKey, template string) (*Program, error)
+	// Invalidate drops all programs of the policy.
+	Invalidate(uid types.UID)
+}
+
+// DefaultProgramCache is the ProgramCache shared by override and validate managers,
+// policy interrupters invalidate it when policies change.
+var DefaultProgramCache = NewProgramCache()
+
+type programCacheImpl struct {
+	lock    sync.RWMutex
+	entries map[types.UID]*programEntry
+}
+
+type programEntry struct {
+	generation int64
+	programs   sync.Map // template:*Program
+}
+
+// NewProgramCache returns an empty ProgramCache.
+func NewProgramCache() ProgramCache {
+	return &programCacheImpl{
+		entries: make(map[types.UID]*programEntry),
+	}
+}
+
+func (c *programCacheImpl) Program(key ProgramKey, template string) (*Program, error) {
+	if key.UID == "" {
+		return Compile(template)
+	}
+
+	entry := c.entry(key)
+	if p, ok := entry.programs.Load(template); ok {
+		return p.(*Program), nil
+	}
+
+	p, err := Compile(template)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := entry.programs.LoadOrStore(template, p)
+	return actual.(*Program), nil
+}
+
+// entry returns the entry of the generation, the entry of an older generation is replaced.
+func (c *programCacheImpl) entry(key ProgramKey) *programEntry {
+	c.lock.RLock()
+	entry, ok := c.entries[key.UID]
+	c.lock.RUnlock()
+	if ok && entry.generation >= key.Generation {
+		return entry
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok = c.entries[key.UID]
+	if !ok || entry.generation < key.Generation {
+		entry = &programEntry{generation: key.Generation}
+		c.entries[key.UID] = entry
+	}
+
+	return entry
+}
+
+func (c *programCacheImpl) Invalidate(uid types.UID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, uid)
+}
@@ -0,0 +1,283 @@
+package cue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/utils"
+	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
+)
+
+const benchmarkTemplate = `
+data: _ @tag(data)
+object: data.object
+
+validate: {
+	if object.metadata.name == "forbidden" {
+		valid: false
+		reason: "name is forbidden"
+	}
+	if object.spec.replicas > data.extraParams.maxReplicas {
+		valid: false
+		reason: "too many replicas"
+	}
+}
+`
+
+func benchmarkParameters(b testing.TB) []Parameter {
+	deploy, err := utilhelper.ToUnstructured(helper.NewDeployment(metav1.NamespaceDefault, "bench"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return []Parameter{
+		{
+			Name: utils.DataParameterName,
+			Object: &CueParams{
+				Object:    deploy,
+				OldObject: &unstructured.Unstructured{Object: map[string]interface{}{}},
+				// numbers decoded from JSON are float64
+				ExtraParams: map[string]any{"maxReplicas": float64(10)},
+			},
+		},
+	}
+}
+
+type validateResult struct {
+	Reason string `json:"reason"`
+	Valid  bool   `json:"valid"`
+}
+
+func TestProgram_DoAndReturn(t *testing.T) {
+	p, err := Compile(benchmarkTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := benchmarkParameters(t)
+	for i := 0; i < 3; i++ {
+		result := validateResult{Valid: true}
+		if err := p.DoAndReturn(context.Background(), params, utils.ValidateOutputName, &result); err != nil {
+			t.Fatalf("DoAndReturn() err=%v", err)
+		}
+		if !result.Valid {
+			t.Errorf("DoAndReturn() result=%v, want valid", result)
+		}
+	}
+
+	deploy := params[0].Object.(*CueParams).Object
+	deploy.SetName("forbidden")
+	result := validateResult{Valid: true}
+	if err := p.DoAndReturn(context.Background(), params, utils.ValidateOutputName, &result); err != nil {
+		t.Fatalf("DoAndReturn() err=%v", err)
+	}
+	if result.Valid || result.Reason != "name is forbidden" {
+		t.Errorf("DoAndReturn() result=%v, want invalid", result)
+	}
+
+	if _, err := Compile("validate: {"); err == nil {
+		t.Errorf("Compile() should fail with invalid template")
+	}
+}
+
+func TestProgramCache(t *testing.T) {
+	c := NewProgramCache()
+	key := ProgramKey{UID: "uid", Generation: 1}
+
+	p1, err := c.Program(key, benchmarkTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, _ := c.Program(key, benchmarkTemplate)
+	if p1 != p2 {
+		t.Errorf("Program() should return the cached program of the same generation")
+	}
+
+	p3, _ := c.Program(ProgramKey{UID: "uid", Generation: 2}, benchmarkTemplate)
+	if p3 == p1 {
+		t.Errorf("Program() should compile again for a new generation")
+	}
+
+	c.Invalidate("uid")
+	p4, _ := c.Program(ProgramKey{UID: "uid", Generation: 2}, benchmarkTemplate)
+	if p4 == p3 {
+		t.Errorf("Program() should compile again after invalidated")
+	}
+
+	p5, _ := c.Program(ProgramKey{}, benchmarkTemplate)
+	p6, _ := c.Program(ProgramKey{}, benchmarkTemplate)
+	if p5 == p6 {
+		t.Errorf("Program() should not cache programs without UID")
+	}
+}
+
+func Test_toValue(t *testing.T) {
+	v, err := toValue(struct {
+		Count  float64 `json:"count"`
+		Ratio  float64 `json:"ratio"`
+		Labels map[string]string
+	}{Count: 3, Ratio: 0.5, Labels: map[string]string{"a": "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"Labels":{"a":"b"},"count":3,"ratio":0.5}`
+	got, _ := json.Marshal(v)
+	if string(got) != want {
+		t.Errorf("toValue() = %s, want %s", got, want)
+	}
+	if _, ok := v.(map[string]interface{})["count"].(int64); !ok {
+		t.Errorf("toValue() should convert integers to int64")
+	}
+}
+
+func BenchmarkCueDoAndReturn(b *testing.B) {
+	params := benchmarkParameters(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result := validateResult{Valid: true}
+		if err := CueDoAndReturn(context.Background(), benchmarkTemplate, params, utils.ValidateOutputName, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgramCache_DoAndReturn(b *testing.B) {
+	params := benchmarkParameters(b)
+	c := NewProgramCache()
+	key := ProgramKey{UID: "uid", Generation: 1}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := c.Program(key, benchmarkTemplate)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		result := validateResult{Valid: true}
+		if err := p.DoAndReturn(context.Background(), params, utils.ValidateOutputName, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgramCache_DoAndReturnParallel(b *testing.B) {
+	params := benchmarkParameters(b)
+	c := NewProgramCache()
+	key := ProgramKey{UID: "uid", Generation: 1}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p, err := c.Program(key, benchmarkTemplate)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			result := validateResult{Valid: true}
+			if err := p.DoAndReturn(context.Background(), params, utils.ValidateOutputName, &result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkCueDoAndReturnByText measures the former way of executing cue, which parses the template and
+// parameters serialized to JSON as cue source with a fresh context for every execution.
+func BenchmarkCueDoAndReturnByText(b *testing.B) {
+	params := benchmarkParameters(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result := validateResult{Valid: true}
+		if err := cueDoAndReturnByText(benchmarkTemplate, params, utils.ValidateOutputName, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func cueDoAndReturnByText(template string, parameters []Parameter, outputName string, output interface{}) error {
+	bi := build.NewContext().NewInstance("", nil)
+	fs, err := parser.ParseFile("-", template, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	if err = bi.AddSyntax(fs); err != nil {
+		return err
+	}
+
+	for _, parameter := range parameters {
+		bt, err := json.Marshal(parameter.Object)
+		if err != nil {
+			return err
+		}
+
+		fs, err = parser.ParseFile("parameter", fmt.Sprintf("%s: %s", parameter.Name, string(bt)))
+		if err != nil {
+			return err
+		}
+		if err = bi.AddSyntax(fs); err != nil {
+			return err
+		}
+	}
+
+	value := cuecontext.New().BuildInstance(bi)
+	if err = value.Validate(); err != nil {
+		return err
+	}
+
+	return value.LookupPath(cue.ParsePath(outputName)).Decode(output)
+}
+
+func TestProgram_DoAndReturnCanceled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(3 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+
+	p, err := Compile(fmt.Sprintf(`
+processing: {
+  http: {
+    method: "GET"
+    url: %q
+  }
+  output: {}
+}
+validate: valid: true
+`, s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := validateResult{}
+	err = p.DoAndReturn(ctx, nil, utils.ValidateOutputName, &result)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DoAndReturn() err=%v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DoAndReturn() should return once ctx is done, took %v", elapsed)
+	}
+}
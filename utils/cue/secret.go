@@ -0,0 +1,64 @@
+package cue
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
+)
+
+var secretGVK = corev1.SchemeGroupVersion.WithKind("Secret")
+
+// getSecretData returns the decoded data of the Secret referred by ref.
+func getSecretData(ctx context.Context, c dynamiclister.DynamicResourceLister, ref *policyv1alpha1.SecretReference) (map[string][]byte, error) {
+	if c == nil {
+		return nil, ErrNoLister
+	}
+
+	lister, err := c.GVKToResourceLister(ctx, secretGVK)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := lister.ByNamespace(ref.Namespace).Get(ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get secret %s/%s got error=%w", ref.Namespace, ref.Name, err)
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of secret %s/%s", obj, ref.Namespace, ref.Name)
+	}
+
+	secret := &corev1.Secret{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret); err != nil {
+		return nil, err
+	}
+
+	return secret.Data, nil
+}
+
+// getSecretKeyData returns the decoded value of the key referred by ref, or defaultKey if ref has no key.
+func getSecretKeyData(ctx context.Context, c dynamiclister.DynamicResourceLister, ref *policyv1alpha1.SecretKeyReference, defaultKey string) ([]byte, error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+
+	data, err := getSecretData(ctx, c, &ref.SecretReference)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("key %s not found in secret %s/%s", key, ref.Namespace, ref.Name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,38 @@
+package cue
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
+)
+
+var (
+	serviceAccountTokenLock sync.RWMutex
+	// serviceAccountTokens generates tokens of http references with serviceAccountToken auth,
+	// they're read when requesting rather than written into policies.
+	serviceAccountTokens tokenmanager.TokenGenerator
+)
+
+// SetServiceAccountTokenGenerator sets the generator of tokens of http references with serviceAccountToken auth,
+// requests of such references fail if it's nil.
+func SetServiceAccountTokenGenerator(tg tokenmanager.TokenGenerator) {
+	serviceAccountTokenLock.Lock()
+	defer serviceAccountTokenLock.Unlock()
+
+	serviceAccountTokens = tg
+}
+
+func serviceAccountToken(ctx context.Context) (string, error) {
+	serviceAccountTokenLock.RLock()
+	tg := serviceAccountTokens
+	serviceAccountTokenLock.RUnlock()
+
+	if tg == nil {
+		return "", errors.New("service account token is not configured")
+	}
+
+	token, _, err := tg.Generate(ctx)
+	return token, err
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -19,6 +21,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"github.com/k-cloud-labs/pkg/utils"
 	"github.com/k-cloud-labs/pkg/utils/informermanager"
 	"github.com/k-cloud-labs/pkg/utils/metrics"
 )
@@ -30,8 +33,10 @@ type DynamicResourceLister interface {
 	// It returns nil if gvk exist in mem cache or add success, otherwise return an error.
 	RegisterNewResource(waitForSync bool, gvkList ...schema.GroupVersionKind) error
 	// GVKToResourceLister try load resource lister from local cache, if not found in local then request
-	// k8s api to get resource.
-	GVKToResourceLister(schema.GroupVersionKind) (cache.GenericLister, error)
+	// k8s api to get resource, and the requests are canceled once ctx is done.
+	GVKToResourceLister(ctx context.Context, gvk schema.GroupVersionKind) (cache.GenericLister, error)
+	// RESTMapper returns the RESTMapper used to map gvk to gvr.
+	RESTMapper() meta.RESTMapper
 }
 
 // dynamicResourceListerImpl is implement of DynamicResourceLister
@@ -104,7 +109,7 @@ func (d *dynamicResourceListerImpl) RegisterNewResource(waitForSync bool, gvkLis
 	return nil
 }
 
-func (d *dynamicResourceListerImpl) GVKToResourceLister(gvk schema.GroupVersionKind) (cache.GenericLister, error) {
+func (d *dynamicResourceListerImpl) GVKToResourceLister(ctx context.Context, gvk schema.GroupVersionKind) (cache.GenericLister, error) {
 	v, ok := d.listerMap.Load(gvk.String())
 	if ok {
 		klog.Info("loaded exist lister")
@@ -125,11 +130,16 @@ func (d *dynamicResourceListerImpl) GVKToResourceLister(gvk schema.GroupVersionK
 	}()
 
 	return &simpleLister{
+		ctx: ctx,
 		gvr: gvr,
 		di:  d.dynamicInterface,
 	}, nil
 }
 
+func (d *dynamicResourceListerImpl) RESTMapper() meta.RESTMapper {
+	return d.mapper
+}
+
 func (d *dynamicResourceListerImpl) gvk2Gvr(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
 	v, ok := d.gvkToGvrMap.Load(gvk.String())
 	if ok {
@@ -146,13 +156,15 @@ func (d *dynamicResourceListerImpl) gvk2Gvr(gvk schema.GroupVersionKind) (schema
 }
 
 type simpleLister struct {
+	// ctx is the context of requests to k8s api
+	ctx       context.Context
 	namespace string
 	gvr       schema.GroupVersionResource
 	di        dynamic.Interface
 }
 
 func (s *simpleLister) List(selector labels.Selector) (result []runtime.Object, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second)
 	defer cancel()
 
 	var list *unstructured.UnstructuredList
@@ -184,7 +196,7 @@ func (s *simpleLister) List(selector labels.Selector) (result []runtime.Object,
 
 func (s *simpleLister) Get(name string) (runtime.Object, error) {
 	klog.Info("from simple lister")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second)
 	defer cancel()
 
 	if s.namespace != "" {
@@ -198,3 +210,33 @@ func (s *simpleLister) ByNamespace(namespace string) cache.GenericNamespaceListe
 	s.namespace = namespace
 	return s
 }
+
+// NewMatchContext returns the context to match resource selectors with namespaces and RESTMapper of the lister,
+// namespaces are got with ctx. It returns an empty context if d is nil.
+func NewMatchContext(ctx context.Context, d DynamicResourceLister) *utils.MatchContext {
+	if d == nil {
+		return &utils.MatchContext{}
+	}
+
+	return &utils.MatchContext{
+		NamespaceLabels: NamespaceLabels(ctx, d),
+		RESTMapper:      d.RESTMapper(),
+	}
+}
+
+// NamespaceLabels returns a utils.NamespaceLabelsFunc which gets namespaces via the lister with ctx,
+// so lookups are bounded by the deadline of the request. It returns nil if d is nil.
+func NamespaceLabels(ctx context.Context, d DynamicResourceLister) utils.NamespaceLabelsFunc {
+	if d == nil {
+		return nil
+	}
+
+	return func(namespace string) (map[string]string, error) {
+		lister, err := d.GVKToResourceLister(ctx, corev1.SchemeGroupVersion.WithKind("Namespace"))
+		if err != nil {
+			return nil, err
+		}
+
+		return utils.NamespaceLabelsFromLister(lister)(namespace)
+	}
+}
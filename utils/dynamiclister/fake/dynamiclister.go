@@ -55,6 +55,10 @@ func NewFakeDynamicResourceLister(done <-chan struct{}, objects ...runtime.Objec
 	return d, nil
 }
 
+func (d *FakeResourceListerImpl) RESTMapper() meta.RESTMapper {
+	return d.mapper
+}
+
 func (d *FakeResourceListerImpl) RegisterNewResource(waitForSync bool, gvkList ...schema.GroupVersionKind) error {
 	type gvk2Lister struct {
 		gvk    schema.GroupVersionKind
@@ -98,7 +102,7 @@ func (d *FakeResourceListerImpl) RegisterNewResource(waitForSync bool, gvkList .
 	return nil
 }
 
-func (d *FakeResourceListerImpl) GVKToResourceLister(gvk schema.GroupVersionKind) (cache.GenericLister, error) {
+func (d *FakeResourceListerImpl) GVKToResourceLister(ctx context.Context, gvk schema.GroupVersionKind) (cache.GenericLister, error) {
 	v, ok := d.listerMap.Load(gvk.String())
 	if ok {
 		klog.Info("loaded exist lister")
@@ -111,6 +115,7 @@ func (d *FakeResourceListerImpl) GVKToResourceLister(gvk schema.GroupVersionKind
 	}
 
 	return &simpleLister{
+		ctx: ctx,
 		gvr: gvr,
 		di:  d.dynamicInterface,
 	}, nil
@@ -120,13 +125,9 @@ func (d *FakeResourceListerImpl) gvk2Gvr(gvk schema.GroupVersionKind) (schema.Gr
 	rm, err := d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
 		klog.ErrorS(err, "RESTMapping")
-		rm = &meta.RESTMapping{
-			Resource: schema.GroupVersionResource{
-				Group:    "apps",
-				Version:  "v1",
-				Resource: "deployments", // for test
-			},
-		}
+		// for test
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		rm = &meta.RESTMapping{Resource: gvr}
 	}
 
 	klog.InfoS("RESTMapping", "gvk", rm.Resource.String())
@@ -134,13 +135,15 @@ func (d *FakeResourceListerImpl) gvk2Gvr(gvk schema.GroupVersionKind) (schema.Gr
 }
 
 type simpleLister struct {
+	// ctx is the context of requests to k8s api
+	ctx       context.Context
 	namespace string
 	gvr       schema.GroupVersionResource
 	di        dynamic.Interface
 }
 
 func (s *simpleLister) List(selector labels.Selector) (result []runtime.Object, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second)
 	defer cancel()
 
 	var list *unstructured.UnstructuredList
@@ -172,7 +175,7 @@ func (s *simpleLister) List(selector labels.Selector) (result []runtime.Object,
 
 func (s *simpleLister) Get(name string) (runtime.Object, error) {
 	klog.Info("from simple lister")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second)
 	defer cancel()
 
 	if s.namespace != "" {
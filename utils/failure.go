@@ -0,0 +1,20 @@
+package utils
+
+import (
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+// SkippedPolicy is a policy skipped since one of its rules failed with failurePolicy Ignore.
+type SkippedPolicy struct {
+	// PolicyName is the name of cluster scoped policy or namespace/name of namespaced policy.
+	PolicyName string `json:"policyName"`
+	// RuleIndex is the index of the failed rule in the policy.
+	RuleIndex int `json:"ruleIndex"`
+	// Reason is the error of the failed rule.
+	Reason string `json:"reason"`
+}
+
+// IgnoreFailure returns true if runtime errors of rules with the failure policy should be ignored.
+func IgnoreFailure(failurePolicy policyv1alpha1.FailurePolicyType) bool {
+	return failurePolicy == policyv1alpha1.FailurePolicyIgnore
+}
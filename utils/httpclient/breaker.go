@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/k-cloud-labs/pkg/utils/metrics"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed means requests are allowed.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means requests are rejected without being sent.
+	BreakerOpen
+	// BreakerHalfOpen means a trial request is allowed to check whether the host recovered.
+	BreakerHalfOpen
+)
+
+// BreakerOptions configures circuit breakers.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures after which the breaker opens.
+	// Zero or negative value disables circuit breaking.
+	FailureThreshold int
+	// OpenDuration is how long the breaker keeps open before a trial request is allowed.
+	OpenDuration time.Duration
+}
+
+// Breakers is a set of circuit breakers by host, it's safe for concurrent use.
+type Breakers struct {
+	opts     BreakerOptions
+	clock    clock.PassiveClock
+	lock     sync.Mutex
+	breakers map[string]*breaker
+}
+
+// DefaultBreakers is the circuit breakers shared by clients without their own breakers,
+// so all requests to a host agree on whether it's healthy.
+var DefaultBreakers = NewBreakers(BreakerOptions{FailureThreshold: 5, OpenDuration: 30 * time.Second}, nil)
+
+// NewBreakers returns a set of circuit breakers with opts.
+// If c is nil, the real clock is used.
+func NewBreakers(opts BreakerOptions, c clock.PassiveClock) *Breakers {
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	return &Breakers{
+		opts:     opts,
+		clock:    c,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// State returns the state of the circuit breaker of host.
+func (b *Breakers) State(host string) BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if cb, ok := b.breakers[host]; ok {
+		return cb.state
+	}
+
+	return BreakerClosed
+}
+
+// allow returns true if a request to host is allowed,
+// every allowed request must be finished with done.
+func (b *Breakers) allow(host string) bool {
+	if b.opts.FailureThreshold <= 0 {
+		return true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	cb, ok := b.breakers[host]
+	if !ok {
+		cb = &breaker{}
+		b.breakers[host] = cb
+	}
+
+	switch cb.state {
+	case BreakerOpen:
+		if b.clock.Since(cb.openedAt) < b.opts.OpenDuration {
+			return false
+		}
+		b.setState(host, cb, BreakerHalfOpen)
+		cb.probing = true
+		return true
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// done records the result of an allowed request to host. Requests failed for reasons other than
+// the host, e.g. canceled by the caller, are recorded with result resultIgnored.
+func (b *Breakers) done(host string, result requestResult) {
+	if b.opts.FailureThreshold <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	cb, ok := b.breakers[host]
+	if !ok {
+		return
+	}
+
+	if cb.state == BreakerHalfOpen {
+		cb.probing = false
+	}
+
+	switch result {
+	case resultSucceeded:
+		cb.failures = 0
+		if cb.state != BreakerClosed {
+			b.setState(host, cb, BreakerClosed)
+		}
+	case resultFailed:
+		cb.failures++
+		if cb.state == BreakerHalfOpen || (cb.state == BreakerClosed && cb.failures >= b.opts.FailureThreshold) {
+			cb.openedAt = b.clock.Now()
+			b.setState(host, cb, BreakerOpen)
+		}
+	}
+}
+
+func (b *Breakers) setState(host string, cb *breaker, state BreakerState) {
+	cb.state = state
+	metrics.SetHTTPCircuitBreakerState(host, int(state))
+}
+
+type breaker struct {
+	state BreakerState
+	// failures is the number of consecutive failures
+	failures int
+	openedAt time.Time
+	// probing is true if the trial request of half-open state is in flight
+	probing bool
+}
+
+type requestResult int
+
+const (
+	resultSucceeded requestResult = iota
+	resultFailed
+	resultIgnored
+)
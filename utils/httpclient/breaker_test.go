@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestBreakers(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	b := NewBreakers(BreakerOptions{FailureThreshold: 2, OpenDuration: time.Minute}, clock)
+	const host = "example.com"
+
+	request := func(result requestResult) bool {
+		if !b.allow(host) {
+			return false
+		}
+		b.done(host, result)
+		return true
+	}
+
+	// consecutive failures only
+	request(resultFailed)
+	request(resultSucceeded)
+	request(resultFailed)
+	request(resultIgnored)
+	if state := b.State(host); state != BreakerClosed {
+		t.Fatalf("State() = %v, want closed", state)
+	}
+
+	request(resultFailed)
+	if state := b.State(host); state != BreakerOpen {
+		t.Fatalf("State() = %v, want open", state)
+	}
+	if request(resultSucceeded) {
+		t.Errorf("allow() should reject requests while open")
+	}
+	if b.State("other.com") != BreakerClosed || !b.allow("other.com") {
+		t.Errorf("breakers of other hosts should not be affected")
+	}
+
+	// only one trial request in half-open state
+	clock.Step(time.Minute)
+	if !b.allow(host) || b.State(host) != BreakerHalfOpen {
+		t.Fatalf("allow() should allow a trial request after open duration")
+	}
+	if b.allow(host) {
+		t.Errorf("allow() should reject requests while the trial request is in flight")
+	}
+	b.done(host, resultFailed)
+	if state := b.State(host); state != BreakerOpen {
+		t.Fatalf("State() = %v, want open again after the trial failed", state)
+	}
+
+	clock.Step(time.Minute)
+	request(resultSucceeded)
+	if state := b.State(host); state != BreakerClosed {
+		t.Errorf("State() = %v, want closed after the trial succeeded", state)
+	}
+
+	disabled := NewBreakers(BreakerOptions{}, clock)
+	for i := 0; i < 10; i++ {
+		disabled.done(host, resultFailed)
+	}
+	if !disabled.allow(host) {
+		t.Errorf("allow() should always allow requests if breakers are disabled")
+	}
+}
@@ -0,0 +1,220 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/k-cloud-labs/pkg/utils/metrics"
+)
+
+const (
+	defaultBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff = time.Second
+)
+
+// ErrCircuitOpen means the request is rejected without being sent since the circuit breaker of the host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// RetryPolicy defines how failed requests are retried.
+// Requests are retried on connection errors and 5xx or 429 responses, only if their methods are idempotent.
+type RetryPolicy struct {
+	// MaxRetries is the max number of retries after the first attempt, zero means no retry.
+	MaxRetries int
+	// Backoff is the interval before the first retry, it's doubled for every later retry.
+	// Defaults to 100ms.
+	Backoff time.Duration
+	// MaxBackoff caps the interval between retries.
+	// Defaults to 1s.
+	MaxBackoff time.Duration
+}
+
+// Options configures a Client.
+type Options struct {
+	// Name identifies the client in metrics.
+	Name string
+	// Timeout is the timeout of each attempt, zero means no timeout.
+	Timeout time.Duration
+	// Transport is the transport of requests, nil means http.DefaultTransport.
+	Transport http.RoundTripper
+	// CheckRedirect is the redirect policy, see http.Client.
+	CheckRedirect func(req *http.Request, via []*http.Request) error
+	// Retry is the retry policy of requests sent without their own retry policy.
+	Retry RetryPolicy
+	// Breakers is the circuit breakers of hosts, nil means DefaultBreakers.
+	Breakers *Breakers
+}
+
+// Client sends http requests with retries and circuit breakers by host.
+type Client interface {
+	// Do sends the request and returns the response, like http.Client.Do.
+	// The request is retried with retry, or the retry policy of the client if it's nil.
+	// ErrCircuitOpen is returned if the circuit breaker of the host is open.
+	Do(req *http.Request, retry *RetryPolicy) (*http.Response, error)
+}
+
+type clientImpl struct {
+	name     string
+	client   *http.Client
+	retry    RetryPolicy
+	breakers *Breakers
+}
+
+// New returns a Client with opts.
+func New(opts Options) Client {
+	if opts.Breakers == nil {
+		opts.Breakers = DefaultBreakers
+	}
+
+	return &clientImpl{
+		name: opts.Name,
+		client: &http.Client{
+			Transport:     opts.Transport,
+			Timeout:       opts.Timeout,
+			CheckRedirect: opts.CheckRedirect,
+		},
+		retry:    opts.Retry,
+		breakers: opts.Breakers,
+	}
+}
+
+func (c *clientImpl) Do(req *http.Request, retry *RetryPolicy) (*http.Response, error) {
+	policy := c.retry
+	if retry != nil {
+		policy = *retry
+	}
+	// the body can not be sent again without GetBody
+	if !idempotent(req.Method) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		policy.MaxRetries = 0
+	}
+
+	backoff := policy.backoff()
+	host := req.URL.Host
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := sleep(req.Context(), backoff.Step()); err != nil {
+				return nil, err
+			}
+
+			var err error
+			if req, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.do(host, req)
+		if attempt >= policy.MaxRetries || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		klog.V(4).InfoS("Retry http request.", "client", c.name, "url", req.URL.Redacted(), "attempt", attempt+1, "err", err)
+		if resp != nil {
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+// do sends the request once if the circuit breaker of host allows.
+func (c *clientImpl) do(host string, req *http.Request) (*http.Response, error) {
+	if !c.breakers.allow(host) {
+		metrics.HTTPRequestDone(c.name, host, req.Method, "circuit_open", 0)
+		return nil, fmt.Errorf("request %s: %w", host, ErrCircuitOpen)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.HTTPRequestDone(c.name, host, req.Method, code, time.Since(start))
+
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// canceled by the caller
+		c.breakers.done(host, resultIgnored)
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		c.breakers.done(host, resultFailed)
+	default:
+		c.breakers.done(host, resultSucceeded)
+	}
+
+	return resp, err
+}
+
+func (p RetryPolicy) backoff() wait.Backoff {
+	b := wait.Backoff{
+		Duration: p.Backoff,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    math.MaxInt32,
+		Cap:      p.MaxBackoff,
+	}
+	if b.Duration <= 0 {
+		b.Duration = defaultBackoff
+	}
+	if b.Cap <= 0 {
+		b.Cap = defaultMaxBackoff
+	}
+
+	return b
+}
+
+// idempotent returns true if requests of method can be sent more than once, see RFC 7231 4.2.2.
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable returns true if the request failed for reasons which may be resolved by retrying.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// rewind returns a copy of req with a new body to be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
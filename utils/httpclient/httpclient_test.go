@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer returns a server which responds code for the first failures requests and 200 with the request body later.
+func newFlakyServer(failures int32, code int) (*httptest.Server, *int32) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(code)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(b)
+	}))
+
+	return s, &requests
+}
+
+func TestClient_Do(t *testing.T) {
+	retry := &RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		failures     int32
+		code         int
+		retry        *RetryPolicy
+		wantCode     int
+		wantRequests int32
+	}{
+		{
+			name:         "no retry by default",
+			method:       http.MethodGet,
+			failures:     1,
+			code:         http.StatusServiceUnavailable,
+			wantCode:     http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "retry until succeeded",
+			method:       http.MethodGet,
+			failures:     2,
+			code:         http.StatusServiceUnavailable,
+			retry:        retry,
+			wantCode:     http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "retry too many requests",
+			method:       http.MethodGet,
+			failures:     1,
+			code:         http.StatusTooManyRequests,
+			retry:        retry,
+			wantCode:     http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "retries exhausted",
+			method:       http.MethodGet,
+			failures:     5,
+			code:         http.StatusBadGateway,
+			retry:        retry,
+			wantCode:     http.StatusBadGateway,
+			wantRequests: 3,
+		},
+		{
+			name:         "no retry on client errors",
+			method:       http.MethodGet,
+			failures:     1,
+			code:         http.StatusNotFound,
+			retry:        retry,
+			wantCode:     http.StatusNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:         "no retry on non-idempotent methods",
+			method:       http.MethodPost,
+			failures:     1,
+			code:         http.StatusServiceUnavailable,
+			retry:        retry,
+			wantCode:     http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "body is sent again",
+			method:       http.MethodPut,
+			body:         "payload",
+			failures:     1,
+			code:         http.StatusServiceUnavailable,
+			retry:        retry,
+			wantCode:     http.StatusOK,
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, requests := newFlakyServer(tt.failures, tt.code)
+			defer s.Close()
+
+			c := New(Options{Name: "test", Breakers: NewBreakers(BreakerOptions{}, nil)})
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, _ := http.NewRequest(tt.method, s.URL, body)
+			resp, err := c.Do(req, tt.retry)
+			if err != nil {
+				t.Fatalf("Do() err=%v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode || *requests != tt.wantRequests {
+				t.Errorf("Do() code=%d, requests=%d, want code=%d, requests=%d", resp.StatusCode, *requests, tt.wantCode, tt.wantRequests)
+			}
+			if b, _ := io.ReadAll(resp.Body); resp.StatusCode == http.StatusOK && string(b) != tt.body {
+				t.Errorf("Do() body=%s, want %s", b, tt.body)
+			}
+		})
+	}
+}
+
+func TestClient_DoCanceled(t *testing.T) {
+	s, requests := newFlakyServer(10, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := New(Options{Name: "test", Breakers: NewBreakers(BreakerOptions{}, nil)})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	_, err := c.Do(req, &RetryPolicy{MaxRetries: 5, Backoff: time.Second})
+	if !errors.Is(err, context.DeadlineExceeded) || *requests != 1 {
+		t.Errorf("Do() err=%v, requests=%d, want canceled during backoff", err, *requests)
+	}
+}
+
+func TestClient_DoCircuitOpen(t *testing.T) {
+	s, requests := newFlakyServer(10, http.StatusInternalServerError)
+	defer s.Close()
+
+	c := New(Options{Name: "test", Breakers: NewBreakers(BreakerOptions{FailureThreshold: 2, OpenDuration: time.Minute}, nil)})
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, s.URL, nil)
+		resp, err := c.Do(req, nil)
+		if err != nil {
+			t.Fatalf("Do() err=%v", err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL, nil)
+	if _, err := c.Do(req, &RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Do() err=%v, want circuit open", err)
+	}
+	if *requests != 2 {
+		t.Errorf("requests=%d, want 2 since the breaker is open", *requests)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -13,14 +14,32 @@ import (
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/cue"
 	"github.com/k-cloud-labs/pkg/utils/interrupter/model"
 	"github.com/k-cloud-labs/pkg/utils/templatemanager"
+	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
 )
 
 type baseInterrupter struct {
 	overrideTemplateManager templatemanager.TemplateManager
 	validateTemplateManager templatemanager.TemplateManager
 	cueManager              templatemanager.CueManager
+	// disallowInlineSecrets rejects policies with credentials written inline in http references
+	disallowInlineSecrets bool
+	// serviceAccountTokenEnabled is false to reject policies with serviceAccountToken auth
+	serviceAccountTokenEnabled bool
+}
+
+// BaseInterrupterOptions is the operator configuration of interrupters.
+type BaseInterrupterOptions struct {
+	// DisallowInlineSecrets rejects policies with credentials written inline in http references,
+	// and credentials must be read from Secrets.
+	DisallowInlineSecrets bool
+	// ServiceAccountToken is the token sent to http references with serviceAccountToken auth,
+	// policies with such references are rejected if it's not enabled.
+	ServiceAccountToken tokenmanager.ServiceAccountTokenOptions
+	// ServiceAccountTokenExpire is the expire duration of the token if it has no exp claim.
+	ServiceAccountTokenExpire time.Duration
 }
 
 func (i *baseInterrupter) OnMutating(obj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
@@ -38,12 +57,40 @@ func (i *baseInterrupter) OnStartUp() error {
 	return nil
 }
 
-func NewBaseInterrupter(otm, vtm templatemanager.TemplateManager, cm templatemanager.CueManager) PolicyInterrupter {
+// NewBaseInterrupter returns the PolicyInterrupter shared by interrupters of all kinds of policies.
+// The ServiceAccount token of opts is also set to cue to request http references with.
+func NewBaseInterrupter(otm, vtm templatemanager.TemplateManager, cm templatemanager.CueManager, opts BaseInterrupterOptions) PolicyInterrupter {
+	var tg tokenmanager.TokenGenerator
+	if opts.ServiceAccountToken.Enabled() {
+		tg = tokenmanager.NewServiceAccountTokenGenerator(opts.ServiceAccountToken, opts.ServiceAccountTokenExpire)
+	}
+	cue.SetServiceAccountTokenGenerator(tg)
+
 	return &baseInterrupter{
-		overrideTemplateManager: otm,
-		validateTemplateManager: vtm,
-		cueManager:              cm,
+		overrideTemplateManager:    otm,
+		validateTemplateManager:    vtm,
+		cueManager:                 cm,
+		disallowInlineSecrets:      opts.DisallowInlineSecrets,
+		serviceAccountTokenEnabled: opts.ServiceAccountToken.Enabled(),
+	}
+}
+
+// validateHttpRefs validates credentials of http references of a policy in namespace,
+// namespace is empty for cluster scoped policies.
+func (i *baseInterrupter) validateHttpRefs(refs []*policyv1alpha1.HttpDataRef, namespace string) error {
+	for _, ref := range refs {
+		if err := validateHttpAuth(ref.Auth, i.disallowInlineSecrets); err != nil {
+			return err
+		}
+		if ref.Auth != nil && ref.Auth.ServiceAccountToken != nil && !i.serviceAccountTokenEnabled {
+			return errors.New("serviceAccountToken is not enabled by the operator")
+		}
+		if err := validateSecretNamespaces(ref, namespace); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func (i *baseInterrupter) renderAndFormat(data any) (b []byte, err error) {
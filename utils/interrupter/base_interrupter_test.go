@@ -29,7 +29,7 @@ func test_baseInterrupter() (*baseInterrupter, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewBaseInterrupter(mtm, vtm, templatemanager.NewCueManager()).(*baseInterrupter), nil
+	return NewBaseInterrupter(mtm, vtm, templatemanager.NewCueManager(), BaseInterrupterOptions{}).(*baseInterrupter), nil
 }
 
 func Test_baseInterrupter_renderAndFormat(t *testing.T) {
@@ -167,3 +167,20 @@ abc`),
 		})
 	}
 }
+
+func Test_baseInterrupter_validateHttpRefs(t *testing.T) {
+	refs := []*policyv1alpha1.HttpDataRef{{
+		URL:  "http://api",
+		Auth: &policyv1alpha1.HttpRequestAuth{ServiceAccountToken: &policyv1alpha1.ServiceAccountTokenSource{}},
+	}}
+
+	disabled := &baseInterrupter{}
+	if err := disabled.validateHttpRefs(refs, ""); err == nil {
+		t.Errorf("validateHttpRefs() should reject serviceAccountToken if it's not enabled")
+	}
+
+	enabled := &baseInterrupter{serviceAccountTokenEnabled: true}
+	if err := enabled.validateHttpRefs(refs, ""); err != nil {
+		t.Errorf("validateHttpRefs() error = %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
 )
 
 type clusterOverridePolicyInterrupter struct {
@@ -75,7 +76,12 @@ func (c *clusterOverridePolicyInterrupter) OnValidating(obj, oldObj *unstructure
 		}
 	}
 
-	return c.validateOverridePolicy(&cop.Spec)
+	if err := c.validateOverridePolicy(&cop.Spec, ""); err != nil {
+		return err
+	}
+
+	c.recordCueCompiled(c.statusKey(cop.Name), nil)
+	return nil
 }
 
 func (c *clusterOverridePolicyInterrupter) OnStartUp() error {
@@ -86,6 +92,7 @@ func (c *clusterOverridePolicyInterrupter) OnStartUp() error {
 
 	for _, policy := range list {
 		c.handleValueRef(policy, nil, admissionv1.Create)
+		c.recordCueCompiled(c.statusKey(policy.Name), c.validateOverrideRulesCue(policy.Spec.OverrideRules))
 	}
 
 	return nil
@@ -98,6 +105,10 @@ func NewClusterOverridePolicyInterrupter(opInterrupter PolicyInterrupter, lister
 	}
 }
 
+func (c *clusterOverridePolicyInterrupter) statusKey(name string) statusmanager.PolicyKey {
+	return statusmanager.PolicyKey{Kind: statusmanager.KindClusterOverridePolicy, Name: name}
+}
+
 func (c *clusterOverridePolicyInterrupter) patchOverridePolicy(policy *policyv1alpha1.ClusterOverridePolicy, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
 	if operation == admissionv1.Delete {
 		return nil, nil
@@ -172,7 +183,7 @@ func (c *clusterOverridePolicyInterrupter) getTokenCallbackMap(policy *policyv1a
 			continue
 		}
 
-		tg := getTokenGeneratorFromRef(tmpl.ValueRef.Http)
+		tg := getTokenGeneratorFromRef(c.client, tmpl.ValueRef.Http)
 		if tg == nil {
 			continue
 		}
@@ -192,7 +203,9 @@ func (c *clusterOverridePolicyInterrupter) getTokenCallbackMap(policy *policyv1a
 
 	for _, impl := range callbackMap {
 		impl.id = fmt.Sprintf("%s/%s/%s", policy.GroupVersionKind(), policy.Namespace, policy.Name)
+		impl.statusKey = c.statusKey(policy.Name)
 		impl.callback = c.genCallback(impl, policy.Namespace, policy.Name)
+		impl.failure = c.genFailureCallback(impl)
 	}
 
 	return callbackMap
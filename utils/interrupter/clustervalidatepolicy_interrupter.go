@@ -18,14 +18,16 @@ import (
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
 	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
 )
 
 type clusterValidatePolicyInterrupter struct {
 	*baseInterrupter
-	tokenManager tokenmanager.TokenManager
-	client       client.Client
-	lister       v1alpha1.ClusterValidatePolicyLister
+	tokenManager  tokenmanager.TokenManager
+	statusManager statusmanager.StatusManager
+	client        client.Client
+	lister        v1alpha1.ClusterValidatePolicyLister
 }
 
 func (v *clusterValidatePolicyInterrupter) OnMutating(obj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
@@ -50,7 +52,7 @@ func (v *clusterValidatePolicyInterrupter) OnMutating(obj, oldObj *unstructured.
 		}
 	}
 
-	patches, err := v.patchClusterValidatePolicy(cvp, operation)
+	patches, err := v.patchValidateRules(cvp.Spec.ValidateRules, operation)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +87,23 @@ func (v *clusterValidatePolicyInterrupter) OnValidating(obj, oldObj *unstructure
 		}
 	}
 
-	return v.validateClusterValidatePolicy(cvp)
+	if err := v.validateValidateRules(cvp.Spec.ValidateRules); err != nil {
+		return err
+	}
+	if err := validateResourceSelectors(cvp.Spec.ResourceSelectors); err != nil {
+		return err
+	}
+
+	refs := validateRulesRefs(cvp.Spec.ValidateRules)
+	if err := validateK8sRefs(refs); err != nil {
+		return err
+	}
+	if err := v.validateHttpRefs(httpRefs(refs), ""); err != nil {
+		return err
+	}
+
+	v.recordCueCompiled(v.statusKey(cvp.Name), nil)
+	return nil
 }
 
 func (v *clusterValidatePolicyInterrupter) OnStartUp() error {
@@ -96,23 +114,40 @@ func (v *clusterValidatePolicyInterrupter) OnStartUp() error {
 
 	for _, policy := range list {
 		v.handleValueRef(policy, nil, admissionv1.Create)
+		v.recordCueCompiled(v.statusKey(policy.Name), v.validateValidateRules(policy.Spec.ValidateRules))
 	}
 
 	return nil
 }
 
+// NewClusterValidatePolicyInterrupter returns a PolicyInterrupter for ClusterValidatePolicy.
+// If sm is nil, status of policies will not be recorded.
 func NewClusterValidatePolicyInterrupter(interrupter PolicyInterrupter, tm tokenmanager.TokenManager,
-	client client.Client, lister v1alpha1.ClusterValidatePolicyLister) PolicyInterrupter {
+	client client.Client, lister v1alpha1.ClusterValidatePolicyLister, sm statusmanager.StatusManager) PolicyInterrupter {
+	if sm == nil {
+		sm = statusmanager.NewNopStatusManager()
+	}
+
 	return &clusterValidatePolicyInterrupter{
 		baseInterrupter: interrupter.(*baseInterrupter),
 		tokenManager:    tm,
+		statusManager:   sm,
 		client:          client,
 		lister:          lister,
 	}
 }
 
-func (v *clusterValidatePolicyInterrupter) validateClusterValidatePolicy(obj *policyv1alpha1.ClusterValidatePolicy) error {
-	for _, validateRule := range obj.Spec.ValidateRules {
+func (v *clusterValidatePolicyInterrupter) statusKey(name string) statusmanager.PolicyKey {
+	return statusmanager.PolicyKey{Kind: statusmanager.KindClusterValidatePolicy, Name: name}
+}
+
+func (v *clusterValidatePolicyInterrupter) recordCueCompiled(key statusmanager.PolicyKey, err error) {
+	v.statusManager.SetCondition(key, statusmanager.NewCondition(policyv1alpha1.PolicyConditionCueCompiled,
+		policyv1alpha1.PolicyReasonCompiled, policyv1alpha1.PolicyReasonCompileFailed, err))
+}
+
+func (v *clusterValidatePolicyInterrupter) validateValidateRules(rules []policyv1alpha1.ValidateRuleWithOperation) error {
+	for _, validateRule := range rules {
 		if len(validateRule.RenderedCue) != 0 {
 			if err := v.cueManager.Validate([]byte(validateRule.RenderedCue)); err != nil {
 				return err
@@ -128,13 +163,13 @@ func (v *clusterValidatePolicyInterrupter) validateClusterValidatePolicy(obj *po
 	return nil
 }
 
-func (v *clusterValidatePolicyInterrupter) patchClusterValidatePolicy(policy *policyv1alpha1.ClusterValidatePolicy, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
+func (v *clusterValidatePolicyInterrupter) patchValidateRules(rules []policyv1alpha1.ValidateRuleWithOperation, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
 	if operation == admissionv1.Delete {
 		return nil, nil
 	}
 
 	patches := make([]jsonpatchv2.JsonPatchOperation, 0)
-	for i, validateRule := range policy.Spec.ValidateRules {
+	for i, validateRule := range rules {
 		if validateRule.Template == nil {
 			continue
 		}
@@ -209,9 +244,24 @@ const (
 )
 
 func (v *clusterValidatePolicyInterrupter) getTokenCallbackMap(policy *policyv1alpha1.ClusterValidatePolicy) map[string]*tokenCallbackImpl {
+	callbackMap := getValidateRulesCallbackMap(policy.Spec.ValidateRules, v.client, v.getPolicy)
+	for _, impl := range callbackMap {
+		impl.id = fmt.Sprintf("%s/%s", policy.GroupVersionKind(), policy.Name)
+		impl.statusKey = v.statusKey(policy.Name)
+		impl.callback = v.genCallback(impl, policy.Namespace, policy.Name)
+		impl.failure = v.genFailureCallback(impl)
+	}
+
+	return callbackMap
+}
+
+// getValidateRulesCallbackMap returns token callbacks of http references in validate rules,
+// id and callbacks of returned items should be filled by caller.
+func getValidateRulesCallbackMap(rules []policyv1alpha1.ValidateRuleWithOperation, reader client.Reader,
+	getPolicy func(namespace, name string) (client.Object, error)) map[string]*tokenCallbackImpl {
 	callbackMap := make(map[string]*tokenCallbackImpl)
 	checkAndAppend := func(ref *policyv1alpha1.HttpDataRef, tokenPath, expirePath string) {
-		tg := getTokenGeneratorFromRef(ref)
+		tg := getTokenGeneratorFromRef(reader, ref)
 		if tg == nil {
 			return
 		}
@@ -220,7 +270,7 @@ func (v *clusterValidatePolicyInterrupter) getTokenCallbackMap(policy *policyv1a
 		if !ok {
 			cb = &tokenCallbackImpl{
 				generator: tg,
-				getPolicy: v.getPolicy,
+				getPolicy: getPolicy,
 			}
 		}
 
@@ -229,7 +279,7 @@ func (v *clusterValidatePolicyInterrupter) getTokenCallbackMap(policy *policyv1a
 		callbackMap[tg.ID()] = cb
 	}
 
-	for i, rule := range policy.Spec.ValidateRules {
+	for i, rule := range rules {
 		if rule.Template == nil {
 			continue
 		}
@@ -253,11 +303,6 @@ func (v *clusterValidatePolicyInterrupter) getTokenCallbackMap(policy *policyv1a
 		}
 	}
 
-	for _, impl := range callbackMap {
-		impl.id = fmt.Sprintf("%s/%s", policy.GroupVersionKind(), policy.Name)
-		impl.callback = v.genCallback(impl, policy.Namespace, policy.Name)
-	}
-
 	return callbackMap
 }
 
@@ -295,11 +340,21 @@ func (v *clusterValidatePolicyInterrupter) genCallback(impl *tokenCallbackImpl,
 
 		obj, err := impl.getPolicy(namespace, name)
 		if err != nil {
-			klog.ErrorS(err, "load cluster validate policy error", "namespace", namespace, "name", name)
+			klog.ErrorS(err, "load validate policy error", "namespace", namespace, "name", name)
 			return err
 		}
 
-		klog.V(4).InfoS("before patch cvp", "cvp", obj.GetName(), "patchBytes", string(patchBytes))
-		return v.client.Patch(context.Background(), obj, client.RawPatch(types.JSONPatchType, patchBytes))
+		klog.V(4).InfoS("before patch validate policy", "policy", klog.KObj(obj), "patchBytes", string(patchBytes))
+		err = v.client.Patch(context.Background(), obj, client.RawPatch(types.JSONPatchType, patchBytes))
+		v.statusManager.SetCondition(impl.statusKey, statusmanager.NewCondition(policyv1alpha1.PolicyConditionTokenReady,
+			policyv1alpha1.PolicyReasonTokenFetched, policyv1alpha1.PolicyReasonTokenFetchFailed, err))
+		return err
+	}
+}
+
+func (v *clusterValidatePolicyInterrupter) genFailureCallback(impl *tokenCallbackImpl) func(err error) {
+	return func(err error) {
+		v.statusManager.SetCondition(impl.statusKey, statusmanager.NewCondition(policyv1alpha1.PolicyConditionTokenReady,
+			policyv1alpha1.PolicyReasonTokenFetched, policyv1alpha1.PolicyReasonTokenFetchFailed, err))
 	}
 }
@@ -23,14 +23,16 @@ import (
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
 	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
 )
 
 type overridePolicyInterrupter struct {
 	*baseInterrupter
-	tokenManager tokenmanager.TokenManager
-	client       client.Client
-	lister       v1alpha1.OverridePolicyLister
+	tokenManager  tokenmanager.TokenManager
+	statusManager statusmanager.StatusManager
+	client        client.Client
+	lister        v1alpha1.OverridePolicyLister
 }
 
 func (o *overridePolicyInterrupter) OnMutating(obj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
@@ -90,7 +92,12 @@ func (o *overridePolicyInterrupter) OnValidating(obj, oldObj *unstructured.Unstr
 		}
 	}
 
-	return o.validateOverridePolicy(&op.Spec)
+	if err := o.validateOverridePolicy(&op.Spec, op.Namespace); err != nil {
+		return err
+	}
+
+	o.recordCueCompiled(o.statusKey(op.Namespace, op.Name), nil)
+	return nil
 }
 
 func (o *overridePolicyInterrupter) OnStartUp() error {
@@ -101,21 +108,70 @@ func (o *overridePolicyInterrupter) OnStartUp() error {
 
 	for _, policy := range list {
 		o.handleValueRef(policy, nil, admissionv1.Create)
+		o.recordCueCompiled(o.statusKey(policy.Namespace, policy.Name), o.validateOverrideRulesCue(policy.Spec.OverrideRules))
 	}
 
 	return nil
 }
 
-func NewOverridePolicyInterrupter(interrupter PolicyInterrupter, tm tokenmanager.TokenManager, client client.Client, lister v1alpha1.OverridePolicyLister) PolicyInterrupter {
+// NewOverridePolicyInterrupter returns a PolicyInterrupter for OverridePolicy.
+// If sm is nil, status of policies will not be recorded.
+func NewOverridePolicyInterrupter(interrupter PolicyInterrupter, tm tokenmanager.TokenManager, client client.Client, lister v1alpha1.OverridePolicyLister,
+	sm statusmanager.StatusManager) PolicyInterrupter {
+	if sm == nil {
+		sm = statusmanager.NewNopStatusManager()
+	}
+
 	return &overridePolicyInterrupter{
 		baseInterrupter: interrupter.(*baseInterrupter),
 		tokenManager:    tm,
+		statusManager:   sm,
 		client:          client,
 		lister:          lister,
 	}
 }
 
-func (o *overridePolicyInterrupter) validateOverridePolicy(objSpec *policyv1alpha1.OverridePolicySpec) error {
+func (o *overridePolicyInterrupter) statusKey(namespace, name string) statusmanager.PolicyKey {
+	return statusmanager.PolicyKey{Kind: statusmanager.KindOverridePolicy, Namespace: namespace, Name: name}
+}
+
+func (o *overridePolicyInterrupter) recordCueCompiled(key statusmanager.PolicyKey, err error) {
+	o.statusManager.SetCondition(key, statusmanager.NewCondition(policyv1alpha1.PolicyConditionCueCompiled,
+		policyv1alpha1.PolicyReasonCompiled, policyv1alpha1.PolicyReasonCompileFailed, err))
+}
+
+// validateOverrideRulesCue validates cue and rendered cue of override rules.
+func (o *overridePolicyInterrupter) validateOverrideRulesCue(rules []policyv1alpha1.RuleWithOperation) error {
+	for _, overrideRule := range rules {
+		if len(overrideRule.Overriders.RenderedCue) != 0 {
+			if err := o.cueManager.Validate([]byte(overrideRule.Overriders.RenderedCue)); err != nil {
+				return err
+			}
+		}
+		if len(overrideRule.Overriders.Cue) != 0 {
+			if err := o.cueManager.Validate([]byte(overrideRule.Overriders.Cue)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOverridePolicy validates spec of a policy in namespace, namespace is empty for cluster scoped policies.
+func (o *overridePolicyInterrupter) validateOverridePolicy(objSpec *policyv1alpha1.OverridePolicySpec, namespace string) error {
+	if err := validateResourceSelectors(objSpec.ResourceSelectors); err != nil {
+		return err
+	}
+
+	refs := overrideRulesRefs(objSpec.OverrideRules)
+	if err := validateK8sRefs(refs); err != nil {
+		return err
+	}
+	if err := o.validateHttpRefs(httpRefs(refs), namespace); err != nil {
+		return err
+	}
+
 	for _, overrideRule := range objSpec.OverrideRules {
 		if validateOverrideRuleOrigin(overrideRule.Overriders.Origin) {
 			return fmt.Errorf("cop is invalid: in the same cop, there cannot be a unified containerCount in OverrideRuleOriginResourceRequirements and OverrideRuleOriginResourceOversell")
@@ -236,7 +292,7 @@ func (o *overridePolicyInterrupter) getTokenCallbackMap(policy *policyv1alpha1.O
 			continue
 		}
 
-		tg := getTokenGeneratorFromRef(tmpl.ValueRef.Http)
+		tg := getTokenGeneratorFromRef(o.client, tmpl.ValueRef.Http)
 		if tg == nil {
 			continue
 		}
@@ -256,7 +312,9 @@ func (o *overridePolicyInterrupter) getTokenCallbackMap(policy *policyv1alpha1.O
 
 	for _, impl := range callbackMap {
 		impl.id = fmt.Sprintf("%s/%s/%s", policy.GroupVersionKind(), policy.Namespace, policy.Name)
+		impl.statusKey = o.statusKey(policy.Namespace, policy.Name)
 		impl.callback = o.genCallback(impl, policy.Namespace, policy.Name)
+		impl.failure = o.genFailureCallback(impl)
 	}
 
 	return callbackMap
@@ -300,27 +358,62 @@ func (o *overridePolicyInterrupter) genCallback(impl *tokenCallbackImpl, namespa
 			return err
 		}
 
-		return o.client.Patch(context.Background(), obj, client.RawPatch(types.JSONPatchType, patchBytes))
+		err = o.client.Patch(context.Background(), obj, client.RawPatch(types.JSONPatchType, patchBytes))
+		o.statusManager.SetCondition(impl.statusKey, statusmanager.NewCondition(policyv1alpha1.PolicyConditionTokenReady,
+			policyv1alpha1.PolicyReasonTokenFetched, policyv1alpha1.PolicyReasonTokenFetchFailed, err))
+		return err
 	}
 }
 
-func getTokenGeneratorFromRef(ref *policyv1alpha1.HttpDataRef) tokenmanager.TokenGenerator {
-	if ref == nil || ref.Auth == nil || ref.Auth.StaticToken != "" || ref.Auth.AuthURL == "" {
+func (o *overridePolicyInterrupter) genFailureCallback(impl *tokenCallbackImpl) func(err error) {
+	return func(err error) {
+		o.statusManager.SetCondition(impl.statusKey, statusmanager.NewCondition(policyv1alpha1.PolicyConditionTokenReady,
+			policyv1alpha1.PolicyReasonTokenFetched, policyv1alpha1.PolicyReasonTokenFetchFailed, err))
+	}
+}
+
+// getTokenGeneratorFromRef returns the generator of tokens of ref, credentials in Secrets are read with reader.
+// ServiceAccount tokens have no generator since they're read by cue when requesting rather than written into policies.
+func getTokenGeneratorFromRef(reader client.Reader, ref *policyv1alpha1.HttpDataRef) tokenmanager.TokenGenerator {
+	if ref == nil || ref.Auth == nil || ref.Auth.StaticToken != "" || ref.Auth.StaticTokenSecretRef != nil ||
+		ref.Auth.ServiceAccountToken != nil {
+		return nil
+	}
+
+	auth := ref.Auth
+	if auth.AuthURL == "" {
 		return nil
 	}
 
-	return tokenmanager.NewTokenGenerator(ref.Auth.AuthURL, ref.Auth.Username, ref.Auth.Password, ref.Auth.ExpireDuration.Duration)
+	credentials := newCredentials(reader, auth)
+	switch {
+	case auth.OAuth2 != nil:
+		return tokenmanager.NewOAuth2TokenGenerator(auth.AuthURL, credentials, auth.OAuth2.Scopes, auth.OAuth2.Audience, auth.ExpireDuration.Duration)
+	case auth.JSON != nil:
+		return tokenmanager.NewJSONTokenGenerator(auth.AuthURL, credentials, tokenmanager.JSONTokenOptions{
+			Method:       auth.JSON.Method,
+			TokenPath:    auth.JSON.TokenPath,
+			ExpiryPath:   auth.JSON.ExpiryPath,
+			ExpiryFormat: auth.JSON.ExpiryFormat,
+		}, auth.ExpireDuration.Duration)
+	default:
+		return tokenmanager.NewTokenGeneratorWithCredentials(auth.AuthURL, credentials, auth.ExpireDuration.Duration)
+	}
 }
 
 type tokenCallbackImpl struct {
 	id         string
 	callback   func(token string, expireAt time.Time) error
+	failure    func(err error)
 	generator  tokenmanager.TokenGenerator
 	getPolicy  func(namespace, name string) (client.Object, error)
 	tokenPath  []string
 	expirePath []string
+	statusKey  statusmanager.PolicyKey
 }
 
+var _ tokenmanager.FailureCallback = &tokenCallbackImpl{}
+
 func (t *tokenCallbackImpl) ID() string {
 	return t.id
 }
@@ -329,6 +422,12 @@ func (t *tokenCallbackImpl) Callback(token string, expireAt time.Time) error {
 	return t.callback(token, expireAt)
 }
 
+func (t *tokenCallbackImpl) OnFailure(err error) {
+	if t.failure != nil {
+		t.failure(err)
+	}
+}
+
 func compareCallbackMap(cur, old map[string]*tokenCallbackImpl) (update, remove map[string]*tokenCallbackImpl) {
 	update = make(map[string]*tokenCallbackImpl)
 	remove = make(map[string]*tokenCallbackImpl)
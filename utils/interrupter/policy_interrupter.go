@@ -15,6 +15,7 @@ import (
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/cue"
 )
 
 // PolicyInterrupterManager manage multi PolicyInterrupter and decide which one to use by gvk.
@@ -49,6 +50,11 @@ func (p *policyInterrupterImpl) OnMutating(obj, oldObj *unstructured.Unstructure
 		return nil, nil
 	}
 
+	if operation != admissionv1.Create {
+		// compiled cue programs of the policy are out of date once it's changed or deleted
+		cue.DefaultProgramCache.Invalidate(obj.GetUID())
+	}
+
 	patches, err := interrupter.OnMutating(obj, oldObj, operation)
 	if err != nil {
 		return nil, err
@@ -13,6 +13,7 @@ import (
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/test/mock"
+	"github.com/k-cloud-labs/pkg/utils/cue"
 	fakedtokenmanager "github.com/k-cloud-labs/pkg/utils/tokenmanager/fake"
 )
 
@@ -34,9 +35,11 @@ func test_fakePolicyInterrupterManager(t *testing.T) (PolicyInterrupterManager,
 	copLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterOverridePolicy{}, nil).AnyTimes()
 	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
 	cvpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterValidatePolicy{}, nil).AnyTimes()
+	vpLister := mock.NewMockValidatePolicyLister(ctrl)
+	vpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ValidatePolicy{}, nil).AnyTimes()
 
 	// op
-	overridePolicyInterrupter := NewOverridePolicyInterrupter(baseInterrupter, tokenManager, nil, opLister)
+	overridePolicyInterrupter := NewOverridePolicyInterrupter(baseInterrupter, tokenManager, nil, opLister, nil)
 	policyInterrupterManager.AddInterrupter(schema.GroupVersionKind{
 		Group:   policyv1alpha1.SchemeGroupVersion.Group,
 		Version: policyv1alpha1.SchemeGroupVersion.Version,
@@ -49,11 +52,18 @@ func test_fakePolicyInterrupterManager(t *testing.T) (PolicyInterrupterManager,
 		Kind:    "ClusterOverridePolicy",
 	}, NewClusterOverridePolicyInterrupter(overridePolicyInterrupter, copLister))
 	// cvp
+	clusterValidatePolicyInterrupter := NewClusterValidatePolicyInterrupter(baseInterrupter, tokenManager, nil, cvpLister, nil)
 	policyInterrupterManager.AddInterrupter(schema.GroupVersionKind{
 		Group:   policyv1alpha1.SchemeGroupVersion.Group,
 		Version: policyv1alpha1.SchemeGroupVersion.Version,
 		Kind:    "ClusterValidatePolicy",
-	}, NewClusterValidatePolicyInterrupter(baseInterrupter, tokenManager, nil, cvpLister))
+	}, clusterValidatePolicyInterrupter)
+	// vp
+	policyInterrupterManager.AddInterrupter(schema.GroupVersionKind{
+		Group:   policyv1alpha1.SchemeGroupVersion.Group,
+		Version: policyv1alpha1.SchemeGroupVersion.Version,
+		Kind:    "ValidatePolicy",
+	}, NewValidatePolicyInterrupter(clusterValidatePolicyInterrupter, vpLister))
 
 	return policyInterrupterManager, nil
 }
@@ -336,6 +346,62 @@ validate: {
 			},
 			wantErr: false,
 		},
+		{
+			name: "4",
+			args: args{
+				operation: admissionv1.Create,
+				obj: &unstructured.Unstructured{Object: map[string]any{
+					"apiVersion": "policy.kcloudlabs.io/v1alpha1",
+					"kind":       "ValidatePolicy",
+					"metadata": map[string]any{
+						"namespace": "default",
+						"name":      "vp",
+					},
+					"spec": map[string]any{
+						"validateRules": []map[string]any{
+							{
+								"cue": `
+object: _ @tag(object)
+
+validate: {
+	valid: object.metadata.name != "forbidden"
+}
+`,
+							},
+						},
+					},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "4.1",
+			args: args{
+				operation: admissionv1.Create,
+				obj: &unstructured.Unstructured{Object: map[string]any{
+					"apiVersion": "policy.kcloudlabs.io/v1alpha1",
+					"kind":       "ValidatePolicy",
+					"metadata": map[string]any{
+						"namespace": "default",
+						"name":      "vp",
+					},
+					"spec": map[string]any{
+						"validateRules": []map[string]any{
+							{
+								"cue": `
+object: _ @tag(object)
+
+validate: {
+	valid: object.metadata.name != "forbidden"
+} invalid cue here
+`,
+							},
+						},
+					},
+				}},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1256,6 +1322,39 @@ validate: {
 	}
 }
 
+func Test_policyInterrupterImpl_OnMutating_InvalidatePrograms(t *testing.T) {
+	policyInterrupter, err := test_fakePolicyInterrupterManager(t)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "policy.kcloudlabs.io/v1alpha1",
+		"kind":       "ClusterValidatePolicy",
+		"metadata": map[string]any{
+			"name":       "cvp",
+			"uid":        "cvp-uid",
+			"generation": int64(1),
+		},
+	}}
+	key := cue.ProgramKey{UID: obj.GetUID(), Generation: obj.GetGeneration()}
+	template := `validate: valid: true`
+	p1, err := cue.DefaultProgramCache.Program(key, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := policyInterrupter.OnMutating(obj, obj, admissionv1.Update); err != nil {
+		t.Fatalf("OnMutating() error = %v", err)
+	}
+
+	p2, _ := cue.DefaultProgramCache.Program(key, template)
+	if p1 == p2 {
+		t.Errorf("OnMutating() should invalidate compiled programs of the policy")
+	}
+}
+
 func Test_policyInterrupterImpl_OnStartUp(t *testing.T) {
 	policyInterrupter, err := test_fakePolicyInterrupterManager(t)
 	if err != nil {
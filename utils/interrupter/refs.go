@@ -0,0 +1,79 @@
+package interrupter
+
+import (
+	"fmt"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+// overrideRulesRefs returns value references in override rules.
+func overrideRulesRefs(rules []policyv1alpha1.RuleWithOperation) []*policyv1alpha1.ResourceRefer {
+	var refs []*policyv1alpha1.ResourceRefer
+	for _, rule := range rules {
+		if tmpl := rule.Overriders.Template; tmpl != nil && tmpl.ValueRef != nil {
+			refs = append(refs, tmpl.ValueRef)
+		}
+	}
+
+	return refs
+}
+
+// validateRulesRefs returns value and data references in validate rules.
+func validateRulesRefs(rules []policyv1alpha1.ValidateRuleWithOperation) []*policyv1alpha1.ResourceRefer {
+	var refs []*policyv1alpha1.ResourceRefer
+	for _, rule := range rules {
+		if rule.Template == nil || rule.Template.Condition == nil {
+			continue
+		}
+
+		condition := rule.Template.Condition
+		if condition.ValueRef != nil {
+			refs = append(refs, condition.ValueRef)
+		}
+		if condition.DataRef != nil {
+			refs = append(refs, condition.DataRef)
+		}
+	}
+
+	return refs
+}
+
+// httpRefs returns http references in refs.
+func httpRefs(refs []*policyv1alpha1.ResourceRefer) []*policyv1alpha1.HttpDataRef {
+	var result []*policyv1alpha1.HttpDataRef
+	for _, ref := range refs {
+		if ref.Http != nil {
+			result = append(result, ref.Http)
+		}
+	}
+
+	return result
+}
+
+// validateK8sRefs validates references to k8s objects, which are looked up by apiVersion and kind.
+func validateK8sRefs(refs []*policyv1alpha1.ResourceRefer) error {
+	for _, ref := range refs {
+		if ref.From != policyv1alpha1.FromK8s {
+			continue
+		}
+
+		if ref.K8s == nil || ref.K8s.APIVersion == "" || ref.K8s.Kind == "" {
+			return fmt.Errorf("apiVersion and kind are required in k8s references")
+		}
+	}
+
+	return nil
+}
+
+// validateResourceSelectors validates each selector selects the type of resources,
+// otherwise it selects all types of resources including Secrets.
+func validateResourceSelectors(selectors []policyv1alpha1.ResourceSelector) error {
+	for i, rs := range selectors {
+		if rs.APIVersion == "" && rs.Kind == "" && len(rs.APIGroups) == 0 && len(rs.Versions) == 0 &&
+			len(rs.Kinds) == 0 && len(rs.Resources) == 0 {
+			return fmt.Errorf("resourceSelectors[%d]: one of apiVersion, kind, apiGroups, versions, kinds or resources is required", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package interrupter
+
+import (
+	"testing"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+func Test_validateResourceSelectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors []policyv1alpha1.ResourceSelector
+		wantErr   bool
+	}{
+		{
+			name: "no selectors",
+		},
+		{
+			name:      "apiVersion and kind",
+			selectors: []policyv1alpha1.ResourceSelector{{APIVersion: "apps/v1", Kind: "Deployment"}},
+		},
+		{
+			name:      "wildcards",
+			selectors: []policyv1alpha1.ResourceSelector{{APIGroups: []string{"apps"}, Kinds: []string{"*"}}},
+		},
+		{
+			name:      "namespace only",
+			selectors: []policyv1alpha1.ResourceSelector{{APIVersion: "v1", Kind: "Pod"}, {Namespace: "prod"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateResourceSelectors(tt.selectors); (err != nil) != tt.wantErr {
+				t.Errorf("validateResourceSelectors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateK8sRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     *policyv1alpha1.ResourceRefer
+		wantErr bool
+	}{
+		{
+			name: "k8s",
+			ref: &policyv1alpha1.ResourceRefer{
+				From: policyv1alpha1.FromK8s,
+				K8s:  &policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "ConfigMap", Name: "config"},
+			},
+		},
+		{
+			name: "owner",
+			ref:  &policyv1alpha1.ResourceRefer{From: policyv1alpha1.FromOwnerReference},
+		},
+		{
+			name:    "no selector",
+			ref:     &policyv1alpha1.ResourceRefer{From: policyv1alpha1.FromK8s},
+			wantErr: true,
+		},
+		{
+			name: "no kind",
+			ref: &policyv1alpha1.ResourceRefer{
+				From: policyv1alpha1.FromK8s,
+				K8s:  &policyv1alpha1.ResourceSelector{APIVersion: "v1", Kinds: []string{"ConfigMap"}, Name: "config"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateK8sRefs([]*policyv1alpha1.ResourceRefer{tt.ref}); (err != nil) != tt.wantErr {
+				t.Errorf("validateK8sRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
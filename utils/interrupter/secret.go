@@ -0,0 +1,215 @@
+package interrupter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
+)
+
+// secretCredentials provides the username and password of http references, which are either written inline
+// or read from Secrets. Secrets are read on every refresh of tokens, and tokens are refreshed once
+// resourceVersions of Secrets change, so updates of them take effect without changing policies.
+type secretCredentials struct {
+	reader      client.Reader
+	username    string
+	usernameRef policyv1alpha1.SecretKeyReference
+	password    string
+	passwordRef policyv1alpha1.SecretKeyReference
+}
+
+var _ tokenmanager.VersionedCredentials = secretCredentials{}
+
+// newCredentials returns the credentials of auth, Secrets are read with reader.
+func newCredentials(reader client.Reader, auth *policyv1alpha1.HttpRequestAuth) tokenmanager.Credentials {
+	if auth.UsernameSecretRef == nil && auth.PasswordSecretRef == nil {
+		return tokenmanager.NewStaticCredentials(auth.Username, auth.Password)
+	}
+
+	c := secretCredentials{
+		reader:   reader,
+		username: auth.Username,
+		password: auth.Password,
+	}
+	if auth.UsernameSecretRef != nil {
+		c.usernameRef = *auth.UsernameSecretRef
+	}
+	if auth.PasswordSecretRef != nil {
+		c.passwordRef = *auth.PasswordSecretRef
+	}
+
+	return c
+}
+
+func (c secretCredentials) Get(ctx context.Context) (username, password string, err error) {
+	if username, err = c.get(ctx, c.username, c.usernameRef, corev1.BasicAuthUsernameKey); err != nil {
+		return "", "", err
+	}
+	if password, err = c.get(ctx, c.password, c.passwordRef, corev1.BasicAuthPasswordKey); err != nil {
+		return "", "", err
+	}
+
+	return username, password, nil
+}
+
+// get returns value if ref is empty, otherwise the value of the key referred by ref.
+func (c secretCredentials) get(ctx context.Context, value string, ref policyv1alpha1.SecretKeyReference, defaultKey string) (string, error) {
+	if ref.Name == "" {
+		return value, nil
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+
+	secret, err := c.getSecret(ctx, ref.SecretReference)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", key, ref.Namespace, ref.Name)
+	}
+
+	return string(v), nil
+}
+
+// Version returns resourceVersions of the referred Secrets.
+func (c secretCredentials) Version(ctx context.Context) (string, error) {
+	var versions []string
+	for _, ref := range []policyv1alpha1.SecretKeyReference{c.usernameRef, c.passwordRef} {
+		if ref.Name == "" {
+			continue
+		}
+
+		secret, err := c.getSecret(ctx, ref.SecretReference)
+		if err != nil {
+			return "", err
+		}
+		versions = append(versions, secret.ResourceVersion)
+	}
+
+	return strings.Join(versions, ","), nil
+}
+
+func (c secretCredentials) getSecret(ctx context.Context, ref policyv1alpha1.SecretReference) (*corev1.Secret, error) {
+	if c.reader == nil {
+		return nil, errors.New("no client to read secrets")
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.reader.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("get secret %s/%s got error=%w", ref.Namespace, ref.Name, err)
+	}
+
+	return secret, nil
+}
+
+func (c secretCredentials) String() string {
+	if c.usernameRef.Name != "" {
+		return fmt.Sprintf("secret/%s/%s", c.usernameRef.Namespace, c.usernameRef.Name)
+	}
+
+	return c.username
+}
+
+// validateHttpAuth validates credentials and the token source of auth,
+// inline credentials are rejected if disallowInlineSecrets is true.
+func validateHttpAuth(auth *policyv1alpha1.HttpRequestAuth, disallowInlineSecrets bool) error {
+	if auth == nil {
+		return nil
+	}
+
+	if auth.StaticToken != "" && auth.StaticTokenSecretRef != nil {
+		return errors.New("staticToken and staticTokenSecretRef are mutually exclusive")
+	}
+	if auth.Username != "" && auth.UsernameSecretRef != nil {
+		return errors.New("username and usernameSecretRef are mutually exclusive")
+	}
+	if auth.Password != "" && auth.PasswordSecretRef != nil {
+		return errors.New("password and passwordSecretRef are mutually exclusive")
+	}
+
+	if disallowInlineSecrets && (auth.StaticToken != "" || auth.Username != "" || auth.Password != "") {
+		return errors.New("inline credentials are not allowed, use staticTokenSecretRef, usernameSecretRef and passwordSecretRef instead")
+	}
+
+	return validateTokenSource(auth)
+}
+
+// validateSecretNamespaces rejects Secrets referred by ref out of namespace, so namespaced policies can't
+// read credentials of other namespaces. Empty namespace means cluster scoped policies, which may refer to any Secret.
+func validateSecretNamespaces(ref *policyv1alpha1.HttpDataRef, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	for _, secret := range httpSecretRefs(ref) {
+		if secret.Namespace != namespace {
+			return fmt.Errorf("secret %s/%s must be in the namespace %s of the policy", secret.Namespace, secret.Name, namespace)
+		}
+	}
+
+	return nil
+}
+
+// httpSecretRefs returns all Secrets referred by ref.
+func httpSecretRefs(ref *policyv1alpha1.HttpDataRef) []policyv1alpha1.SecretReference {
+	var refs []policyv1alpha1.SecretReference
+	if tls := ref.TLS; tls != nil {
+		if tls.CA != nil {
+			refs = append(refs, tls.CA.SecretReference)
+		}
+		if tls.ClientCert != nil {
+			refs = append(refs, *tls.ClientCert)
+		}
+	}
+	if auth := ref.Auth; auth != nil {
+		for _, keyRef := range []*policyv1alpha1.SecretKeyReference{auth.StaticTokenSecretRef, auth.UsernameSecretRef, auth.PasswordSecretRef} {
+			if keyRef != nil {
+				refs = append(refs, keyRef.SecretReference)
+			}
+		}
+	}
+
+	return refs
+}
+
+// validateTokenSource validates settings of the token generator of auth.
+func validateTokenSource(auth *policyv1alpha1.HttpRequestAuth) error {
+	var sources int
+	for _, set := range []bool{auth.OAuth2 != nil, auth.ServiceAccountToken != nil, auth.JSON != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("oauth2, serviceAccountToken and json are mutually exclusive")
+	}
+
+	if (auth.OAuth2 != nil || auth.JSON != nil) && auth.AuthURL == "" {
+		return errors.New("authUrl is required to request tokens")
+	}
+
+	if auth.JSON != nil {
+		if _, err := tokenmanager.ParseJSONPath(auth.JSON.TokenPath); err != nil {
+			return fmt.Errorf("invalid tokenPath: %w", err)
+		}
+		if auth.JSON.ExpiryPath != "" {
+			if _, err := tokenmanager.ParseJSONPath(auth.JSON.ExpiryPath); err != nil {
+				return fmt.Errorf("invalid expiryPath: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
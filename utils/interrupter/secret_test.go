@@ -0,0 +1,286 @@
+package interrupter
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/tokenmanager"
+)
+
+func Test_secretCredentials(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "auth"},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("user"),
+			corev1.BasicAuthPasswordKey: []byte("pass"),
+			"other":                     []byte("other-pass"),
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	ref := func(key string) *policyv1alpha1.SecretKeyReference {
+		return &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "auth"}, Key: key}
+	}
+
+	tests := []struct {
+		name         string
+		auth         *policyv1alpha1.HttpRequestAuth
+		wantUsername string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name:         "inline",
+			auth:         &policyv1alpha1.HttpRequestAuth{Username: "u", Password: "p"},
+			wantUsername: "u",
+			wantPassword: "p",
+		},
+		{
+			name:         "secret with default keys",
+			auth:         &policyv1alpha1.HttpRequestAuth{UsernameSecretRef: ref(""), PasswordSecretRef: ref("")},
+			wantUsername: "user",
+			wantPassword: "pass",
+		},
+		{
+			name:         "inline username and password in secret",
+			auth:         &policyv1alpha1.HttpRequestAuth{Username: "u", PasswordSecretRef: ref("other")},
+			wantUsername: "u",
+			wantPassword: "other-pass",
+		},
+		{
+			name:    "key not found",
+			auth:    &policyv1alpha1.HttpRequestAuth{PasswordSecretRef: ref("none")},
+			wantErr: true,
+		},
+		{
+			name: "secret not found",
+			auth: &policyv1alpha1.HttpRequestAuth{PasswordSecretRef: &policyv1alpha1.SecretKeyReference{
+				SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "none"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := newCredentials(c, tt.auth).Get(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("Get() = %s, %s, want %s, %s", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+
+	t.Run("secret updated", func(t *testing.T) {
+		auth := &policyv1alpha1.HttpRequestAuth{UsernameSecretRef: ref(""), PasswordSecretRef: ref("")}
+		credentials := newCredentials(c, auth)
+		version, err := credentials.(tokenmanager.VersionedCredentials).Version(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		updated := secret.DeepCopy()
+		updated.Data[corev1.BasicAuthPasswordKey] = []byte("new-pass")
+		if err := c.Update(context.Background(), updated); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, password, _ := credentials.Get(context.Background()); password != "new-pass" {
+			t.Errorf("Get() password = %s, want the updated one", password)
+		}
+		if newVersion, _ := credentials.(tokenmanager.VersionedCredentials).Version(context.Background()); newVersion == version {
+			t.Errorf("Version() = %s, want it changed after the secret is updated", newVersion)
+		}
+		// generators are equal as long as references are not changed
+		if newCredentials(c, auth.DeepCopy()) != credentials {
+			t.Errorf("credentials with the same references should be equal")
+		}
+	})
+}
+
+func Test_validateSecretNamespaces(t *testing.T) {
+	ref := func(namespace string) *policyv1alpha1.SecretKeyReference {
+		return &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: namespace, Name: "auth"}}
+	}
+	tests := []struct {
+		name      string
+		ref       *policyv1alpha1.HttpDataRef
+		namespace string
+		wantErr   bool
+	}{
+		{
+			name: "cluster scoped policy",
+			ref: &policyv1alpha1.HttpDataRef{Auth: &policyv1alpha1.HttpRequestAuth{
+				PasswordSecretRef: ref("other"),
+			}},
+		},
+		{
+			name: "same namespace",
+			ref: &policyv1alpha1.HttpDataRef{
+				Auth: &policyv1alpha1.HttpRequestAuth{UsernameSecretRef: ref("ns"), PasswordSecretRef: ref("ns")},
+				TLS:  &policyv1alpha1.HttpTLSConfig{CA: ref("ns"), ClientCert: &ref("ns").SecretReference},
+			},
+			namespace: "ns",
+		},
+		{
+			name: "password in other namespace",
+			ref: &policyv1alpha1.HttpDataRef{Auth: &policyv1alpha1.HttpRequestAuth{
+				UsernameSecretRef: ref("ns"), PasswordSecretRef: ref("other"),
+			}},
+			namespace: "ns",
+			wantErr:   true,
+		},
+		{
+			name: "static token in other namespace",
+			ref: &policyv1alpha1.HttpDataRef{Auth: &policyv1alpha1.HttpRequestAuth{
+				StaticTokenSecretRef: ref("kube-system"),
+			}},
+			namespace: "ns",
+			wantErr:   true,
+		},
+		{
+			name:      "client cert in other namespace",
+			ref:       &policyv1alpha1.HttpDataRef{TLS: &policyv1alpha1.HttpTLSConfig{ClientCert: &ref("other").SecretReference}},
+			namespace: "ns",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSecretNamespaces(tt.ref, tt.namespace); (err != nil) != tt.wantErr {
+				t.Errorf("validateSecretNamespaces() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateHttpAuth(t *testing.T) {
+	ref := &policyv1alpha1.SecretKeyReference{SecretReference: policyv1alpha1.SecretReference{Namespace: "ns", Name: "auth"}}
+	tests := []struct {
+		name                  string
+		auth                  *policyv1alpha1.HttpRequestAuth
+		disallowInlineSecrets bool
+		wantErr               bool
+	}{
+		{
+			name:                  "no auth",
+			disallowInlineSecrets: true,
+		},
+		{
+			name: "inline allowed",
+			auth: &policyv1alpha1.HttpRequestAuth{StaticToken: "token"},
+		},
+		{
+			name:                  "inline disallowed",
+			auth:                  &policyv1alpha1.HttpRequestAuth{Username: "u", PasswordSecretRef: ref},
+			disallowInlineSecrets: true,
+			wantErr:               true,
+		},
+		{
+			name:                  "secret refs",
+			auth:                  &policyv1alpha1.HttpRequestAuth{UsernameSecretRef: ref, PasswordSecretRef: ref, AuthURL: "http://auth"},
+			disallowInlineSecrets: true,
+		},
+		{
+			name:    "mutually exclusive",
+			auth:    &policyv1alpha1.HttpRequestAuth{StaticToken: "token", StaticTokenSecretRef: ref},
+			wantErr: true,
+		},
+		{
+			name: "mutually exclusive token sources",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				AuthURL:             "http://auth",
+				OAuth2:              &policyv1alpha1.OAuth2ClientCredentials{},
+				ServiceAccountToken: &policyv1alpha1.ServiceAccountTokenSource{},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "oauth2 without url",
+			auth:    &policyv1alpha1.HttpRequestAuth{OAuth2: &policyv1alpha1.OAuth2ClientCredentials{Scopes: []string{"read"}}},
+			wantErr: true,
+		},
+		{
+			name: "json",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				AuthURL: "http://auth",
+				JSON:    &policyv1alpha1.JSONTokenSource{TokenPath: "{.data.token}", ExpiryPath: ".data.expiresIn"},
+			},
+		},
+		{
+			name: "invalid jsonpath",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				AuthURL: "http://auth",
+				JSON:    &policyv1alpha1.JSONTokenSource{TokenPath: "{.data[}"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateHttpAuth(tt.auth, tt.disallowInlineSecrets); (err != nil) != tt.wantErr {
+				t.Errorf("validateHttpAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_getTokenGeneratorFromRef(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   *policyv1alpha1.HttpRequestAuth
+		wantID string
+	}{
+		{
+			name: "no auth",
+		},
+		{
+			name: "static token",
+			auth: &policyv1alpha1.HttpRequestAuth{StaticToken: "token", AuthURL: "http://auth/token"},
+		},
+		{
+			name:   "basic",
+			auth:   &policyv1alpha1.HttpRequestAuth{AuthURL: "http://auth/token", Username: "u", Password: "p"},
+			wantID: "auth:u",
+		},
+		{
+			name: "oauth2",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				AuthURL:  "http://auth/token",
+				Username: "client",
+				OAuth2:   &policyv1alpha1.OAuth2ClientCredentials{Scopes: []string{"read"}},
+			},
+			wantID: "oauth2:auth:client",
+		},
+		{
+			// read by cue when requesting rather than written into policies
+			name: "service account token",
+			auth: &policyv1alpha1.HttpRequestAuth{ServiceAccountToken: &policyv1alpha1.ServiceAccountTokenSource{}},
+		},
+		{
+			name: "json",
+			auth: &policyv1alpha1.HttpRequestAuth{
+				AuthURL:  "http://auth/token",
+				Username: "u",
+				JSON:     &policyv1alpha1.JSONTokenSource{TokenPath: ".token"},
+			},
+			wantID: "json:auth:u",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := getTokenGeneratorFromRef(nil, &policyv1alpha1.HttpDataRef{Auth: tt.auth})
+			if (tg == nil) != (tt.wantID == "") {
+				t.Fatalf("getTokenGeneratorFromRef() = %v, want id %q", tg, tt.wantID)
+			}
+			if tg != nil && tg.ID() != tt.wantID {
+				t.Errorf("getTokenGeneratorFromRef() id = %v, want %v", tg.ID(), tt.wantID)
+			}
+		})
+	}
+}
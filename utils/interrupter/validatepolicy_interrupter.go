@@ -0,0 +1,175 @@
+package interrupter
+
+import (
+	"fmt"
+	"reflect"
+
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
+)
+
+type validatePolicyInterrupter struct {
+	*clusterValidatePolicyInterrupter
+	lister v1alpha1.ValidatePolicyLister
+}
+
+func (v *validatePolicyInterrupter) OnMutating(obj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) ([]jsonpatchv2.JsonPatchOperation, error) {
+	vp := new(policyv1alpha1.ValidatePolicy)
+	if err := convertToPolicy(obj, vp); err != nil {
+		return nil, err
+	}
+
+	var old *policyv1alpha1.ValidatePolicy
+	if oldObj != nil {
+		old = new(policyv1alpha1.ValidatePolicy)
+		if err := convertToPolicy(oldObj, old); err != nil {
+			return nil, err
+		}
+	}
+
+	// UPDATE vp
+	if old != nil {
+		// no change
+		if reflect.DeepEqual(vp.Spec, old.Spec) {
+			return nil, nil
+		}
+	}
+
+	patches, err := v.patchValidateRules(vp.Spec.ValidateRules, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	v.handleValueRef(vp, old, operation)
+	return patches, nil
+}
+
+func (v *validatePolicyInterrupter) OnValidating(obj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) error {
+	if operation == admissionv1.Delete {
+		return nil
+	}
+
+	vp := new(policyv1alpha1.ValidatePolicy)
+	if err := convertToPolicy(obj, vp); err != nil {
+		return err
+	}
+
+	var old *policyv1alpha1.ValidatePolicy
+	if oldObj != nil {
+		old = new(policyv1alpha1.ValidatePolicy)
+		if err := convertToPolicy(oldObj, old); err != nil {
+			return err
+		}
+	}
+
+	// UPDATE vp
+	if old != nil {
+		// no change
+		if reflect.DeepEqual(vp.Spec, old.Spec) {
+			return nil
+		}
+	}
+
+	if err := v.validateValidateRules(vp.Spec.ValidateRules); err != nil {
+		return err
+	}
+	if err := validateResourceSelectors(vp.Spec.ResourceSelectors); err != nil {
+		return err
+	}
+
+	refs := validateRulesRefs(vp.Spec.ValidateRules)
+	if err := validateK8sRefs(refs); err != nil {
+		return err
+	}
+	if err := v.validateHttpRefs(httpRefs(refs), vp.Namespace); err != nil {
+		return err
+	}
+
+	v.recordCueCompiled(v.statusKey(vp.Namespace, vp.Name), nil)
+	return nil
+}
+
+func (v *validatePolicyInterrupter) OnStartUp() error {
+	list, err := v.lister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range list {
+		v.handleValueRef(policy, nil, admissionv1.Create)
+		v.recordCueCompiled(v.statusKey(policy.Namespace, policy.Name), v.validateValidateRules(policy.Spec.ValidateRules))
+	}
+
+	return nil
+}
+
+// NewValidatePolicyInterrupter returns a PolicyInterrupter for ValidatePolicy,
+// cvpInterrupter must be created by NewClusterValidatePolicyInterrupter.
+func NewValidatePolicyInterrupter(cvpInterrupter PolicyInterrupter, lister v1alpha1.ValidatePolicyLister) PolicyInterrupter {
+	return &validatePolicyInterrupter{
+		clusterValidatePolicyInterrupter: cvpInterrupter.(*clusterValidatePolicyInterrupter),
+		lister:                           lister,
+	}
+}
+
+func (v *validatePolicyInterrupter) statusKey(namespace, name string) statusmanager.PolicyKey {
+	return statusmanager.PolicyKey{Kind: statusmanager.KindValidatePolicy, Namespace: namespace, Name: name}
+}
+
+func (v *validatePolicyInterrupter) handleValueRef(policy, oldPolicy *policyv1alpha1.ValidatePolicy, operation admissionv1.Operation) {
+	newCallbackMap := v.getTokenCallbackMap(policy)
+
+	var oldCallbackMap map[string]*tokenCallbackImpl
+	if operation == admissionv1.Update && oldPolicy != nil {
+		oldCallbackMap = v.getTokenCallbackMap(oldPolicy)
+	}
+
+	if operation == admissionv1.Create {
+		for _, impl := range newCallbackMap {
+			v.tokenManager.AddToken(impl.generator, impl)
+		}
+		return
+	}
+
+	if operation == admissionv1.Update {
+		needUpdate, needRemove := compareCallbackMap(newCallbackMap, oldCallbackMap)
+		for _, impl := range needRemove {
+			v.tokenManager.RemoveToken(impl.generator, impl)
+		}
+
+		for _, impl := range needUpdate {
+			v.tokenManager.AddToken(impl.generator, impl)
+		}
+
+		return
+	}
+
+	if operation == admissionv1.Delete {
+		for _, impl := range newCallbackMap {
+			v.tokenManager.RemoveToken(impl.generator, impl)
+		}
+	}
+}
+
+func (v *validatePolicyInterrupter) getTokenCallbackMap(policy *policyv1alpha1.ValidatePolicy) map[string]*tokenCallbackImpl {
+	callbackMap := getValidateRulesCallbackMap(policy.Spec.ValidateRules, v.client, v.getPolicy)
+	for _, impl := range callbackMap {
+		impl.id = fmt.Sprintf("%s/%s/%s", policy.GroupVersionKind(), policy.Namespace, policy.Name)
+		impl.statusKey = v.statusKey(policy.Namespace, policy.Name)
+		impl.callback = v.genCallback(impl, policy.Namespace, policy.Name)
+		impl.failure = v.genFailureCallback(impl)
+	}
+
+	return callbackMap
+}
+
+func (v *validatePolicyInterrupter) getPolicy(namespace, name string) (client.Object, error) {
+	return v.lister.ValidatePolicies(namespace).Get(name)
+}
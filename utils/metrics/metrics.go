@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -13,12 +14,25 @@ type ErrorType string
 const (
 	ErrorTypeUnknown        ErrorType = "unknown"
 	ErrorTypeCueExecute     ErrorType = "cue_execute_error"
+	ErrorTypeCelExecute     ErrorType = "cel_execute_error"
+	ErrorTypeTimeout        ErrorType = "timeout_error"
+	ErrorTypeFailureIgnored ErrorType = "failure_ignored"
 	ErrorTypeOriginExecute  ErrorType = "cue_origin_error"
 	ErrTypePrepareCueParams ErrorType = "prepare_cue_params_error"
 
 	SubSystemName = "kcloudlabs"
 )
 
+// HTTPCacheResult is the result of looking up the cache of http references.
+type HTTPCacheResult string
+
+const (
+	HTTPCacheHit         HTTPCacheResult = "hit"
+	HTTPCacheStale       HTTPCacheResult = "stale"
+	HTTPCacheMiss        HTTPCacheResult = "miss"
+	HTTPCacheNegativeHit HTTPCacheResult = "negative_hit"
+)
+
 var (
 	policyTotalNumber = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -71,7 +85,7 @@ var (
 			Name:      "validate_policy_reject_count",
 			Help:      "The number of resources changes rejected by validate policies",
 		},
-		[]string{"name", "resource_type"},
+		[]string{"name", "resource_type", "enforcement_action"},
 	)
 
 	policyErrorCount = prometheus.NewCounterVec(
@@ -92,6 +106,33 @@ var (
 		[]string{"name", "resource_type"},
 	)
 
+	backgroundApplyCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: SubSystemName,
+			Name:      "background_apply_count",
+			Help:      "Count of existing resources handled by background reconciler of override policies",
+		},
+		[]string{"resource_type", "result"},
+	)
+
+	auditPassNumber = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: SubSystemName,
+			Name:      "audit_pass_number",
+			Help:      "Number of existing resources passed validate policy in the last audit",
+		},
+		[]string{"name"},
+	)
+
+	auditFailNumber = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: SubSystemName,
+			Name:      "audit_fail_number",
+			Help:      "Number of existing resources violated validate policy in the last audit",
+		},
+		[]string{"name"},
+	)
+
 	resourceSyncErrorCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Subsystem: SubSystemName,
@@ -100,6 +141,34 @@ var (
 		},
 		[]string{"resource_type"},
 	)
+
+	httpCacheRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: SubSystemName,
+			Name:      "http_cache_request_count",
+			Help:      "Count of http references looked up in the response cache by result",
+		},
+		[]string{"result"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: SubSystemName,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of http requests made by policies by client, host, method and status code",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"client", "host", "method", "code"},
+	)
+
+	httpCircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: SubSystemName,
+			Name:      "http_circuit_breaker_state",
+			Help:      "State of circuit breakers of http hosts, 0 is closed, 1 is open and 2 is half-open",
+		},
+		[]string{"host"},
+	)
 )
 
 func init() {
@@ -111,7 +180,13 @@ func init() {
 		validatePolicyMatchedCount,
 		validatePolicyRejectCount,
 		policyErrorCount,
+		backgroundApplyCount,
+		auditPassNumber,
+		auditFailNumber,
 		resourceSyncErrorCount,
+		httpCacheRequestCount,
+		httpRequestDuration,
+		httpCircuitBreakerState,
 	)
 }
 
@@ -150,9 +225,9 @@ func ValidatePolicyMatched(policyName string, resourceGVK schema.GroupVersionKin
 		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind)).Inc()
 }
 
-func ValidatePolicyReject(policyName string, resourceGVK schema.GroupVersionKind) {
+func ValidatePolicyReject(policyName string, resourceGVK schema.GroupVersionKind, enforcementAction string) {
 	validatePolicyRejectCount.WithLabelValues(policyName,
-		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind)).Inc()
+		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind), enforcementAction).Inc()
 }
 
 func PolicyGotError(policyName string, resourceGVK schema.GroupVersionKind, errorType ErrorType) {
@@ -166,8 +241,43 @@ func PolicySuccess(policyName string, resourceGVK schema.GroupVersionKind) {
 		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind)).Inc()
 }
 
+// Results of background apply.
+const (
+	BackgroundApplyPatched   = "patched"
+	BackgroundApplyUnchanged = "unchanged"
+	BackgroundApplyDryRun    = "dry_run"
+	BackgroundApplyError     = "error"
+)
+
+func BackgroundApply(resourceGVK schema.GroupVersionKind, result string) {
+	backgroundApplyCount.WithLabelValues(
+		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind), result).Inc()
+}
+
+func SetAuditResult(policyName string, pass, fail int64) {
+	auditPassNumber.WithLabelValues(policyName).Set(float64(pass))
+	auditFailNumber.WithLabelValues(policyName).Set(float64(fail))
+}
+
+func DeleteAuditResult(policyName string) {
+	auditPassNumber.DeleteLabelValues(policyName)
+	auditFailNumber.DeleteLabelValues(policyName)
+}
+
 func SyncResourceError(resourceGVK schema.GroupVersionKind) {
 	resourceSyncErrorCount.WithLabelValues(
 		fmt.Sprintf("%s/%s/%s", resourceGVK.Group, resourceGVK.Version, resourceGVK.Kind),
 	).Inc()
 }
+
+func HTTPCacheRequest(result HTTPCacheResult) {
+	httpCacheRequestCount.WithLabelValues(string(result)).Inc()
+}
+
+func HTTPRequestDone(client, host, method, code string, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(client, host, method, code).Observe(duration.Seconds())
+}
+
+func SetHTTPCircuitBreakerState(host string, state int) {
+	httpCircuitBreakerState.WithLabelValues(host).Set(float64(state))
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrOptOutNotAllowed means the requesting user is not allowed to set the opt-out annotation.
+var ErrOptOutNotAllowed = errors.New("not allowed to opt out of policies")
+
+// OptOutAllowList defines who may set the SkipPolicies annotation on objects.
+// Users are matched by username, and groups are matched by any group of the user.
+type OptOutAllowList struct {
+	Users  []string
+	Groups []string
+}
+
+// Allows tells if the user is in the allow-list.
+func (l *OptOutAllowList) Allows(userInfo *authenticationv1.UserInfo) bool {
+	if l == nil || userInfo == nil {
+		return false
+	}
+
+	for _, u := range l.Users {
+		if u == userInfo.Username {
+			return true
+		}
+	}
+
+	for _, g := range l.Groups {
+		for _, ug := range userInfo.Groups {
+			if g == ug {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ObjectOptedOut tells if the object opted out of all policies with the SkipPolicies annotation.
+// The annotation is ignored if allowList is nil.
+// If the annotation is already set in oldObj, it's trusted since setting it has been authorized before,
+// otherwise the requesting user in RequestInfo of ctx must be in the allow-list, or ErrOptOutNotAllowed is returned.
+// Pass the object itself as oldObj to check an object already persisted.
+func ObjectOptedOut(ctx context.Context, obj, oldObj *unstructured.Unstructured, allowList *OptOutAllowList) (bool, error) {
+	if allowList == nil || obj == nil || obj.GetAnnotations()[SkipPolicies] != SkipPoliciesValue {
+		return false, nil
+	}
+
+	if oldObj != nil && oldObj.GetAnnotations()[SkipPolicies] == SkipPoliciesValue {
+		return true, nil
+	}
+
+	ri := RequestInfoFromContext(ctx)
+	if ri == nil || !allowList.Allows(&ri.UserInfo) {
+		username := ""
+		if ri != nil {
+			username = ri.UserInfo.Username
+		}
+		return false, fmt.Errorf("user %q is %w with annotation %s", username, ErrOptOutNotAllowed, SkipPolicies)
+	}
+
+	return true, nil
+}
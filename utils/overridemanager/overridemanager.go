@@ -4,22 +4,28 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cel"
 	"github.com/k-cloud-labs/pkg/utils/cue"
 	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
 	"github.com/k-cloud-labs/pkg/utils/metrics"
 	"github.com/k-cloud-labs/pkg/utils/origin"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
 	"github.com/k-cloud-labs/pkg/utils/util"
 )
 
@@ -27,11 +33,16 @@ import (
 type OverrideManager interface {
 	// ApplyOverridePolicies overrides the object if one or more matched override policies exist.
 	// For cluster scoped resource:
-	// - Apply ClusterOverridePolicy by policies name in ascending
+	// - Apply ClusterOverridePolicy by policies priority in ascending, policies name is used as a tie-breaker
 	// For namespaced scoped resource, apply order is:
 	// - First apply ClusterOverridePolicy;
 	// - Then apply OverridePolicy;
+	// No policy is applied if the object opted out with the utils.SkipPolicies annotation.
+	// Rules are matched with the admission request metadata in ctx, see utils.ContextWithRequestInfo.
 	ApplyOverridePolicies(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (appliedCOPs *AppliedOverrides, appliedOPs *AppliedOverrides, err error)
+	// ReapplyOverridePolicies reverts the overrides recorded in applied overrides annotations of the object,
+	// then applies the current override policies again, so objects converge after policies changed or deleted.
+	ReapplyOverridePolicies(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (appliedCOPs *AppliedOverrides, appliedOPs *AppliedOverrides, err error)
 }
 
 // GeneralOverridePolicy is an abstract object of ClusterOverridePolicy and OverridePolicy
@@ -40,6 +51,10 @@ type GeneralOverridePolicy interface {
 	GetName() string
 	// GetNamespace returns the namespace of OverridePolicy
 	GetNamespace() string
+	// GetUID returns the UID of OverridePolicy
+	GetUID() types.UID
+	// GetGeneration returns the generation of OverridePolicy
+	GetGeneration() int64
 	// GetOverridePolicySpec returns the OverridePolicySpec of OverridePolicy
 	GetOverridePolicySpec() policyv1alpha1.OverridePolicySpec
 }
@@ -54,20 +69,121 @@ type overrideOption struct {
 type policyOverriders struct {
 	name       string
 	namespace  string
+	priority   int32
 	overriders policyv1alpha1.Overriders
+	// programKey is the key of compiled cue programs of the policy
+	programKey cue.ProgramKey
+	// timeout is the timeout of applying all overriders of the policy, zero means no timeout
+	timeout time.Duration
+	// ruleIndex is the index of the rule of overriders in the policy
+	ruleIndex int
+	// failurePolicy is the failure policy of the rule of overriders
+	failurePolicy policyv1alpha1.FailurePolicyType
+	// ignorable is true if any rule of the policy ignores failures, so the policy may be skipped
+	ignorable bool
+}
+
+// policyDeadlines tracks deadlines of policies being applied,
+// so the timeout of a policy limits the time of applying all its overriders rather than each of them.
+type policyDeadlines map[statusmanager.PolicyKey]time.Time
+
+// context returns the context to apply overriders of p with the deadline of its policy, if it has a timeout.
+func (d policyDeadlines) context(ctx context.Context, p policyOverriders) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	deadline, ok := d[p.statusKey()]
+	if !ok {
+		deadline = time.Now().Add(p.timeout)
+		d[p.statusKey()] = deadline
+	}
+
+	return context.WithDeadline(ctx, deadline)
+}
+
+// policyFailures skips policies whose rules failed with failurePolicy Ignore.
+// Rules of a policy are applied one by one, so the object is kept before applying a policy which may be skipped,
+// and the overrides already applied by the policy are reverted once it's skipped.
+type policyFailures struct {
+	current  statusmanager.PolicyKey
+	snapshot *unstructured.Unstructured
+	// applied is the number of overrides applied before the current policy
+	applied int
+	skipped map[statusmanager.PolicyKey]bool
+	// policies is the list of skipped policies in the order they are skipped
+	policies []utils.SkippedPolicy
+}
+
+// begin is called before applying overriders of p with the number of overrides applied,
+// it returns false if the policy of p has been skipped.
+func (f *policyFailures) begin(p policyOverriders, obj *unstructured.Unstructured, applied int) bool {
+	key := p.statusKey()
+	if f.skipped[key] {
+		return false
+	}
+
+	if key != f.current {
+		f.current = key
+		f.applied = applied
+		f.snapshot = nil
+		if p.ignorable {
+			f.snapshot = obj.DeepCopy()
+		}
+	}
+
+	return true
+}
+
+// skip skips the policy of p failed with err, and restores the object to the one before the policy is applied.
+// It returns the number of overrides applied before the policy, the ones after it should be dropped.
+func (f *policyFailures) skip(p policyOverriders, obj *unstructured.Unstructured, err error) int {
+	if f.skipped == nil {
+		f.skipped = make(map[statusmanager.PolicyKey]bool)
+	}
+	f.skipped[p.statusKey()] = true
+	f.policies = append(f.policies, utils.SkippedPolicy{
+		PolicyName: p.policyName(),
+		RuleIndex:  p.ruleIndex,
+		Reason:     err.Error(),
+	})
+	if f.snapshot != nil {
+		obj.Object = f.snapshot.Object
+		f.snapshot = nil
+	}
+
+	return f.applied
 }
 
 type overrideManagerImpl struct {
 	dynamicLister dynamiclister.DynamicResourceLister
 	opLister      v1alpha1.OverridePolicyLister
 	copLister     v1alpha1.ClusterOverridePolicyLister
+	statusManager statusmanager.StatusManager
+	optOut        *utils.OptOutAllowList
+}
+
+// OverrideManagerOptions defines optional settings of OverrideManager.
+type OverrideManagerOptions struct {
+	// StatusManager records status of policies, nil means status of policies will not be recorded.
+	StatusManager statusmanager.StatusManager
+	// OptOut allows users to opt out objects with annotation, nil means the opt-out annotation of objects is ignored.
+	OptOut *utils.OptOutAllowList
 }
 
-func NewOverrideManager(dynamicClient dynamiclister.DynamicResourceLister, copLister v1alpha1.ClusterOverridePolicyLister, opLister v1alpha1.OverridePolicyLister) OverrideManager {
+// NewOverrideManager returns an implement of OverrideManager.
+func NewOverrideManager(dynamicClient dynamiclister.DynamicResourceLister, copLister v1alpha1.ClusterOverridePolicyLister,
+	opLister v1alpha1.OverridePolicyLister, opts OverrideManagerOptions) OverrideManager {
+	if opts.StatusManager == nil {
+		opts.StatusManager = statusmanager.NewNopStatusManager()
+	}
+
 	return &overrideManagerImpl{
 		dynamicLister: dynamicClient,
 		opLister:      opLister,
 		copLister:     copLister,
+		statusManager: opts.StatusManager,
+		optOut:        opts.OptOut,
 	}
 }
 
@@ -78,6 +194,16 @@ func (o *overrideManagerImpl) ApplyOverridePolicies(ctx context.Context, rawObj,
 		err         error
 	)
 
+	optedOut, err := utils.ObjectOptedOut(ctx, rawObj, oldObj, o.optOut)
+	if err != nil {
+		// the annotation is ignored and policies are still applied
+		klog.ErrorS(err, "Ignored opt-out annotation.", "resource", klog.KObj(rawObj), "operation", operation)
+	}
+	if optedOut {
+		klog.V(2).InfoS("Skip override policies for object opted out.", "resource", klog.KObj(rawObj), "operation", operation)
+		return nil, nil, nil
+	}
+
 	appliedCOPs, err = o.applyClusterOverridePolicies(ctx, rawObj, oldObj, operation)
 	if err != nil {
 		klog.ErrorS(err, "Failed to apply cluster override policies.")
@@ -96,6 +222,21 @@ func (o *overrideManagerImpl) ApplyOverridePolicies(ctx context.Context, rawObj,
 	return appliedCOPs, appliedOPs, nil
 }
 
+func (o *overrideManagerImpl) ReapplyOverridePolicies(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (*AppliedOverrides, *AppliedOverrides, error) {
+	// the object is left as it is if it has opted out
+	if optedOut, _ := utils.ObjectOptedOut(ctx, rawObj, rawObj, o.optOut); optedOut {
+		klog.V(2).InfoS("Skip reapplying override policies for object opted out.", "resource", klog.KObj(rawObj))
+		return nil, nil, nil
+	}
+
+	if err := RevertOverrides(rawObj); err != nil {
+		klog.ErrorS(err, "Failed to revert applied overrides.", "resource", klog.KObj(rawObj))
+		return nil, nil, err
+	}
+
+	return o.ApplyOverridePolicies(ctx, rawObj, oldObj, operation)
+}
+
 func (o *overrideManagerImpl) applyClusterOverridePolicies(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (*AppliedOverrides, error) {
 	defer traceStep(ctx, "applyClusterOverridePolicies finished")
 	traceStep(ctx, "About to list cop")
@@ -116,23 +257,42 @@ func (o *overrideManagerImpl) applyClusterOverridePolicies(ctx context.Context,
 		items = append(items, cops[i])
 	}
 
-	matchingPolicyOverriders := o.getOverridersFromOverridePolicies(items, rawObj, operation)
+	matchingPolicyOverriders := o.getOverridersFromOverridePolicies(ctx, items, rawObj, operation, utils.RequestInfoFromContext(ctx))
 	if len(matchingPolicyOverriders) == 0 {
 		klog.V(2).InfoS("No cluster override policy.", "resource", klog.KObj(rawObj), "operation", operation)
 		return nil, nil
 	}
 
 	appliedOverrides := &AppliedOverrides{}
+	deadlines := policyDeadlines{}
+	failures := &policyFailures{}
 	for _, p := range matchingPolicyOverriders {
+		if !failures.begin(p, rawObj, len(appliedOverrides.AppliedItems)) {
+			continue
+		}
 		metrics.OverridePolicyMatched(p.name, rawObj.GroupVersionKind())
-		if err := o.applyPolicyOverriders(ctx, rawObj, oldObj, p); err != nil {
+		o.statusManager.RecordMatched(p.statusKey())
+		policyCtx, cancel := deadlines.context(ctx, p)
+		patches, inverse, err := o.applyPolicyOverriders(policyCtx, rawObj, oldObj, operation, p)
+		cancel()
+		if err != nil {
+			o.statusManager.RecordError(p.statusKey(), err)
+			if utils.IgnoreFailure(p.failurePolicy) {
+				klog.ErrorS(err, "Skipped cluster override policy since failure is ignored.", "clusteroverridepolicy", p.name,
+					"ruleIndex", p.ruleIndex, "resource", klog.KObj(rawObj), "operation", operation)
+				metrics.PolicyGotError(p.name, rawObj.GroupVersionKind(), metrics.ErrorTypeFailureIgnored)
+				appliedOverrides.AppliedItems = appliedOverrides.AppliedItems[:failures.skip(p, rawObj, err)]
+				continue
+			}
 			klog.ErrorS(err, "Failed to apply cluster overriders.", "clusteroverridepolicy", p.name, "resource", klog.KObj(rawObj), "operation", operation)
 			return nil, err
 		}
 		metrics.PolicySuccess(p.name, rawObj.GroupVersionKind())
+		o.statusManager.RecordApplied(p.statusKey())
 		klog.V(2).InfoS("Applied cluster overriders.", "clusteroverridepolicy", p.name, "resource", klog.KObj(rawObj), "operation", operation)
-		appliedOverrides.Add(p.name, p.overriders)
+		appliedOverrides.Add(p.name, p.priority, p.overriders, patches, inverse)
 	}
+	appliedOverrides.SkippedPolicies = failures.policies
 
 	return appliedOverrides, nil
 }
@@ -156,7 +316,7 @@ func (o *overrideManagerImpl) applyOverridePolicies(ctx context.Context, rawObj,
 		items = append(items, ops[i])
 	}
 
-	matchingPolicyOverriders := o.getOverridersFromOverridePolicies(items, rawObj, operation)
+	matchingPolicyOverriders := o.getOverridersFromOverridePolicies(ctx, items, rawObj, operation, utils.RequestInfoFromContext(ctx))
 	if len(matchingPolicyOverriders) == 0 {
 		klog.V(2).InfoS("No override policy.", "resource", klog.KObj(rawObj), "operation", operation)
 		return nil, nil
@@ -164,72 +324,165 @@ func (o *overrideManagerImpl) applyOverridePolicies(ctx context.Context, rawObj,
 	klog.V(4).InfoS("matched override polices", "count", len(ops))
 
 	appliedOverriders := &AppliedOverrides{}
+	deadlines := policyDeadlines{}
+	failures := &policyFailures{}
 	for _, p := range matchingPolicyOverriders {
+		if !failures.begin(p, rawObj, len(appliedOverriders.AppliedItems)) {
+			continue
+		}
 		metrics.OverridePolicyMatched(p.namespace+"/"+p.name, rawObj.GroupVersionKind())
-		if err := o.applyPolicyOverriders(ctx, rawObj, oldObj, p); err != nil {
+		o.statusManager.RecordMatched(p.statusKey())
+		policyCtx, cancel := deadlines.context(ctx, p)
+		patches, inverse, err := o.applyPolicyOverriders(policyCtx, rawObj, oldObj, operation, p)
+		cancel()
+		if err != nil {
+			o.statusManager.RecordError(p.statusKey(), err)
+			if utils.IgnoreFailure(p.failurePolicy) {
+				klog.ErrorS(err, "Skipped override policy since failure is ignored.", "overridepolicy", p.policyName(),
+					"ruleIndex", p.ruleIndex, "resource", klog.KObj(rawObj), "operation", operation)
+				metrics.PolicyGotError(p.policyName(), rawObj.GroupVersionKind(), metrics.ErrorTypeFailureIgnored)
+				appliedOverriders.AppliedItems = appliedOverriders.AppliedItems[:failures.skip(p, rawObj, err)]
+				continue
+			}
 			klog.ErrorS(err, "Failed to apply overriders.",
 				"overridepolicy", fmt.Sprintf("%s/%s", p.namespace, p.name), "resource", klog.KObj(rawObj), "operation", operation)
-			return nil, fmt.Errorf("appling policy(%v/%v) err=%v", p.namespace, p.name, err)
+			return nil, fmt.Errorf("appling policy(%v/%v) err=%w", p.namespace, p.name, err)
 		}
 		metrics.PolicySuccess(p.name, rawObj.GroupVersionKind())
+		o.statusManager.RecordApplied(p.statusKey())
 		klog.V(2).InfoS("Applied overriders", "overridepolicy", fmt.Sprintf("%s/%s", p.namespace, p.name), "resource", klog.KObj(rawObj), "operation", operation)
-		appliedOverriders.Add(p.name, p.overriders)
+		appliedOverriders.Add(p.name, p.priority, p.overriders, patches, inverse)
 	}
+	appliedOverriders.SkippedPolicies = failures.policies
 
 	return appliedOverriders, nil
 }
 
-func (o *overrideManagerImpl) getOverridersFromOverridePolicies(policies []GeneralOverridePolicy, resource *unstructured.Unstructured, operation admissionv1.Operation) []policyOverriders {
+// matchContext returns the context used to match resource selectors of policies, objects are got with ctx.
+func (o *overrideManagerImpl) matchContext(ctx context.Context) *utils.MatchContext {
+	return dynamiclister.NewMatchContext(ctx, o.dynamicLister)
+}
+
+// getOverridersFromOverridePolicies returns overriders of rules matching the resource, operation and request,
+// ri with value nil means the request info is unknown.
+func (o *overrideManagerImpl) getOverridersFromOverridePolicies(ctx context.Context, policies []GeneralOverridePolicy, resource *unstructured.Unstructured,
+	operation admissionv1.Operation, ri *utils.RequestInfo) []policyOverriders {
 	resourceMatchingPolicies := make([]GeneralOverridePolicy, 0)
 
+	mc := o.matchContext(ctx)
 	for _, policy := range policies {
-		if len(policy.GetOverridePolicySpec().ResourceSelectors) == 0 {
-			resourceMatchingPolicies = append(resourceMatchingPolicies, policy)
+		spec := policy.GetOverridePolicySpec()
+		if len(spec.ResourceSelectors) > 0 && !utils.ResourceMatchSelectorsWithContext(resource, mc, spec.ResourceSelectors...) {
 			continue
 		}
 
-		if utils.ResourceMatchSelectors(resource, policy.GetOverridePolicySpec().ResourceSelectors...) {
-			resourceMatchingPolicies = append(resourceMatchingPolicies, policy)
+		if len(spec.ExcludeResourceSelectors) > 0 && utils.ResourceMatchSelectorsWithContext(resource, mc, spec.ExcludeResourceSelectors...) {
+			continue
 		}
+
+		resourceMatchingPolicies = append(resourceMatchingPolicies, policy)
 	}
 
 	matchingPolicyOverriders := make([]policyOverriders, 0)
 
 	for _, policy := range resourceMatchingPolicies {
+		ignorable := false
 		for _, rule := range policy.GetOverridePolicySpec().OverrideRules {
-			if len(rule.TargetOperations) == 0 || util.Exists(rule.TargetOperations, operation) {
+			ignorable = ignorable || utils.IgnoreFailure(rule.FailurePolicy)
+		}
+
+		for i, rule := range policy.GetOverridePolicySpec().OverrideRules {
+			if (len(rule.TargetOperations) == 0 || util.Exists(rule.TargetOperations, operation)) && utils.RequestMatches(ri, rule.RequestMatcher) {
 				matchingPolicyOverriders = append(matchingPolicyOverriders, policyOverriders{
-					name:       policy.GetName(),
-					namespace:  policy.GetNamespace(),
-					overriders: rule.Overriders,
+					name:          policy.GetName(),
+					namespace:     policy.GetNamespace(),
+					priority:      policy.GetOverridePolicySpec().Priority,
+					overriders:    rule.Overriders,
+					programKey:    cue.ProgramKey{UID: policy.GetUID(), Generation: policy.GetGeneration()},
+					timeout:       utils.PolicyTimeout(policy.GetOverridePolicySpec().TimeoutSeconds),
+					ruleIndex:     i,
+					failurePolicy: rule.FailurePolicy,
+					ignorable:     ignorable,
 				})
 			}
 		}
 	}
 
-	sort.Slice(matchingPolicyOverriders, func(i, j int) bool {
+	// keep the order of rules in the same policy
+	sort.SliceStable(matchingPolicyOverriders, func(i, j int) bool {
+		if matchingPolicyOverriders[i].priority != matchingPolicyOverriders[j].priority {
+			return matchingPolicyOverriders[i].priority < matchingPolicyOverriders[j].priority
+		}
+
 		return matchingPolicyOverriders[i].name < matchingPolicyOverriders[j].name
 	})
 
 	return matchingPolicyOverriders
 }
 
-// applyPolicyOverriders applies OverridePolicy/ClusterOverridePolicy overriders to target object
-func (o *overrideManagerImpl) applyPolicyOverriders(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, p policyOverriders) error {
+// statusKey returns the key of policy which the overriders belong to.
+func (p policyOverriders) statusKey() statusmanager.PolicyKey {
+	if p.namespace == "" {
+		return statusmanager.PolicyKey{Kind: statusmanager.KindClusterOverridePolicy, Name: p.name}
+	}
+
+	return statusmanager.PolicyKey{Kind: statusmanager.KindOverridePolicy, Namespace: p.namespace, Name: p.name}
+}
+
+// policyName returns the name of cluster override policy or namespace/name of override policy.
+func (p policyOverriders) policyName() string {
+	if p.namespace == "" {
+		return p.name
+	}
+
+	return p.namespace + "/" + p.name
+}
+
+// applyPolicyOverriders applies OverridePolicy/ClusterOverridePolicy overriders to target object,
+// and returns the JSON patch operations applied in order and the inverse operations which revert them.
+func (o *overrideManagerImpl) applyPolicyOverriders(ctx context.Context, rawObj, oldObj *unstructured.Unstructured,
+	operation admissionv1.Operation, p policyOverriders) (
+	applied, inverse []jsonpatchv2.JsonPatchOperation, err error) {
 	defer traceStep(ctx, "applyPolicyOverriders finished")
 	traceStep(ctx, "Start applyPolicyOverriders")
-	policyName := p.name
-	if p.namespace != "" {
-		policyName = p.namespace + "/" + p.name
+	policyName := p.policyName()
+	if p.timeout > 0 {
+		defer func() {
+			var timeoutErr *utils.PolicyTimeoutError
+			if err = utils.NewPolicyTimeoutError(ctx, policyName, p.timeout, err); errors.As(err, &timeoutErr) {
+				metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrorTypeTimeout)
+			}
+		}()
+	}
+
+	apply := func(patches []overrideOption) error {
+		inversePatches, err := applyJSONPatchWithInverse(rawObj, patches)
+		if err != nil {
+			return err
+		}
+
+		for _, patch := range patches {
+			applied = append(applied, jsonpatchv2.NewOperation(patch.Op, patch.Path, patch.Value))
+		}
+		// later patches must be reverted first
+		inverse = append(inversePatches, inverse...)
+		return nil
 	}
+
+	var extraParams map[string]any
 	if p.overriders.Template != nil && p.overriders.RenderedCue != "" {
 		traceStep(ctx, "About to BuildCueParamsViaOverridePolicy")
-		cp, err := cue.BuildCueParamsViaOverridePolicy(o.dynamicLister, rawObj, p.overriders.Template)
+		cp, err := cue.BuildCueParamsViaOverridePolicy(ctx, o.dynamicLister, rawObj, p.overriders.Template)
 		traceStep(ctx, "BuildCueParamsViaOverridePolicy done")
+		if p.overriders.Template.ValueRef != nil {
+			o.statusManager.SetCondition(p.statusKey(), statusmanager.NewCondition(policyv1alpha1.PolicyConditionReferencesResolved,
+				policyv1alpha1.PolicyReasonResolved, policyv1alpha1.PolicyReasonResolveFailed, err))
+		}
 		if err != nil {
 			metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrTypePrepareCueParams)
-			return fmt.Errorf("BuildCueParamsViaOverridePolicy error=%w", err)
+			return nil, nil, fmt.Errorf("BuildCueParamsViaOverridePolicy error=%w", err)
 		}
+		extraParams = cp.ExtraParams
 		cp.Object = rawObj
 		cp.OldObject = oldObj
 		if cp.OldObject == nil {
@@ -243,34 +496,56 @@ func (o *overrideManagerImpl) applyPolicyOverriders(ctx context.Context, rawObj,
 		}
 
 		traceStep(ctx, "About to execute template cue")
-		patches, err := executeCueV2(p.overriders.RenderedCue, params)
+		patches, err := executeCueV2(ctx, p.programKey, p.overriders.RenderedCue, params)
 		traceStep(ctx, "execute template cue done")
 		if err != nil {
 			metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrorTypeCueExecute)
-			return err
+			return nil, nil, err
 		}
 
 		if len(patches) > 0 {
 			metrics.OverridePolicyOverride(policyName, rawObj.GroupVersionKind())
 		}
 
-		if err := applyJSONPatch(rawObj, patches); err != nil {
-			return err
+		if err := apply(patches); err != nil {
+			return nil, nil, err
 		}
 	}
 	if p.overriders.Cue != "" {
 		traceStep(ctx, "About to execute custom cue")
-		patches, err := executeCue(rawObj, p.overriders.Cue)
+		patches, err := executeCue(ctx, p.programKey, rawObj, p.overriders.Cue)
 		traceStep(ctx, "execute custom cue done")
 		if err != nil {
 			metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrorTypeCueExecute)
-			return err
+			return nil, nil, err
 		}
 		if patches != nil && len(*patches) > 0 {
 			metrics.OverridePolicyOverride(policyName, rawObj.GroupVersionKind())
 		}
-		if err := applyJSONPatch(rawObj, *patches); err != nil {
-			return err
+		if err := apply(*patches); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if p.overriders.Cel != "" {
+		traceStep(ctx, "About to execute cel")
+		var patches []overrideOption
+		err := cel.CelDoAndReturn(ctx, p.overriders.Cel, &cel.Parameters{
+			Object:      rawObj,
+			OldObject:   oldObj,
+			ExtraParams: extraParams,
+			Request:     cel.NewRequest(operation, utils.RequestInfoFromContext(ctx)),
+		}, &patches)
+		traceStep(ctx, "execute cel done")
+		if err != nil {
+			metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrorTypeCelExecute)
+			return nil, nil, err
+		}
+		if len(patches) > 0 {
+			metrics.OverridePolicyOverride(policyName, rawObj.GroupVersionKind())
+		}
+		if err := apply(patches); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -279,7 +554,7 @@ func (o *overrideManagerImpl) applyPolicyOverriders(ctx context.Context, rawObj,
 		patches, err := getJSONPatchesByOrigin(rawObj, p.overriders.Origin)
 		if err != nil {
 			metrics.PolicyGotError(policyName, rawObj.GroupVersionKind(), metrics.ErrorTypeOriginExecute)
-			return err
+			return nil, nil, err
 		}
 		var resultPatches []overrideOption
 		for i := range patches {
@@ -291,40 +566,76 @@ func (o *overrideManagerImpl) applyPolicyOverriders(ctx context.Context, rawObj,
 		}
 
 		traceStep(ctx, "get origin jsonPatches done")
-		return applyJSONPatch(rawObj, resultPatches)
+		if err := apply(resultPatches); err != nil {
+			return nil, nil, err
+		}
+
+		return applied, inverse, nil
 	}
 
 	if len(p.overriders.Plaintext) > 0 {
 		metrics.OverridePolicyOverride(policyName, rawObj.GroupVersionKind())
 	}
 
-	return applyJSONPatch(rawObj, parseJSONPatchesByPlaintext(p.overriders.Plaintext))
+	if err := apply(parseJSONPatchesByPlaintext(p.overriders.Plaintext)); err != nil {
+		return nil, nil, err
+	}
+
+	return applied, inverse, nil
 }
 
 // applyJSONPatch applies the override on to the given unstructured object.
 func applyJSONPatch(obj *unstructured.Unstructured, overrides []overrideOption) error {
-	jsonPatchBytes, err := json.Marshal(overrides)
+	doc, err := obj.MarshalJSON()
 	if err != nil {
 		return err
 	}
 
-	patch, err := jsonpatch.DecodePatch(jsonPatchBytes)
+	patchedDoc, err := patchJSON(doc, overrides)
 	if err != nil {
 		return err
 	}
 
-	objectJSONBytes, err := obj.MarshalJSON()
+	return obj.UnmarshalJSON(patchedDoc)
+}
+
+// applyJSONPatchWithInverse applies the override on to the given unstructured object like applyJSONPatch,
+// and returns the inverse patches which revert the override, see inverseOperations.
+func applyJSONPatchWithInverse(obj *unstructured.Unstructured, overrides []overrideOption) ([]jsonpatchv2.JsonPatchOperation, error) {
+	doc, err := obj.MarshalJSON()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	patchedObjectJSONBytes, err := patch.Apply(objectJSONBytes)
+	patchedDoc, err := patchJSON(doc, overrides)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	inverse, err := inverseOperations(doc, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := obj.UnmarshalJSON(patchedDoc); err != nil {
+		return nil, err
+	}
+
+	return inverse, nil
+}
+
+func patchJSON(doc []byte, overrides []overrideOption) ([]byte, error) {
+	jsonPatchBytes, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := jsonpatch.DecodePatch(jsonPatchBytes)
+	if err != nil {
+		return nil, err
 	}
 
-	err = obj.UnmarshalJSON(patchedObjectJSONBytes)
-	return err
+	return patch.Apply(doc)
 }
 
 func parseJSONPatchesByPlaintext(overriders []policyv1alpha1.PlaintextOverrider) []overrideOption {
@@ -379,9 +690,9 @@ func getJSONPatchesByOrigin(rawObj *unstructured.Unstructured, overriders []poli
 	return patches, nil
 }
 
-func executeCueV2(cueStr string, parameters []cue.Parameter) ([]overrideOption, error) {
+func executeCueV2(ctx context.Context, key cue.ProgramKey, cueStr string, parameters []cue.Parameter) ([]overrideOption, error) {
 	result := make([]overrideOption, 0)
-	if err := cue.CueDoAndReturn(cueStr, parameters, utils.OverrideOutputName, &result); err != nil {
+	if err := cueDoAndReturn(ctx, key, cueStr, parameters, &result); err != nil {
 		klog.ErrorS(err, "execute cue error", "cue", cueStr, "params", parameters)
 		if klog.V(4).Enabled() {
 			buf := &bytes.Buffer{}
@@ -424,15 +735,25 @@ func marshalIndent(v any) (*bytes.Buffer, error) {
 	return buf, nil
 }
 
-func executeCue(rawObj *unstructured.Unstructured, template string) (*[]overrideOption, error) {
+func executeCue(ctx context.Context, key cue.ProgramKey, rawObj *unstructured.Unstructured, template string) (*[]overrideOption, error) {
 	result := make([]overrideOption, 0)
-	if err := cue.CueDoAndReturn(template, []cue.Parameter{{Name: utils.ObjectParameterName, Object: rawObj}}, utils.OverrideOutputName, &result); err != nil {
+	if err := cueDoAndReturn(ctx, key, template, []cue.Parameter{{Name: utils.ObjectParameterName, Object: rawObj}}, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// cueDoAndReturn executes the cue template with the program compiled once for the policy generation of key.
+func cueDoAndReturn(ctx context.Context, key cue.ProgramKey, template string, parameters []cue.Parameter, output interface{}) error {
+	p, err := cue.DefaultProgramCache.Program(key, template)
+	if err != nil {
+		return err
+	}
+
+	return p.DoAndReturn(ctx, parameters, utils.OverrideOutputName, output)
+}
+
 func traceStep(ctx context.Context, msg string) {
 	trace := utils.TraceFromContext(ctx)
 	if trace == nil {
@@ -9,7 +9,9 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -112,6 +114,66 @@ func TestGetMatchingOverridePolicies(t *testing.T) {
 			},
 		},
 	}
+	overridePolicy4 := &policyv1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "overridePolicy4",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					TargetOperations: []admissionv1.Operation{admissionv1.Create},
+					Overriders:       overriders2,
+				},
+			},
+			Priority: -1,
+		},
+	}
+	overridePolicy5 := &policyv1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "overridePolicy5",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			ExcludeResourceSelectors: []policyv1alpha1.ResourceSelector{
+				{
+					APIVersion: deployment.APIVersion,
+					Kind:       deployment.Kind,
+					Name:       deployment.Name,
+				},
+			},
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: overriders1,
+				},
+			},
+		},
+	}
+
+	overridePolicy6 := &policyv1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "overridePolicy6",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					RequestMatcher: policyv1alpha1.RequestMatcher{
+						ExcludeUserInfo: &policyv1alpha1.UserInfoMatcher{
+							ServiceAccounts: []string{"flux-system/*"},
+						},
+					},
+					Overriders: overriders1,
+				},
+				{
+					RequestMatcher: policyv1alpha1.RequestMatcher{
+						SubResources: []string{"scale"},
+					},
+					Overriders: overriders2,
+				},
+			},
+		},
+	}
 
 	m := &overrideManagerImpl{}
 	tests := []struct {
@@ -119,6 +181,7 @@ func TestGetMatchingOverridePolicies(t *testing.T) {
 		policies         []GeneralOverridePolicy
 		resource         *unstructured.Unstructured
 		operation        admissionv1.Operation
+		requestInfo      *utils.RequestInfo
 		wantedOverriders []policyOverriders
 	}{
 		{
@@ -154,6 +217,7 @@ func TestGetMatchingOverridePolicies(t *testing.T) {
 					name:       overridePolicy1.Name,
 					namespace:  overridePolicy1.Namespace,
 					overriders: overriders2,
+					ruleIndex:  1,
 				},
 				{
 					name:       overridePolicy2.Name,
@@ -167,10 +231,81 @@ func TestGetMatchingOverridePolicies(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "OverrideRules priority",
+			policies:  []GeneralOverridePolicy{overridePolicy3, overridePolicy2, overridePolicy4},
+			resource:  deploymentObj,
+			operation: admissionv1.Create,
+			wantedOverriders: []policyOverriders{
+				{
+					name:       overridePolicy4.Name,
+					namespace:  overridePolicy4.Namespace,
+					priority:   -1,
+					overriders: overriders2,
+				},
+				{
+					name:       overridePolicy2.Name,
+					namespace:  overridePolicy2.Namespace,
+					overriders: overriders3,
+				},
+				{
+					name:       overridePolicy3.Name,
+					namespace:  overridePolicy3.Namespace,
+					overriders: overriders3,
+				},
+			},
+		},
+		{
+			name:      "OverrideRules exclude",
+			policies:  []GeneralOverridePolicy{overridePolicy2, overridePolicy5},
+			resource:  deploymentObj,
+			operation: admissionv1.Create,
+			wantedOverriders: []policyOverriders{
+				{
+					name:       overridePolicy2.Name,
+					namespace:  overridePolicy2.Namespace,
+					overriders: overriders3,
+				},
+			},
+		},
+		{
+			name:      "OverrideRules request matcher",
+			policies:  []GeneralOverridePolicy{overridePolicy6},
+			resource:  deploymentObj,
+			operation: admissionv1.Update,
+			requestInfo: &utils.RequestInfo{
+				UserInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:default:deployer"},
+			},
+			wantedOverriders: []policyOverriders{
+				{
+					name:       overridePolicy6.Name,
+					namespace:  overridePolicy6.Namespace,
+					overriders: overriders1,
+				},
+			},
+		},
+		{
+			name:      "OverrideRules request matcher excluded",
+			policies:  []GeneralOverridePolicy{overridePolicy6},
+			resource:  deploymentObj,
+			operation: admissionv1.Update,
+			requestInfo: &utils.RequestInfo{
+				UserInfo:    authenticationv1.UserInfo{Username: "system:serviceaccount:flux-system:kustomize-controller"},
+				SubResource: "scale",
+			},
+			wantedOverriders: []policyOverriders{
+				{
+					name:       overridePolicy6.Name,
+					namespace:  overridePolicy6.Namespace,
+					overriders: overriders2,
+					ruleIndex:  1,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := m.getOverridersFromOverridePolicies(tt.policies, tt.resource, tt.operation); !reflect.DeepEqual(got, tt.wantedOverriders) {
+			if got := m.getOverridersFromOverridePolicies(context.Background(), tt.policies, tt.resource, tt.operation, tt.requestInfo); !reflect.DeepEqual(got, tt.wantedOverriders) {
 				t.Errorf("getOverridersFromOverridePolicies() = %v, want %v", got, tt.wantedOverriders)
 			}
 		})
@@ -333,7 +468,7 @@ patches: [
 
 	opLister := mock.NewMockOverridePolicyLister(ctrl)
 	copLister := mock.NewMockClusterOverridePolicyLister(ctrl)
-	m := NewOverrideManager(nil, copLister, opLister)
+	m := NewOverrideManager(nil, copLister, opLister, OverrideManagerOptions{})
 
 	opLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.OverridePolicy{
 		overridePolicy1,
@@ -368,15 +503,35 @@ patches: [
 				AppliedItems: []OverridePolicyShadow{
 					{
 						PolicyName: overridePolicy1.Name,
-						Overriders: overriders1,
+						Order:      1,
+						Patches: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("add", "/metadata/annotations", overriders1.Plaintext[0].Value),
+						},
+						Inverse: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("replace", "/metadata/annotations", map[string]interface{}{"hello": "world"}),
+						},
 					},
 					{
 						PolicyName: overridePolicy2.Name,
-						Overriders: overriders2,
+						Order:      2,
+						Patches: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("add", "/metadata/annotations/cue", "cue"),
+							jsonpatchv2.NewOperation("add", "/metadata/annotations/aaa", overriders2.Plaintext[0].Value),
+						},
+						Inverse: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("remove", "/metadata/annotations/aaa", nil),
+							jsonpatchv2.NewOperation("remove", "/metadata/annotations/cue", nil),
+						},
 					},
 					{
 						PolicyName: overridePolicy4.Name,
-						Overriders: overriders4,
+						Order:      3,
+						Patches: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("replace", "/metadata/annotations/owned-by", "template-cue"),
+						},
+						Inverse: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("remove", "/metadata/annotations/owned-by", nil),
+						},
 					},
 				},
 			},
@@ -384,7 +539,13 @@ patches: [
 				AppliedItems: []OverridePolicyShadow{
 					{
 						PolicyName: overridePolicy3.Name,
-						Overriders: overriders3,
+						Order:      1,
+						Patches: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("add", "/metadata/annotations", overriders3.Plaintext[0].Value),
+						},
+						Inverse: []jsonpatchv2.JsonPatchOperation{
+							jsonpatchv2.NewOperation("remove", "/metadata/annotations", nil),
+						},
 					},
 				},
 			},
@@ -406,6 +567,182 @@ patches: [
 	}
 }
 
+func TestOverrideManagerImpl_ApplyOverridePolicies_OptOut(t *testing.T) {
+	cop := &policyv1alpha1.ClusterOverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cop",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: policyv1alpha1.Overriders{
+						Plaintext: []policyv1alpha1.PlaintextOverrider{
+							{
+								Path:     "/metadata/labels",
+								Operator: policyv1alpha1.OverriderOpAdd,
+								Value:    apiextensionsv1.JSON{Raw: []byte(`{"foo":"bar"}`)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	copLister := mock.NewMockClusterOverridePolicyLister(ctrl)
+	copLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterOverridePolicy{cop}, nil).AnyTimes()
+	opLister := mock.NewMockOverridePolicyLister(ctrl)
+	opLister.EXPECT().List(labels.Everything()).Return(nil, nil).AnyTimes()
+	m := NewOverrideManager(nil, copLister, opLister, OverrideManagerOptions{
+		OptOut: &utils.OptOutAllowList{Users: []string{"admin"}},
+	})
+
+	tests := []struct {
+		name        string
+		username    string
+		wantApplied bool
+	}{
+		{
+			name:     "opted out by allowed user",
+			username: "admin",
+		},
+		{
+			name:        "annotation ignored for user not allowed",
+			username:    "developer",
+			wantApplied: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+			deployment.Annotations = map[string]string{utils.SkipPolicies: utils.SkipPoliciesValue}
+			obj, _ := utilhelper.ToUnstructured(deployment)
+
+			ctx := utils.ContextWithRequestInfo(context.Background(), &utils.RequestInfo{UserInfo: authenticationv1.UserInfo{Username: tt.username}})
+			cops, _, err := m.ApplyOverridePolicies(ctx, obj, nil, admissionv1.Create)
+			if err != nil {
+				t.Fatalf("ApplyOverridePolicies() err=%v", err)
+			}
+			if applied := cops != nil && obj.GetLabels()["foo"] == "bar"; applied != tt.wantApplied {
+				t.Errorf("applied=%v, want %v", applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestOverrideManagerImpl_ApplyOverridePolicies_FailurePolicy(t *testing.T) {
+	newPolicies := func(failurePolicy policyv1alpha1.FailurePolicyType) []*policyv1alpha1.ClusterOverridePolicy {
+		return []*policyv1alpha1.ClusterOverridePolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "optional",
+				},
+				Spec: policyv1alpha1.OverridePolicySpec{
+					OverrideRules: []policyv1alpha1.RuleWithOperation{
+						{
+							Overriders: policyv1alpha1.Overriders{
+								Plaintext: []policyv1alpha1.PlaintextOverrider{
+									{
+										Path:     "/metadata/annotations",
+										Operator: policyv1alpha1.OverriderOpAdd,
+										Value:    apiextensionsv1.JSON{Raw: []byte(`{"optional":"true"}`)},
+									},
+								},
+							},
+						},
+						{
+							Overriders: policyv1alpha1.Overriders{
+								Plaintext: []policyv1alpha1.PlaintextOverrider{
+									{
+										Path:     "/spec/notExist/field",
+										Operator: policyv1alpha1.OverriderOpReplace,
+										Value:    apiextensionsv1.JSON{Raw: []byte(`"value"`)},
+									},
+								},
+							},
+							FailurePolicy: failurePolicy,
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "required",
+				},
+				Spec: policyv1alpha1.OverridePolicySpec{
+					OverrideRules: []policyv1alpha1.RuleWithOperation{
+						{
+							Overriders: policyv1alpha1.Overriders{
+								Plaintext: []policyv1alpha1.PlaintextOverrider{
+									{
+										Path:     "/metadata/labels",
+										Operator: policyv1alpha1.OverriderOpAdd,
+										Value:    apiextensionsv1.JSON{Raw: []byte(`{"foo":"bar"}`)},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		failurePolicy policyv1alpha1.FailurePolicyType
+		wantErr       bool
+	}{
+		{
+			name:    "fail by default",
+			wantErr: true,
+		},
+		{
+			name:          "fail",
+			failurePolicy: policyv1alpha1.FailurePolicyFail,
+			wantErr:       true,
+		},
+		{
+			name:          "ignore",
+			failurePolicy: policyv1alpha1.FailurePolicyIgnore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			copLister := mock.NewMockClusterOverridePolicyLister(ctrl)
+			copLister.EXPECT().List(labels.Everything()).Return(newPolicies(tt.failurePolicy), nil).AnyTimes()
+			opLister := mock.NewMockOverridePolicyLister(ctrl)
+			opLister.EXPECT().List(labels.Everything()).Return(nil, nil).AnyTimes()
+			m := NewOverrideManager(nil, copLister, opLister, OverrideManagerOptions{})
+
+			obj, _ := utilhelper.ToUnstructured(helper.NewDeployment(metav1.NamespaceDefault, "test"))
+			cops, _, err := m.ApplyOverridePolicies(context.Background(), obj, nil, admissionv1.Create)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyOverridePolicies() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(cops.AppliedItems) != 1 || cops.AppliedItems[0].PolicyName != "required" || obj.GetLabels()["foo"] != "bar" {
+				t.Errorf("ApplyOverridePolicies() should apply the required policy, applied=%v", cops.AppliedItems)
+			}
+			if _, ok := obj.GetAnnotations()["optional"]; ok {
+				t.Errorf("ApplyOverridePolicies() should revert overrides of the skipped policy, annotations=%v", obj.GetAnnotations())
+			}
+			if len(cops.SkippedPolicies) != 1 || cops.SkippedPolicies[0].PolicyName != "optional" || cops.SkippedPolicies[0].RuleIndex != 1 {
+				t.Errorf("ApplyOverridePolicies() skipped=%v, want the optional policy", cops.SkippedPolicies)
+			}
+		})
+	}
+}
+
 func Test_executeCueV2(t *testing.T) {
 	fs := flag.NewFlagSet("test", flag.ExitOnError)
 	klog.InitFlags(fs)
@@ -486,7 +823,7 @@ patches: [
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := executeCueV2(tt.args.cueStr, tt.args.parameters)
+			got, err := executeCueV2(context.Background(), cue.ProgramKey{}, tt.args.cueStr, tt.args.parameters)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("executeCueV2() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,332 @@
+package overridemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/k-cloud-labs/pkg/utils"
+)
+
+// ParseAppliedOverrides parses the value of applied overrides annotation.
+// It returns an error if the value is encoded in compact mode, since compact items can not be reverted.
+func ParseAppliedOverrides(value string) (*AppliedOverrides, error) {
+	var items []struct {
+		OverridePolicyShadow
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, err
+	}
+
+	ao := &AppliedOverrides{}
+	for _, item := range items {
+		if item.Hash != "" {
+			return nil, fmt.Errorf("applied overrides of policy(%s) is in compact mode", item.PolicyName)
+		}
+
+		ao.AppliedItems = append(ao.AppliedItems, item.OverridePolicyShadow)
+	}
+
+	return ao, nil
+}
+
+// InversePatches returns the JSON patches which revert all applied items, items are reverted in descending order.
+func (ao *AppliedOverrides) InversePatches() ([]jsonpatchv2.JsonPatchOperation, error) {
+	ao.AscendOrder()
+	var inverse []jsonpatchv2.JsonPatchOperation
+	for i := len(ao.AppliedItems) - 1; i >= 0; i-- {
+		item := ao.AppliedItems[i]
+		if len(item.Patches) > 0 && len(item.Inverse) == 0 {
+			return nil, fmt.Errorf("no inverse patches recorded for policy(%s)", item.PolicyName)
+		}
+
+		inverse = append(inverse, item.Inverse...)
+	}
+
+	return inverse, nil
+}
+
+// RevertOverrides reverts the mutations recorded in applied overrides annotations of obj, then removes the annotations.
+// OverridePolicy items are reverted before ClusterOverridePolicy items since they are applied later.
+// Fields changed by others after the object was overridden may be overwritten by the recorded prior values.
+func RevertOverrides(obj *unstructured.Unstructured) error {
+	annotations := obj.GetAnnotations()
+	var inverse []jsonpatchv2.JsonPatchOperation
+	for _, key := range []string{utils.AppliedOverrides, utils.AppliedClusterOverrides} {
+		value, ok := annotations[key]
+		if !ok || value == "" {
+			continue
+		}
+
+		ao, err := ParseAppliedOverrides(value)
+		if err != nil {
+			return fmt.Errorf("parse annotation(%s) err=%w", key, err)
+		}
+
+		patches, err := ao.InversePatches()
+		if err != nil {
+			return err
+		}
+
+		inverse = append(inverse, patches...)
+	}
+
+	if len(inverse) > 0 {
+		overrides := make([]overrideOption, 0, len(inverse))
+		for _, patch := range inverse {
+			overrides = append(overrides, overrideOption{
+				Op:    patch.Operation,
+				Path:  patch.Path,
+				Value: patch.Value,
+			})
+		}
+
+		if err := applyJSONPatch(obj, overrides); err != nil {
+			return fmt.Errorf("revert overrides err=%w", err)
+		}
+	}
+
+	annotations = obj.GetAnnotations()
+	if annotations == nil {
+		return nil
+	}
+
+	delete(annotations, utils.AppliedOverrides)
+	delete(annotations, utils.AppliedClusterOverrides)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// inverseOperations returns the operations which revert overrides applied in order to doc, later overrides are reverted first.
+// doc is decoded once, and overrides are replayed on it to look up prior values of each override.
+func inverseOperations(doc []byte, overrides []overrideOption) ([]jsonpatchv2.JsonPatchOperation, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	inverse := make([]jsonpatchv2.JsonPatchOperation, 0, len(overrides))
+	for _, override := range overrides {
+		ops, err := inverseOperation(root, override)
+		if err != nil {
+			return nil, err
+		}
+
+		inverse = append(ops, inverse...)
+		if root, err = replayOperation(root, override); err != nil {
+			return nil, err
+		}
+	}
+
+	return inverse, nil
+}
+
+// inverseOperation returns the operations which revert override applied to root.
+// The prior values are looked up from root, which is the decoded object before override applied.
+// Prior values are always detached from root by override, so they are not changed by later overrides.
+func inverseOperation(root interface{}, override overrideOption) ([]jsonpatchv2.JsonPatchOperation, error) {
+	parent, key, err := resolveParent(root, override.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch override.Op {
+	case "add":
+		switch p := parent.(type) {
+		case []interface{}:
+			// add to array inserts a new element
+			if key == "-" {
+				return []jsonpatchv2.JsonPatchOperation{
+					jsonpatchv2.NewOperation("remove", replaceLastToken(override.Path, strconv.Itoa(len(p))), nil),
+				}, nil
+			}
+
+			return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("remove", override.Path, nil)}, nil
+		case map[string]interface{}:
+			if prior, ok := p[key]; ok {
+				return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("replace", override.Path, prior)}, nil
+			}
+
+			return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("remove", override.Path, nil)}, nil
+		}
+	case "remove":
+		prior, err := lookupChild(parent, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("add", override.Path, prior)}, nil
+	case "replace":
+		// replace of missing key is treated as add
+		if p, ok := parent.(map[string]interface{}); ok {
+			if _, exist := p[key]; !exist {
+				return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("remove", override.Path, nil)}, nil
+			}
+		}
+
+		prior, err := lookupChild(parent, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return []jsonpatchv2.JsonPatchOperation{jsonpatchv2.NewOperation("replace", override.Path, prior)}, nil
+	case "test":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("can not inverse operation(%s) of path(%s)", override.Op, override.Path)
+}
+
+// resolveParent returns the container of the value that JSON pointer path refers to, and the last unescaped token.
+func resolveParent(root interface{}, path string) (interface{}, string, error) {
+	tokens, err := splitPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	current := root
+	for _, token := range tokens[:len(tokens)-1] {
+		child, err := lookupChild(current, token)
+		if err != nil {
+			return nil, "", fmt.Errorf("path(%s) err=%w", path, err)
+		}
+
+		current = child
+	}
+
+	return current, tokens[len(tokens)-1], nil
+}
+
+// splitPath returns the unescaped tokens of JSON pointer path.
+func splitPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, fmt.Errorf("can not inverse operation on the whole document")
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// replayOperation applies override to the decoded document root in place, and returns the new root.
+func replayOperation(root interface{}, override overrideOption) (interface{}, error) {
+	if override.Op == "test" {
+		return root, nil
+	}
+
+	tokens, err := splitPath(override.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if override.Op != "remove" {
+		// decode the value as the document, so later overrides can look up values in it
+		b, err := json.Marshal(override.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	return replayOnNode(root, tokens, override.Op, value)
+}
+
+// replayOnNode applies op with value to the path of tokens in node, and returns the new node,
+// since the length of arrays are changed by add and remove.
+func replayOnNode(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	key := tokens[0]
+	if len(tokens) > 1 {
+		child, err := lookupChild(node, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if child, err = replayOnNode(child, tokens[1:], op, value); err != nil {
+			return nil, err
+		}
+
+		if p, ok := node.([]interface{}); ok {
+			index, _ := strconv.Atoi(key)
+			p[index] = child
+			return p, nil
+		}
+
+		node.(map[string]interface{})[key] = child
+		return node, nil
+	}
+
+	switch p := node.(type) {
+	case map[string]interface{}:
+		if op == "remove" {
+			delete(p, key)
+		} else {
+			p[key] = value
+		}
+
+		return p, nil
+	case []interface{}:
+		index := len(p)
+		if key != "-" {
+			var err error
+			if index, err = strconv.Atoi(key); err != nil || index < 0 || index > len(p) {
+				return nil, fmt.Errorf("index(%s) out of range", key)
+			}
+		}
+
+		switch {
+		case op == "add":
+			p = append(p, nil)
+			copy(p[index+1:], p[index:])
+			p[index] = value
+			return p, nil
+		case index >= len(p):
+			return nil, fmt.Errorf("index(%s) out of range", key)
+		case op == "remove":
+			return append(p[:index], p[index+1:]...), nil
+		case op == "replace":
+			p[index] = value
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("can not replay operation(%s) on key(%s)", op, key)
+}
+
+func lookupChild(parent interface{}, key string) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		child, ok := p[key]
+		if !ok {
+			return nil, fmt.Errorf("key(%s) not found", key)
+		}
+
+		return child, nil
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(p) {
+			return nil, fmt.Errorf("index(%s) out of range", key)
+		}
+
+		return p[index], nil
+	}
+
+	return nil, fmt.Errorf("can not get key(%s) of non-container value", key)
+}
+
+func replaceLastToken(path, token string) string {
+	return path[:strings.LastIndex(path, "/")+1] + token
+}
@@ -0,0 +1,144 @@
+package overridemanager
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/utils"
+	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
+)
+
+func TestRevertOverrides(t *testing.T) {
+	deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+	deployment.Labels = map[string]string{"app": "test"}
+	deployment.Spec.Template.Spec.Containers[0].Args = []string{"a", "b"}
+	original, _ := utilhelper.ToUnstructured(deployment)
+	obj := original.DeepCopy()
+
+	copPatches := []overrideOption{
+		{Op: "add", Path: "/metadata/annotations", Value: map[string]interface{}{"foo": "bar"}},
+		{Op: "add", Path: "/metadata/labels/app", Value: "override"},
+		{Op: "replace", Path: "/metadata/labels/version", Value: "v1"},
+	}
+	opPatches := []overrideOption{
+		{Op: "add", Path: "/spec/template/spec/containers/0/args/-", Value: "c"},
+		{Op: "add", Path: "/spec/template/spec/containers/0/args/0", Value: "z"},
+		{Op: "remove", Path: "/metadata/labels/app"},
+		{Op: "replace", Path: "/spec/replicas", Value: 10},
+	}
+
+	copInverse, err := applyJSONPatchWithInverse(obj, copPatches)
+	if err != nil {
+		t.Fatalf("applyJSONPatchWithInverse() err=%v", err)
+	}
+	opInverse, err := applyJSONPatchWithInverse(obj, opPatches)
+	if err != nil {
+		t.Fatalf("applyJSONPatchWithInverse() err=%v", err)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if args := containers[0].(map[string]interface{})["args"]; !reflect.DeepEqual(args, []interface{}{"z", "a", "b", "c"}) {
+		t.Fatalf("unexpected args after override: %v", args)
+	}
+
+	appliedCOPs := &AppliedOverrides{}
+	appliedCOPs.Add("cop", 0, policyv1alpha1.Overriders{}, nil, copInverse)
+	appliedOPs := &AppliedOverrides{}
+	appliedOPs.Add("op", 0, policyv1alpha1.Overriders{}, nil, opInverse)
+
+	copBytes, _ := appliedCOPs.MarshalJSON()
+	opBytes, _ := appliedOPs.MarshalJSON()
+	annotations := obj.GetAnnotations()
+	annotations[utils.AppliedClusterOverrides] = string(copBytes)
+	annotations[utils.AppliedOverrides] = string(opBytes)
+	obj.SetAnnotations(annotations)
+
+	if err := RevertOverrides(obj); err != nil {
+		t.Fatalf("RevertOverrides() err=%v", err)
+	}
+
+	if !reflect.DeepEqual(obj.Object, original.Object) {
+		t.Errorf("RevertOverrides() got %v, want %v", obj.Object, original.Object)
+	}
+}
+
+func TestParseAppliedOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{
+			name:  "full",
+			value: `[{"policyName":"aaa","order":1,"overriders":{},"patches":[{"op":"add","path":"/a","value":1}],"inverse":[{"op":"remove","path":"/a"}]}]`,
+		},
+		{
+			name:    "compact",
+			value:   `[{"policyName":"aaa","order":1,"hash":"abc","paths":["/a"]}]`,
+			wantErr: "compact mode",
+		},
+		{
+			name:    "no inverse",
+			value:   `[{"policyName":"aaa","order":1,"overriders":{},"patches":[{"op":"add","path":"/a","value":1}]}]`,
+			wantErr: "no inverse patches",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ao, err := ParseAppliedOverrides(tt.value)
+			if err == nil {
+				_, err = ao.InversePatches()
+			}
+
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected err=%v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("err=%v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_inverseOperations(t *testing.T) {
+	original := []byte(`{"a":{"list":[1,2,3]},"b":"x"}`)
+	overrides := []overrideOption{
+		{Op: "add", Path: "/c", Value: map[string]interface{}{"list": []string{"p"}}},
+		{Op: "add", Path: "/c/list/0", Value: "q"},
+		{Op: "replace", Path: "/c/list/1", Value: "r"},
+		{Op: "remove", Path: "/a/list/1"},
+		{Op: "add", Path: "/a/list/-", Value: 4},
+		{Op: "replace", Path: "/b", Value: "y"},
+		{Op: "test", Path: "/b", Value: "y"},
+	}
+
+	patched, err := patchJSON(original, overrides)
+	if err != nil {
+		t.Fatalf("patchJSON() err=%v", err)
+	}
+
+	inverse, err := inverseOperations(original, overrides)
+	if err != nil {
+		t.Fatalf("inverseOperations() err=%v", err)
+	}
+
+	reverts := make([]overrideOption, 0, len(inverse))
+	for _, op := range inverse {
+		reverts = append(reverts, overrideOption{Op: op.Operation, Path: op.Path, Value: op.Value})
+	}
+	reverted, err := patchJSON(patched, reverts)
+	if err != nil {
+		t.Fatalf("patchJSON() inverse=%v err=%v", inverse, err)
+	}
+
+	if !jsonpatch.Equal(reverted, original) {
+		t.Errorf("reverted %s, want %s", reverted, original)
+	}
+}
@@ -1,10 +1,15 @@
 package overridemanager
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"sort"
 
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils"
 )
 
 // OverridePolicyShadow is the condensed version of a OverridePolicy.
@@ -12,25 +17,74 @@ type OverridePolicyShadow struct {
 	// PolicyName is the name of the referencing policy.
 	PolicyName string `json:"policyName"`
 
+	// Priority is the priority of the referencing policy.
+	Priority int32 `json:"priority,omitempty"`
+
+	// Order is the effective order in which the overriders were applied, starting from 1.
+	Order int `json:"order"`
+
 	// Overriders is the overrider list of the referencing policy.
-	Overriders policyv1alpha1.Overriders `json:"overriders"`
+	// It's only recorded when the overriders applied no patches, otherwise Patches records what changed.
+	Overriders *policyv1alpha1.Overriders `json:"overriders,omitempty"`
+
+	// Patches is the JSON patch operations the overriders applied to the object, in the order they are applied.
+	Patches []jsonpatchv2.JsonPatchOperation `json:"patches,omitempty"`
+
+	// Inverse is the JSON patch operations which revert Patches, computed from prior values of the object.
+	// They should be applied in the recorded order.
+	Inverse []jsonpatchv2.JsonPatchOperation `json:"inverse,omitempty"`
+}
+
+// CompactOverridePolicyShadow is the compact version of OverridePolicyShadow,
+// it only records a hash of the applied patches and the patched paths to keep the size bounded.
+type CompactOverridePolicyShadow struct {
+	// PolicyName is the name of the referencing policy.
+	PolicyName string `json:"policyName"`
+
+	// Priority is the priority of the referencing policy.
+	Priority int32 `json:"priority,omitempty"`
+
+	// Order is the effective order in which the overriders were applied, starting from 1.
+	Order int `json:"order"`
+
+	// Hash is the hex encoded sha256 of the JSON encoding of applied patches.
+	Hash string `json:"hash"`
+
+	// Paths is the deduplicated list of paths of applied patches.
+	Paths []string `json:"paths,omitempty"`
 }
 
 // AppliedOverrides is the list of applied overriders.
 type AppliedOverrides struct {
 	// AppliedItems is the list of applied overriders.
 	AppliedItems []OverridePolicyShadow `json:"appliedItems,omitempty"`
+	// SkippedPolicies is the list of policies skipped since their rules failed with failurePolicy Ignore,
+	// it's not recorded in the applied overrides annotation.
+	SkippedPolicies []utils.SkippedPolicy `json:"-"`
 }
 
-// Add appends an item to AppliedItems.
-func (ao *AppliedOverrides) Add(policyName string, overriders policyv1alpha1.Overriders) {
-	ao.AppliedItems = append(ao.AppliedItems, OverridePolicyShadow{PolicyName: policyName, Overriders: overriders})
+// Add appends an item to AppliedItems, items should be added in the order they are applied.
+// overriders are only recorded if there are no patches, to keep the size of annotation small.
+func (ao *AppliedOverrides) Add(policyName string, priority int32, overriders policyv1alpha1.Overriders,
+	patches, inverse []jsonpatchv2.JsonPatchOperation) {
+	item := OverridePolicyShadow{
+		PolicyName: policyName,
+		Priority:   priority,
+		Order:      len(ao.AppliedItems) + 1,
+		Patches:    patches,
+		Inverse:    inverse,
+	}
+	if len(patches) == 0 {
+		item.Overriders = &overriders
+	}
+
+	ao.AppliedItems = append(ao.AppliedItems, item)
 }
 
-// AscendOrder sort the applied items in ascending order.
+// AscendOrder sort the applied items in ascending order of the effective order.
 func (ao *AppliedOverrides) AscendOrder() {
-	sort.Slice(ao.AppliedItems, func(i, j int) bool {
-		return ao.AppliedItems[i].PolicyName < ao.AppliedItems[j].PolicyName
+	sort.SliceStable(ao.AppliedItems, func(i, j int) bool {
+		return ao.AppliedItems[i].Order < ao.AppliedItems[j].Order
 	})
 }
 
@@ -43,3 +97,53 @@ func (ao *AppliedOverrides) MarshalJSON() ([]byte, error) {
 	ao.AscendOrder()
 	return json.Marshal(ao.AppliedItems)
 }
+
+// MarshalCompactJSON returns the JSON encoding of applied overrides in compact mode,
+// each item is encoded as CompactOverridePolicyShadow without overriders and patch values.
+// Objects with compact applied overrides can not be reverted.
+func (ao *AppliedOverrides) MarshalCompactJSON() ([]byte, error) {
+	if len(ao.AppliedItems) == 0 {
+		return nil, nil
+	}
+
+	ao.AscendOrder()
+	items := make([]CompactOverridePolicyShadow, 0, len(ao.AppliedItems))
+	for _, item := range ao.AppliedItems {
+		compact, err := item.Compact()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, compact)
+	}
+
+	return json.Marshal(items)
+}
+
+// Compact converts the shadow to CompactOverridePolicyShadow.
+func (s OverridePolicyShadow) Compact() (CompactOverridePolicyShadow, error) {
+	patchesBytes, err := json.Marshal(s.Patches)
+	if err != nil {
+		return CompactOverridePolicyShadow{}, err
+	}
+
+	sum := sha256.Sum256(patchesBytes)
+	compact := CompactOverridePolicyShadow{
+		PolicyName: s.PolicyName,
+		Priority:   s.Priority,
+		Order:      s.Order,
+		Hash:       hex.EncodeToString(sum[:]),
+	}
+
+	seen := make(map[string]bool, len(s.Patches))
+	for _, patch := range s.Patches {
+		if seen[patch.Path] {
+			continue
+		}
+
+		seen[patch.Path] = true
+		compact.Paths = append(compact.Paths, patch.Path)
+	}
+
+	return compact, nil
+}
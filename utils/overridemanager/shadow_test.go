@@ -2,25 +2,63 @@ package overridemanager
 
 import (
 	"testing"
+
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 )
 
 func TestAppliedOverrides_AscendOrder(t *testing.T) {
 	applied := AppliedOverrides{}
-	item2 := OverridePolicyShadow{PolicyName: "bbb"}
+	item2 := OverridePolicyShadow{PolicyName: "bbb", Priority: -1}
 	item1 := OverridePolicyShadow{PolicyName: "aaa"}
 	item3 := OverridePolicyShadow{PolicyName: "ccc"}
 
-	applied.Add(item1.PolicyName, item1.Overriders)
-	applied.Add(item2.PolicyName, item2.Overriders)
-	applied.Add(item3.PolicyName, item3.Overriders)
+	applied.Add(item2.PolicyName, item2.Priority, policyv1alpha1.Overriders{}, item2.Patches, item2.Inverse)
+	applied.Add(item1.PolicyName, item1.Priority, policyv1alpha1.Overriders{}, item1.Patches, item1.Inverse)
+	applied.Add(item3.PolicyName, item3.Priority, policyv1alpha1.Overriders{}, item3.Patches, item3.Inverse)
 
 	appliedBytes, err := applied.MarshalJSON()
 	if err != nil {
 		t.Fatalf("not expect error, but got: %v", err)
 	}
 
-	expectJSON := `[{"policyName":"aaa","overriders":{}},{"policyName":"bbb","overriders":{}},{"policyName":"ccc","overriders":{}}]`
+	expectJSON := `[{"policyName":"bbb","priority":-1,"order":1,"overriders":{}},{"policyName":"aaa","order":2,"overriders":{}},{"policyName":"ccc","order":3,"overriders":{}}]`
 	if string(appliedBytes) != expectJSON {
 		t.Fatalf("expect %s, but got: %s", expectJSON, string(appliedBytes))
 	}
 }
+
+func TestAppliedOverrides_MarshalCompactJSON(t *testing.T) {
+	applied := AppliedOverrides{}
+	patches := []jsonpatchv2.JsonPatchOperation{
+		jsonpatchv2.NewOperation("add", "/metadata/annotations", map[string]interface{}{}),
+		jsonpatchv2.NewOperation("add", "/metadata/annotations/foo", "bar"),
+		jsonpatchv2.NewOperation("replace", "/metadata/annotations/foo", "baz"),
+	}
+	applied.Add("aaa", 0, policyv1alpha1.Overriders{}, patches, nil)
+	applied.Add("bbb", 0, policyv1alpha1.Overriders{}, nil, nil)
+
+	fullBytes, err := applied.MarshalJSON()
+	if err != nil {
+		t.Fatalf("not expect error, but got: %v", err)
+	}
+
+	expectFullJSON := `[{"policyName":"aaa","order":1,"patches":[{"op":"add","path":"/metadata/annotations","value":{}},` +
+		`{"op":"add","path":"/metadata/annotations/foo","value":"bar"},{"op":"replace","path":"/metadata/annotations/foo","value":"baz"}]},` +
+		`{"policyName":"bbb","order":2,"overriders":{}}]`
+	if string(fullBytes) != expectFullJSON {
+		t.Fatalf("expect %s, but got: %s", expectFullJSON, string(fullBytes))
+	}
+
+	compactBytes, err := applied.MarshalCompactJSON()
+	if err != nil {
+		t.Fatalf("not expect error, but got: %v", err)
+	}
+
+	expectCompactJSON := `[{"policyName":"aaa","order":1,"hash":"825a50e990360647eadad7d8a955f3ca34f10e5fc0088f0cec64a1412539661c","paths":["/metadata/annotations","/metadata/annotations/foo"]},` +
+		`{"policyName":"bbb","order":2,"hash":"74234e98afe7498fb5daf1f36ac2d78acc339464f950703b8c019892f982b90b"}]`
+	if string(compactBytes) != expectCompactJSON {
+		t.Fatalf("expect %s, but got: %s", expectCompactJSON, string(compactBytes))
+	}
+}
@@ -0,0 +1,153 @@
+package overridemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
+)
+
+// SimulateResult is the result of simulating override policies against an object.
+type SimulateResult struct {
+	// Object is the final object after all matched overriders applied.
+	Object *unstructured.Unstructured
+	// PolicyPatches is the list of JSON patches each matched overrider made, in the order they are applied.
+	PolicyPatches []PolicyPatches
+	// Patches is the JSON patches from the original object to the final object.
+	Patches []jsonpatchv2.JsonPatchOperation
+	// Diff is the unified diff between the original object and the final object in indented JSON.
+	Diff string
+	// SkippedPolicies is the list of policies skipped since their rules failed with failurePolicy Ignore.
+	SkippedPolicies []utils.SkippedPolicy
+}
+
+// PolicyPatches is the JSON patches made by an overrider of a policy.
+type PolicyPatches struct {
+	// PolicyName is the name of the policy.
+	PolicyName string
+	// PolicyNamespace is the namespace of the policy, empty for ClusterOverridePolicy.
+	PolicyNamespace string
+	// Patches is the JSON patches made by the overrider.
+	Patches []jsonpatchv2.JsonPatchOperation
+}
+
+// Simulate runs the same matching and patching pipeline as ApplyOverridePolicies against an in-memory policy set,
+// policies without namespace are treated as ClusterOverridePolicy and others are treated as OverridePolicy.
+// The given obj is not changed.
+// Templates referring to other k8s objects or owners are resolved with dynamicClient, e.g. dynamiclister/fake.
+// If dynamicClient is nil, they fail with cue.ErrNoLister.
+func Simulate(dynamicClient dynamiclister.DynamicResourceLister, policies []GeneralOverridePolicy, obj, oldObj *unstructured.Unstructured,
+	operation admissionv1.Operation) (*SimulateResult, error) {
+	o := &overrideManagerImpl{
+		dynamicLister: dynamicClient,
+		statusManager: statusmanager.NewNopStatusManager(),
+	}
+
+	var cops, ops []GeneralOverridePolicy
+	for _, policy := range policies {
+		if policy.GetNamespace() == "" {
+			cops = append(cops, policy)
+			continue
+		}
+
+		ops = append(ops, policy)
+	}
+
+	result := &SimulateResult{
+		Object: obj.DeepCopy(),
+	}
+	ctx := context.Background()
+	if err := o.simulatePolicies(ctx, cops, result, oldObj, operation); err != nil {
+		return nil, err
+	}
+
+	if obj.GetNamespace() != "" {
+		if err := o.simulatePolicies(ctx, ops, result, oldObj, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	final, err := result.Object.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	result.Patches, err = jsonpatchv2.CreatePatch(original, final)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Diff, err = diffObjects(obj, result.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (o *overrideManagerImpl) simulatePolicies(ctx context.Context, policies []GeneralOverridePolicy, result *SimulateResult,
+	oldObj *unstructured.Unstructured, operation admissionv1.Operation) error {
+	deadlines := policyDeadlines{}
+	failures := &policyFailures{}
+	defer func() {
+		result.SkippedPolicies = append(result.SkippedPolicies, failures.policies...)
+	}()
+	for _, p := range o.getOverridersFromOverridePolicies(ctx, policies, result.Object, operation, utils.RequestInfoFromContext(ctx)) {
+		if !failures.begin(p, result.Object, len(result.PolicyPatches)) {
+			continue
+		}
+
+		policyCtx, cancel := deadlines.context(ctx, p)
+		// patches are the same as the ones recorded in the applied overrides annotation by admission
+		patches, _, err := o.applyPolicyOverriders(policyCtx, result.Object, oldObj, operation, p)
+		cancel()
+		if err != nil && utils.IgnoreFailure(p.failurePolicy) {
+			result.PolicyPatches = result.PolicyPatches[:failures.skip(p, result.Object, err)]
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("appling policy(%v/%v) err=%w", p.namespace, p.name, err)
+		}
+
+		result.PolicyPatches = append(result.PolicyPatches, PolicyPatches{
+			PolicyName:      p.name,
+			PolicyNamespace: p.namespace,
+			Patches:         patches,
+		})
+	}
+
+	return nil
+}
+
+func diffObjects(original, final *unstructured.Unstructured) (string, error) {
+	a, err := json.MarshalIndent(original.Object, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(final.Object, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: "original",
+		ToFile:   "simulated",
+		Context:  3,
+	})
+}
@@ -0,0 +1,284 @@
+package overridemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/test/mock"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cue"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister/fake"
+	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
+)
+
+func TestSimulate(t *testing.T) {
+	deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+	deploymentObj, _ := utilhelper.ToUnstructured(deployment)
+
+	cop := &policyv1alpha1.ClusterOverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cop",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					TargetOperations: []admissionv1.Operation{admissionv1.Create},
+					Overriders: policyv1alpha1.Overriders{
+						Plaintext: []policyv1alpha1.PlaintextOverrider{
+							{
+								Path:     "/metadata/annotations",
+								Operator: "add",
+								Value:    apiextensionsv1.JSON{Raw: []byte(`{"foo":"bar"}`)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	op := &policyv1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "op",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: policyv1alpha1.Overriders{
+						Plaintext: []policyv1alpha1.PlaintextOverrider{
+							{
+								Path:     "/metadata/annotations/foo",
+								Operator: "replace",
+								Value:    apiextensionsv1.JSON{Raw: []byte(`"baz"`)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(nil, []GeneralOverridePolicy{op, cop}, deploymentObj, nil, admissionv1.Create)
+	if err != nil {
+		t.Fatalf("Simulate() err=%v", err)
+	}
+
+	if got := result.Object.GetAnnotations(); !reflect.DeepEqual(got, map[string]string{"foo": "baz"}) {
+		t.Errorf("Simulate() annotations = %v, want foo=baz", got)
+	}
+	if deploymentObj.GetAnnotations() != nil {
+		t.Errorf("Simulate() should not change the given object")
+	}
+
+	wantPolicyPatches := []PolicyPatches{
+		{
+			PolicyName: "cop",
+			Patches: []jsonpatchv2.JsonPatchOperation{
+				{Operation: "add", Path: "/metadata/annotations", Value: apiextensionsv1.JSON{Raw: []byte(`{"foo":"bar"}`)}},
+			},
+		},
+		{
+			PolicyName:      "op",
+			PolicyNamespace: metav1.NamespaceDefault,
+			Patches: []jsonpatchv2.JsonPatchOperation{
+				{Operation: "replace", Path: "/metadata/annotations/foo", Value: apiextensionsv1.JSON{Raw: []byte(`"baz"`)}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result.PolicyPatches, wantPolicyPatches) {
+		t.Errorf("Simulate() PolicyPatches = %v, want %v", result.PolicyPatches, wantPolicyPatches)
+	}
+
+	// patches of policies are the same as the ones recorded by admission
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	opLister := mock.NewMockOverridePolicyLister(ctrl)
+	opLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.OverridePolicy{op}, nil)
+	copLister := mock.NewMockClusterOverridePolicyLister(ctrl)
+	copLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterOverridePolicy{cop}, nil)
+	appliedCOPs, appliedOPs, err := NewOverrideManager(nil, copLister, opLister, OverrideManagerOptions{}).
+		ApplyOverridePolicies(context.Background(), deploymentObj.DeepCopy(), nil, admissionv1.Create)
+	if err != nil {
+		t.Fatalf("ApplyOverridePolicies() err=%v", err)
+	}
+	if !reflect.DeepEqual(appliedCOPs.AppliedItems[0].Patches, result.PolicyPatches[0].Patches) ||
+		!reflect.DeepEqual(appliedOPs.AppliedItems[0].Patches, result.PolicyPatches[1].Patches) {
+		t.Errorf("Simulate() PolicyPatches = %v, want the ones recorded by admission %v, %v", result.PolicyPatches, appliedCOPs, appliedOPs)
+	}
+
+	wantPatches := []jsonpatchv2.JsonPatchOperation{
+		{Operation: "add", Path: "/metadata/annotations", Value: map[string]any{"foo": "baz"}},
+	}
+	if !reflect.DeepEqual(result.Patches, wantPatches) {
+		t.Errorf("Simulate() Patches = %v, want %v", result.Patches, wantPatches)
+	}
+	if !strings.Contains(result.Diff, `+    "annotations": {`) {
+		t.Errorf("Simulate() Diff = %v", result.Diff)
+	}
+}
+
+func TestSimulate_Cel(t *testing.T) {
+	deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+	deploymentObj, _ := utilhelper.ToUnstructured(deployment)
+
+	cop := &policyv1alpha1.ClusterOverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cop",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: policyv1alpha1.Overriders{
+						Cel: `[{"op": "add", "path": "/metadata/annotations", "value": {"name": object.metadata.name, "operation": request.operation}}]`,
+					},
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(nil, []GeneralOverridePolicy{cop}, deploymentObj, nil, admissionv1.Create)
+	if err != nil {
+		t.Fatalf("Simulate() err=%v", err)
+	}
+
+	want := map[string]string{"name": "test", "operation": "CREATE"}
+	if got := result.Object.GetAnnotations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Simulate() annotations = %v, want %v", got, want)
+	}
+
+	cop.Spec.OverrideRules[0].Overriders.Cel = `object.metadata.name`
+	if _, err := Simulate(nil, []GeneralOverridePolicy{cop}, deploymentObj, nil, admissionv1.Create); err == nil {
+		t.Errorf("Simulate() should fail if cel does not evaluate to patches")
+	}
+}
+
+func TestSimulate_Timeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(3 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+
+	deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+	deploymentObj, _ := utilhelper.ToUnstructured(deployment)
+
+	timeoutSeconds := int32(1)
+	cop := &policyv1alpha1.ClusterOverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cop",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			TimeoutSeconds: &timeoutSeconds,
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: policyv1alpha1.Overriders{
+						Cue: fmt.Sprintf(`
+processing: {
+  http: {
+    method: "GET"
+    url: %q
+  }
+  output: {}
+}
+patches: []
+`, s.URL),
+					},
+				},
+			},
+		},
+	}
+
+	start := time.Now()
+	_, err := Simulate(nil, []GeneralOverridePolicy{cop}, deploymentObj, nil, admissionv1.Create)
+	var timeoutErr *utils.PolicyTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Policy != "cop" {
+		t.Fatalf("Simulate() err=%v, want PolicyTimeoutError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Simulate() should be cut off by the timeout of policy, took %v", elapsed)
+	}
+}
+
+func TestSimulate_ValueRef(t *testing.T) {
+	deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+	deploymentObj, _ := utilhelper.ToUnstructured(deployment)
+
+	cop := &policyv1alpha1.ClusterOverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cop",
+		},
+		Spec: policyv1alpha1.OverridePolicySpec{
+			OverrideRules: []policyv1alpha1.RuleWithOperation{
+				{
+					Overriders: policyv1alpha1.Overriders{
+						Template: &policyv1alpha1.OverrideRuleTemplate{
+							Type:      policyv1alpha1.OverrideRuleTypeAnnotations,
+							Operation: policyv1alpha1.OverriderOpAdd,
+							ValueRef: &policyv1alpha1.ResourceRefer{
+								From: policyv1alpha1.FromK8s,
+								K8s: &policyv1alpha1.ResourceSelector{
+									APIVersion: "v1",
+									Kind:       "ConfigMap",
+									Namespace:  metav1.NamespaceDefault,
+									Name:       "config",
+								},
+							},
+						},
+						RenderedCue: `
+data: _ @tag(data)
+patches: [{
+    op:   "replace"
+    path: "/metadata/annotations"
+    value: {owner: data.extraParams.otherObject.data.owner}
+}]
+`,
+					},
+				},
+			},
+		},
+	}
+
+	// valid input must not panic without a lister
+	if _, err := Simulate(nil, []GeneralOverridePolicy{cop}, deploymentObj, nil, admissionv1.Create); !errors.Is(err, cue.ErrNoLister) {
+		t.Fatalf("Simulate() err=%v, want ErrNoLister", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	dynamicClient, err := fake.NewFakeDynamicResourceLister(done, &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "config"},
+		Data:       map[string]string{"owner": "team-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Simulate(dynamicClient, []GeneralOverridePolicy{cop}, deploymentObj, nil, admissionv1.Create)
+	if err != nil {
+		t.Fatalf("Simulate() err=%v", err)
+	}
+	if got := result.Object.GetAnnotations(); !reflect.DeepEqual(got, map[string]string{"owner": "team-a"}) {
+		t.Errorf("Simulate() annotations = %v, want owner=team-a", got)
+	}
+}
@@ -0,0 +1,452 @@
+package overridereconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	jsonpatchv2 "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/informermanager"
+	"github.com/k-cloud-labs/pkg/utils/metrics"
+	"github.com/k-cloud-labs/pkg/utils/overridemanager"
+	"github.com/k-cloud-labs/pkg/utils/restmapper"
+	"github.com/k-cloud-labs/pkg/utils/util"
+)
+
+const (
+	defaultQPS   = 10
+	defaultBurst = 20
+)
+
+var (
+	overridePolicyGVR        = policyv1alpha1.SchemeGroupVersion.WithResource("overridepolicies")
+	clusterOverridePolicyGVR = policyv1alpha1.SchemeGroupVersion.WithResource("clusteroverridepolicies")
+	namespaceGVR             = corev1.SchemeGroupVersion.WithResource("namespaces")
+)
+
+// OverrideReconciler re-applies override policies to existing resources in background.
+// Only policies with spec.backgroundApply enabled are watched, when such a policy is created, changed or deleted,
+// resources matched by its resource selectors before and after the change are reconciled:
+// overrides recorded in applied overrides annotations are reverted, then current override policies are applied
+// as a CREATE operation, the difference is patched to the resource.
+// Policies without resource selectors are ignored since they match all kinds of resources,
+// and so are selectors which don't select a single kind, e.g. selectors with wildcards.
+type OverrideReconciler interface {
+	// Start watches override policies and reconciles resources until ctx done.
+	Start(ctx context.Context) error
+}
+
+// objectKey identifies a resource to reconcile.
+type objectKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+type overrideReconcilerImpl struct {
+	informer        informermanager.SingleClusterInformerManager
+	restMapper      meta.RESTMapper
+	overrideManager overridemanager.OverrideManager
+	dryRun          bool
+
+	policyQueue workqueue.RateLimitingInterface
+	objectQueue workqueue.RateLimitingInterface
+
+	mu sync.Mutex
+	// pendingSelectors is the resource selectors of changed policies to reconcile, keyed by policy key.
+	pendingSelectors map[string][]policyv1alpha1.ResourceSelector
+}
+
+// NewOverrideReconciler returns an implement of OverrideReconciler.
+// qps limits the number of resources reconciled per second, qps with value '0' means use default qps.
+// If dryRun is true, the patches are only logged and not sent to api server.
+func NewOverrideReconciler(informer informermanager.SingleClusterInformerManager, restMapper meta.RESTMapper,
+	om overridemanager.OverrideManager, qps float64, dryRun bool) OverrideReconciler {
+	burst := defaultBurst
+	if qps <= 0 {
+		qps = defaultQPS
+	} else if int(qps) > burst {
+		burst = int(qps)
+	}
+
+	objectRateLimiter := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+
+	return &overrideReconcilerImpl{
+		informer:         informer,
+		restMapper:       restMapper,
+		overrideManager:  om,
+		dryRun:           dryRun,
+		policyQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "override-reconciler-policies"),
+		objectQueue:      workqueue.NewNamedRateLimitingQueue(objectRateLimiter, "override-reconciler-objects"),
+		pendingSelectors: make(map[string][]policyv1alpha1.ResourceSelector),
+	}
+}
+
+func (r *overrideReconcilerImpl) Start(ctx context.Context) error {
+	defer r.policyQueue.ShutDown()
+	defer r.objectQueue.ShutDown()
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.onPolicyAdd,
+		UpdateFunc: r.onPolicyUpdate,
+		DeleteFunc: r.onPolicyDelete,
+	}
+	r.informer.ForResource(overridePolicyGVR, handler)
+	r.informer.ForResource(clusterOverridePolicyGVR, handler)
+	r.informer.Start()
+	for gvr, synced := range r.informer.WaitForCacheSync() {
+		if !synced {
+			return fmt.Errorf("sync resource(%v) failed", gvr.String())
+		}
+	}
+
+	go wait.UntilWithContext(ctx, r.runPolicyWorker, time.Second)
+	go wait.UntilWithContext(ctx, r.runObjectWorker, time.Second)
+
+	klog.InfoS("Started override reconciler.", "dryRun", r.dryRun)
+	<-ctx.Done()
+	klog.InfoS("Stopped override reconciler.")
+	return nil
+}
+
+func (r *overrideReconcilerImpl) onPolicyAdd(obj interface{}) {
+	policy, err := toGeneralOverridePolicy(obj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to convert override policy.")
+		return
+	}
+
+	if policy.GetOverridePolicySpec().BackgroundApply {
+		r.enqueuePolicy(policy, policy.GetOverridePolicySpec().ResourceSelectors)
+	}
+}
+
+func (r *overrideReconcilerImpl) onPolicyUpdate(oldObj, newObj interface{}) {
+	oldPolicy, err := toGeneralOverridePolicy(oldObj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to convert override policy.")
+		return
+	}
+
+	newPolicy, err := toGeneralOverridePolicy(newObj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to convert override policy.")
+		return
+	}
+
+	// spec not changed, e.g. status updated or resync
+	if oldPolicy.(metav1.Object).GetGeneration() == newPolicy.(metav1.Object).GetGeneration() ||
+		!newPolicy.GetOverridePolicySpec().BackgroundApply {
+		return
+	}
+
+	// resources no longer matched should be reconciled too
+	var selectors []policyv1alpha1.ResourceSelector
+	selectors = append(selectors, oldPolicy.GetOverridePolicySpec().ResourceSelectors...)
+	selectors = append(selectors, newPolicy.GetOverridePolicySpec().ResourceSelectors...)
+	r.enqueuePolicy(newPolicy, selectors)
+}
+
+func (r *overrideReconcilerImpl) onPolicyDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	r.onPolicyAdd(obj)
+}
+
+func (r *overrideReconcilerImpl) enqueuePolicy(policy overridemanager.GeneralOverridePolicy, selectors []policyv1alpha1.ResourceSelector) {
+	key := policy.GetName()
+	if policy.GetNamespace() != "" {
+		key = policy.GetNamespace() + "/" + policy.GetName()
+	}
+
+	r.mu.Lock()
+	r.pendingSelectors[key] = append(r.pendingSelectors[key], withNamespace(selectors, policy.GetNamespace())...)
+	r.mu.Unlock()
+
+	r.policyQueue.Add(key)
+}
+
+// withNamespace restricts selectors of OverridePolicy to its namespace.
+func withNamespace(selectors []policyv1alpha1.ResourceSelector, namespace string) []policyv1alpha1.ResourceSelector {
+	if namespace == "" {
+		return selectors
+	}
+
+	result := make([]policyv1alpha1.ResourceSelector, 0, len(selectors))
+	for _, rs := range selectors {
+		rs.Namespace = namespace
+		result = append(result, rs)
+	}
+
+	return result
+}
+
+func (r *overrideReconcilerImpl) runPolicyWorker(ctx context.Context) {
+	for r.processNextPolicy(ctx) {
+	}
+}
+
+func (r *overrideReconcilerImpl) processNextPolicy(ctx context.Context) bool {
+	item, shutdown := r.policyQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.policyQueue.Done(item)
+
+	key := item.(string)
+	r.mu.Lock()
+	selectors := r.pendingSelectors[key]
+	delete(r.pendingSelectors, key)
+	r.mu.Unlock()
+
+	if err := r.enqueueMatchedObjects(selectors); err != nil {
+		klog.ErrorS(err, "Failed to list resources matched by override policy.", "policy", key)
+		// put back selectors to retry
+		r.mu.Lock()
+		r.pendingSelectors[key] = append(r.pendingSelectors[key], selectors...)
+		r.mu.Unlock()
+		r.policyQueue.AddRateLimited(key)
+		return true
+	}
+
+	r.policyQueue.Forget(key)
+	return true
+}
+
+func (r *overrideReconcilerImpl) enqueueMatchedObjects(selectors []policyv1alpha1.ResourceSelector) error {
+	for _, rs := range selectors {
+		gvk, ok := utils.SelectedGVK(rs)
+		if !ok {
+			klog.V(2).InfoS("Skip resource selector without a single kind.", "selector", rs)
+			continue
+		}
+
+		gvr, err := restmapper.GetGroupVersionResource(r.restMapper, gvk)
+		if err != nil {
+			return err
+		}
+
+		lister, err := r.syncedLister(gvr)
+		if err != nil {
+			return err
+		}
+
+		mc := &utils.MatchContext{RESTMapper: r.restMapper}
+		if rs.NamespaceSelector != nil {
+			nsLister, err := r.syncedLister(namespaceGVR)
+			if err != nil {
+				return err
+			}
+			mc.NamespaceLabels = utils.NamespaceLabelsFromLister(nsLister)
+		}
+
+		var objs []runtime.Object
+		if rs.Namespace != "" && !utils.IsGlobPattern(rs.Namespace) {
+			objs, err = lister.ByNamespace(rs.Namespace).List(labels.Everything())
+		} else {
+			objs, err = lister.List(labels.Everything())
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range objs {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok || !utils.ResourceMatchesWithContext(u, mc, rs) {
+				continue
+			}
+
+			r.objectQueue.AddRateLimited(objectKey{gvr: gvr, namespace: u.GetNamespace(), name: u.GetName()})
+		}
+	}
+
+	return nil
+}
+
+// syncedLister returns the lister of gvr, the informer is started and synced if it's not synced yet.
+func (r *overrideReconcilerImpl) syncedLister(gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+	lister := r.informer.Lister(gvr)
+	if !r.informer.IsInformerSynced(gvr) {
+		r.informer.Start()
+		if !r.informer.WaitForCacheSync()[gvr] {
+			return nil, fmt.Errorf("sync resource(%v) failed", gvr.String())
+		}
+	}
+
+	return lister, nil
+}
+
+func (r *overrideReconcilerImpl) runObjectWorker(ctx context.Context) {
+	for r.processNextObject(ctx) {
+	}
+}
+
+func (r *overrideReconcilerImpl) processNextObject(ctx context.Context) bool {
+	item, shutdown := r.objectQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.objectQueue.Done(item)
+
+	key := item.(objectKey)
+	if err := r.reconcile(ctx, key); err != nil {
+		klog.ErrorS(err, "Failed to reconcile resource.", "resource", key.gvr.String(), "namespace", key.namespace, "name", key.name)
+		r.objectQueue.AddRateLimited(key)
+		return true
+	}
+
+	r.objectQueue.Forget(key)
+	return true
+}
+
+func (r *overrideReconcilerImpl) reconcile(ctx context.Context, key objectKey) error {
+	lister := r.informer.Lister(key.gvr)
+	var (
+		obj runtime.Object
+		err error
+	)
+	if key.namespace != "" {
+		obj, err = lister.ByNamespace(key.namespace).Get(key.name)
+	} else {
+		obj, err = lister.Get(key.name)
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	original, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	gvk := original.GroupVersionKind()
+	patches, err := r.computePatches(ctx, original)
+	if err != nil {
+		metrics.BackgroundApply(gvk, metrics.BackgroundApplyError)
+		return err
+	}
+
+	if len(patches) == 0 {
+		metrics.BackgroundApply(gvk, metrics.BackgroundApplyUnchanged)
+		return nil
+	}
+
+	// make sure the object is not changed since it's read from cache
+	patches = append([]jsonpatchv2.JsonPatchOperation{
+		jsonpatchv2.NewOperation("test", "/metadata/resourceVersion", original.GetResourceVersion()),
+	}, patches...)
+	patchBytes, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+
+	if r.dryRun {
+		klog.InfoS("Dry run, skip patching resource.", "resource", klog.KObj(original), "kind", gvk.Kind, "patches", string(patchBytes))
+		metrics.BackgroundApply(gvk, metrics.BackgroundApplyDryRun)
+		return nil
+	}
+
+	client := r.informer.GetClient().Resource(key.gvr)
+	if key.namespace != "" {
+		_, err = client.Namespace(key.namespace).Patch(ctx, key.name, types.JSONPatchType, patchBytes, metav1.PatchOptions{})
+	} else {
+		_, err = client.Patch(ctx, key.name, types.JSONPatchType, patchBytes, metav1.PatchOptions{})
+	}
+	if err != nil {
+		metrics.BackgroundApply(gvk, metrics.BackgroundApplyError)
+		return err
+	}
+
+	klog.V(2).InfoS("Patched resource in background.", "resource", klog.KObj(original), "kind", gvk.Kind)
+	metrics.BackgroundApply(gvk, metrics.BackgroundApplyPatched)
+	return nil
+}
+
+// computePatches returns JSON patches which converge original to the result of current override policies.
+func (r *overrideReconcilerImpl) computePatches(ctx context.Context, original *unstructured.Unstructured) ([]jsonpatchv2.JsonPatchOperation, error) {
+	obj := original.DeepCopy()
+	appliedCOPs, appliedOPs, err := r.overrideManager.ReapplyOverridePolicies(ctx, obj, nil, admissionv1.Create)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setAppliedOverrides(obj, utils.AppliedClusterOverrides, appliedCOPs); err != nil {
+		return nil, err
+	}
+
+	if err := setAppliedOverrides(obj, utils.AppliedOverrides, appliedOPs); err != nil {
+		return nil, err
+	}
+
+	originalBytes, err := original.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	objBytes, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonpatchv2.CreatePatch(originalBytes, objBytes)
+}
+
+func setAppliedOverrides(obj *unstructured.Unstructured, key string, applied *overridemanager.AppliedOverrides) error {
+	if applied == nil || len(applied.AppliedItems) == 0 {
+		return nil
+	}
+
+	value, err := applied.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = string(value)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+func toGeneralOverridePolicy(obj interface{}) (overridemanager.GeneralOverridePolicy, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	if u.GetNamespace() == "" {
+		return util.ConvertToClusterOverridePolicy(u)
+	}
+
+	return util.ConvertToOverridePolicy(u)
+}
@@ -0,0 +1,122 @@
+package overridereconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/fake"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/test/helper"
+	"github.com/k-cloud-labs/pkg/test/mock"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/informermanager"
+	"github.com/k-cloud-labs/pkg/utils/overridemanager"
+	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
+)
+
+func TestOverrideReconciler(t *testing.T) {
+	deploymentGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+	deploymentGVK := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion})
+	restMapper.Add(deploymentGVK, meta.RESTScopeNamespace)
+
+	tests := []struct {
+		name            string
+		backgroundApply bool
+		dryRun          bool
+		wantPatched     bool
+	}{
+		{
+			name:            "patch existing resource",
+			backgroundApply: true,
+			wantPatched:     true,
+		},
+		{
+			name:            "dry run",
+			backgroundApply: true,
+			dryRun:          true,
+		},
+		{
+			name: "background apply disabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cop := &policyv1alpha1.ClusterOverridePolicy{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: policyv1alpha1.SchemeGroupVersion.String(),
+					Kind:       "ClusterOverridePolicy",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "cop"},
+				Spec: policyv1alpha1.OverridePolicySpec{
+					BackgroundApply: tt.backgroundApply,
+					ResourceSelectors: []policyv1alpha1.ResourceSelector{
+						{APIVersion: "apps/v1", Kind: "Deployment"},
+					},
+					OverrideRules: []policyv1alpha1.RuleWithOperation{
+						{
+							Overriders: policyv1alpha1.Overriders{
+								Plaintext: []policyv1alpha1.PlaintextOverrider{
+									{
+										Path:     "/metadata/labels",
+										Operator: policyv1alpha1.OverriderOpAdd,
+										Value:    apiextensionsv1.JSON{Raw: []byte(`{"foo":"bar"}`)},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			copObj, _ := utilhelper.ToUnstructured(cop)
+			deployment := helper.NewDeployment(metav1.NamespaceDefault, "test")
+			deployment.ResourceVersion = "1"
+			deploymentObj, _ := utilhelper.ToUnstructured(deployment)
+
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				deploymentGVR:            "DeploymentList",
+				overridePolicyGVR:        "OverridePolicyList",
+				clusterOverridePolicyGVR: "ClusterOverridePolicyList",
+			}, deploymentObj, copObj)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			copLister := mock.NewMockClusterOverridePolicyLister(ctrl)
+			copLister.EXPECT().List(gomock.Any()).Return([]*policyv1alpha1.ClusterOverridePolicy{cop}, nil).AnyTimes()
+			opLister := mock.NewMockOverridePolicyLister(ctrl)
+			opLister.EXPECT().List(gomock.Any()).Return(nil, nil).AnyTimes()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			informer := informermanager.NewSingleClusterInformerManager(client, 0, ctx.Done())
+			r := NewOverrideReconciler(informer, restMapper, overridemanager.NewOverrideManager(nil, copLister, opLister, overridemanager.OverrideManagerOptions{}), 0, tt.dryRun)
+			go func() {
+				if err := r.Start(ctx); err != nil {
+					t.Errorf("Start() err=%v", err)
+				}
+			}()
+
+			err := wait.PollImmediate(50*time.Millisecond, 500*time.Millisecond, func() (bool, error) {
+				obj, err := client.Resource(deploymentGVR).Namespace(metav1.NamespaceDefault).Get(ctx, "test", metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+
+				return obj.GetLabels()["foo"] == "bar" && obj.GetAnnotations()[utils.AppliedClusterOverrides] != "", nil
+			})
+			if patched := err == nil; patched != tt.wantPatched {
+				t.Errorf("patched=%v, want %v, err=%v", patched, tt.wantPatched, err)
+			}
+		})
+	}
+}
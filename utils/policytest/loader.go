@@ -0,0 +1,164 @@
+package policytest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/util"
+)
+
+// Suite is the policies and tests loaded from files.
+type Suite struct {
+	OverridePolicies        []*policyv1alpha1.OverridePolicy
+	ClusterOverridePolicies []*policyv1alpha1.ClusterOverridePolicy
+	ClusterValidatePolicies []*policyv1alpha1.ClusterValidatePolicy
+	ValidatePolicies        []*policyv1alpha1.ValidatePolicy
+	Tests                   []*PolicyTest
+
+	renderer *renderer
+}
+
+// LoadSuite loads policies and tests from yaml files in paths, directories are walked recursively.
+// Each yaml file can contain multiple documents, documents with unknown kind are ignored.
+// Templates of rules are rendered into renderedCue like the mutating hook of policies.
+func LoadSuite(paths ...string) (*Suite, error) {
+	r, err := newRenderer()
+	if err != nil {
+		return nil, err
+	}
+
+	suite := &Suite{renderer: r}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || !isYAMLFile(p) {
+				return nil
+			}
+
+			if err := suite.loadFile(p); err != nil {
+				return fmt.Errorf("load file(%s) err=%w", p, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return suite, nil
+}
+
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func (s *Suite) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		if err := s.loadDocument(doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Suite) loadDocument(doc []byte) error {
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+		return err
+	}
+
+	if typeMeta.Kind == KindPolicyTest {
+		test := &PolicyTest{}
+		if err := yaml.UnmarshalStrict(doc, test); err != nil {
+			return err
+		}
+
+		s.Tests = append(s.Tests, test)
+		return nil
+	}
+
+	if typeMeta.GroupVersionKind().GroupVersion() != policyv1alpha1.SchemeGroupVersion {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+		return err
+	}
+
+	switch typeMeta.Kind {
+	case "OverridePolicy":
+		policy, err := util.ConvertToOverridePolicy(obj)
+		if err != nil {
+			return err
+		}
+		if err := s.renderer.renderOverrideRules(policy.Spec.OverrideRules); err != nil {
+			return fmt.Errorf("render templates of %s err=%w", policy.Name, err)
+		}
+		s.OverridePolicies = append(s.OverridePolicies, policy)
+	case "ClusterOverridePolicy":
+		policy, err := util.ConvertToClusterOverridePolicy(obj)
+		if err != nil {
+			return err
+		}
+		if err := s.renderer.renderOverrideRules(policy.Spec.OverrideRules); err != nil {
+			return fmt.Errorf("render templates of %s err=%w", policy.Name, err)
+		}
+		s.ClusterOverridePolicies = append(s.ClusterOverridePolicies, policy)
+	case "ClusterValidatePolicy":
+		policy, err := util.ConvertToClusterValidatePolicy(obj)
+		if err != nil {
+			return err
+		}
+		if err := s.renderer.renderValidateRules(policy.Spec.ValidateRules); err != nil {
+			return fmt.Errorf("render templates of %s err=%w", policy.Name, err)
+		}
+		s.ClusterValidatePolicies = append(s.ClusterValidatePolicies, policy)
+	case "ValidatePolicy":
+		policy, err := util.ConvertToValidatePolicy(obj)
+		if err != nil {
+			return err
+		}
+		if err := s.renderer.renderValidateRules(policy.Spec.ValidateRules); err != nil {
+			return fmt.Errorf("render templates of %s err=%w", policy.Name, err)
+		}
+		s.ValidatePolicies = append(s.ValidatePolicies, policy)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package policytest
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/interrupter/model"
+	"github.com/k-cloud-labs/pkg/utils/templatemanager"
+	"github.com/k-cloud-labs/pkg/utils/templatemanager/templates"
+)
+
+// renderer renders templates of rules into renderedCue with the built-in templates, like the mutating hook
+// of policies does before they're stored, so template rules take effect without a cluster.
+type renderer struct {
+	overrideTemplateManager templatemanager.TemplateManager
+	validateTemplateManager templatemanager.TemplateManager
+	cueManager              templatemanager.CueManager
+}
+
+func newRenderer() (*renderer, error) {
+	otm, err := templatemanager.NewOverrideTemplateManager(&templatemanager.TemplateSource{
+		Content:      templates.OverrideTemplate,
+		TemplateName: "BaseTemplate",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vtm, err := templatemanager.NewValidateTemplateManager(&templatemanager.TemplateSource{
+		Content:      templates.ValidateTemplate,
+		TemplateName: "BaseTemplate",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderer{
+		overrideTemplateManager: otm,
+		validateTemplateManager: vtm,
+		cueManager:              templatemanager.NewCueManager(),
+	}, nil
+}
+
+// renderOverrideRules sets renderedCue of override rules with templates.
+func (r *renderer) renderOverrideRules(rules []policyv1alpha1.RuleWithOperation) error {
+	for i := range rules {
+		tmpl := rules[i].Overriders.Template
+		if tmpl == nil {
+			continue
+		}
+
+		b, err := r.overrideTemplateManager.Render(model.OverrideRulesToOverridePolicyRenderData(tmpl))
+		if err != nil {
+			return err
+		}
+
+		if b, err = r.cueManager.Format(trimBlankLine(b)); err != nil {
+			return err
+		}
+		rules[i].Overriders.RenderedCue = string(b)
+	}
+
+	return nil
+}
+
+// renderValidateRules sets renderedCue of validate rules with templates,
+// affectMode of conditions defaults to reject like the mutating hook.
+func (r *renderer) renderValidateRules(rules []policyv1alpha1.ValidateRuleWithOperation) error {
+	for i := range rules {
+		tmpl := rules[i].Template
+		if tmpl == nil {
+			continue
+		}
+
+		if tmpl.Condition != nil && tmpl.Condition.AffectMode == "" {
+			tmpl.Condition.AffectMode = policyv1alpha1.AffectModeReject
+		}
+
+		b, err := r.validateTemplateManager.Render(model.ValidateRulesToValidatePolicyRenderData(tmpl))
+		if err != nil {
+			return err
+		}
+
+		if b, err = r.cueManager.Format(trimBlankLine(b)); err != nil {
+			return err
+		}
+		rules[i].RenderedCue = string(b)
+	}
+
+	return nil
+}
+
+// trimBlankLine removes blank lines of rendered templates.
+func trimBlankLine(data []byte) []byte {
+	s := bufio.NewScanner(bytes.NewBuffer(data))
+	var buf = &bytes.Buffer{}
+	for s.Scan() {
+		if len(strings.TrimSpace(s.Text())) == 0 {
+			continue
+		}
+
+		buf.Write(s.Bytes())
+		buf.WriteByte('\n')
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+}
@@ -0,0 +1,231 @@
+package policytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/dynamiclister/fake"
+	"github.com/k-cloud-labs/pkg/utils/overridemanager"
+	"github.com/k-cloud-labs/pkg/utils/validatemanager"
+)
+
+// Runner runs tests of a suite through OverrideManager and ValidateManager backed by in-memory listers.
+type Runner struct {
+	suite *Suite
+
+	copLister v1alpha1.ClusterOverridePolicyLister
+	opLister  v1alpha1.OverridePolicyLister
+	cvpLister v1alpha1.ClusterValidatePolicyLister
+	vpLister  v1alpha1.ValidatePolicyLister
+}
+
+// NewRunner returns a Runner for suite.
+func NewRunner(suite *Suite) *Runner {
+	copIndexer, opIndexer, cvpIndexer, vpIndexer := newIndexer(), newIndexer(), newIndexer(), newIndexer()
+	// keys of policies never fail to compute, so errors are ignored
+	for _, cop := range suite.ClusterOverridePolicies {
+		_ = copIndexer.Add(cop)
+	}
+	for _, op := range suite.OverridePolicies {
+		_ = opIndexer.Add(op)
+	}
+	for _, cvp := range suite.ClusterValidatePolicies {
+		_ = cvpIndexer.Add(cvp)
+	}
+	for _, vp := range suite.ValidatePolicies {
+		_ = vpIndexer.Add(vp)
+	}
+
+	return &Runner{
+		suite:     suite,
+		copLister: v1alpha1.NewClusterOverridePolicyLister(copIndexer),
+		opLister:  v1alpha1.NewOverridePolicyLister(opIndexer),
+		cvpLister: v1alpha1.NewClusterValidatePolicyLister(cvpIndexer),
+		vpLister:  v1alpha1.NewValidatePolicyLister(vpIndexer),
+	}
+}
+
+func newIndexer() cache.Indexer {
+	return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+// Run runs all tests in suite and returns results of every case.
+// An error is returned only if the runner can not be set up for a test.
+func (r *Runner) Run(ctx context.Context) ([]Result, error) {
+	var results []Result
+	for _, test := range r.suite.Tests {
+		rs, err := r.runTest(ctx, test)
+		if err != nil {
+			return nil, fmt.Errorf("run test(%s) err=%w", test.Name, err)
+		}
+
+		results = append(results, rs...)
+	}
+
+	return results, nil
+}
+
+func (r *Runner) runTest(ctx context.Context, test *PolicyTest) ([]Result, error) {
+	done := make(chan struct{})
+	defer close(done)
+
+	resources := make([]runtime.Object, 0, len(test.Resources))
+	for _, resource := range test.Resources {
+		resources = append(resources, &unstructured.Unstructured{Object: resource})
+	}
+
+	dynamicClient, err := fake.NewFakeDynamicResourceLister(done, resources...)
+	if err != nil {
+		return nil, err
+	}
+
+	om := overridemanager.NewOverrideManager(dynamicClient, r.copLister, r.opLister, overridemanager.OverrideManagerOptions{})
+	vm := validatemanager.NewValidateManager(dynamicClient, r.cvpLister, r.vpLister, validatemanager.ValidateManagerOptions{
+		Mode: validatemanager.ValidateModeAggregate,
+	})
+
+	results := make([]Result, 0, len(test.Cases))
+	for _, tc := range test.Cases {
+		result := Result{
+			Test: test.Name,
+			Case: tc.Name,
+		}
+
+		msgs, err := runCase(ctx, om, vm, tc)
+		switch {
+		case err != nil:
+			result.Message = err.Error()
+		case len(msgs) > 0:
+			result.Message = strings.Join(msgs, "\n")
+		default:
+			result.Passed = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// runCase runs a case and returns messages of unsatisfied expectations.
+func runCase(ctx context.Context, om overridemanager.OverrideManager, vm validatemanager.ValidateManager, tc TestCase) ([]string, error) {
+	if tc.Object == nil {
+		return nil, fmt.Errorf("object is required")
+	}
+
+	obj := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(tc.Object)}
+	var oldObj *unstructured.Unstructured
+	if tc.OldObject != nil {
+		oldObj = &unstructured.Unstructured{Object: runtime.DeepCopyJSON(tc.OldObject)}
+	}
+
+	operation := tc.Operation
+	if operation == "" {
+		operation = admissionv1.Create
+	}
+
+	ctx = utils.ContextWithRequestInfo(ctx, &utils.RequestInfo{
+		UserInfo:    tc.UserInfo,
+		SubResource: tc.SubResource,
+		DryRun:      tc.DryRun,
+	})
+	if _, _, err := om.ApplyOverridePolicies(ctx, obj, oldObj, operation); err != nil {
+		return nil, fmt.Errorf("apply override policies err=%w", err)
+	}
+
+	var msgs []string
+	if tc.Expected.Object != nil {
+		msg, err := compareObject(tc.Expected.Object, obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		if msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if tc.Expected.Allowed == nil && tc.Expected.Reason == "" {
+		return msgs, nil
+	}
+
+	result, err := vm.ApplyValidatePolicies(ctx, obj, oldObj, operation)
+	if err != nil {
+		return nil, fmt.Errorf("apply validate policies err=%w", err)
+	}
+
+	if tc.Expected.Allowed != nil && *tc.Expected.Allowed != result.Valid {
+		msgs = append(msgs, fmt.Sprintf("expected allowed=%v, got allowed=%v, reason: %s", *tc.Expected.Allowed, result.Valid, result.Reason))
+	}
+
+	if tc.Expected.Reason != "" && !strings.Contains(result.Reason, tc.Expected.Reason) {
+		msgs = append(msgs, fmt.Sprintf("expected reason contains %q, got %q", tc.Expected.Reason, result.Reason))
+	}
+
+	return msgs, nil
+}
+
+// compareObject returns a unified diff if expected is not equal to actual after json normalization.
+func compareObject(expected, actual map[string]interface{}) (string, error) {
+	a, err := normalize(expected)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := normalize(actual)
+	if err != nil {
+		return "", err
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return "", nil
+	}
+
+	ja, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	jb, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(ja)),
+		B:        difflib.SplitLines(string(jb)),
+		FromFile: "expected",
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "object mismatch:\n" + diff, nil
+}
+
+// normalize round-trips v through json, so numbers and empty values are compared in the same form.
+func normalize(v map[string]interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
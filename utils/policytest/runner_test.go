@@ -0,0 +1,92 @@
+package policytest
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunner_Run(t *testing.T) {
+	suite, err := LoadSuite("testdata/policies")
+	if err != nil {
+		t.Fatalf("LoadSuite() err=%v", err)
+	}
+
+	if len(suite.ClusterOverridePolicies) != 1 || len(suite.ClusterValidatePolicies) != 1 || len(suite.Tests) != 1 {
+		t.Fatalf("LoadSuite() got unexpected suite=%+v", suite)
+	}
+
+	results, err := NewRunner(suite).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() err=%v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Run() got %d results, want 2", len(results))
+	}
+
+	for _, result := range results {
+		if !result.Passed {
+			t.Errorf("case %s/%s failed: %s", result.Test, result.Case, result.Message)
+		}
+	}
+}
+
+func TestRunner_RunTemplates(t *testing.T) {
+	suite, err := LoadSuite("testdata/templates")
+	if err != nil {
+		t.Fatalf("LoadSuite() err=%v", err)
+	}
+
+	if suite.ClusterOverridePolicies[0].Spec.OverrideRules[0].Overriders.RenderedCue == "" ||
+		suite.ClusterValidatePolicies[0].Spec.ValidateRules[0].RenderedCue == "" {
+		t.Fatalf("LoadSuite() should render templates into renderedCue")
+	}
+
+	results, err := NewRunner(suite).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() err=%v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Run() got %d results, want 2", len(results))
+	}
+
+	for _, result := range results {
+		if !result.Passed {
+			t.Errorf("case %s/%s failed: %s", result.Test, result.Case, result.Message)
+		}
+	}
+}
+
+func TestRunner_RunFailed(t *testing.T) {
+	suite, err := LoadSuite("testdata/policies")
+	if err != nil {
+		t.Fatalf("LoadSuite() err=%v", err)
+	}
+
+	allowed := true
+	tc := &suite.Tests[0].Cases[1]
+	tc.Expected.Allowed = &allowed
+	tc.Expected.Reason = ""
+	tc.Expected.Object = map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+	}
+
+	results, err := NewRunner(suite).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() err=%v", err)
+	}
+
+	result := results[1]
+	if result.Passed {
+		t.Fatalf("case %s/%s passed, want failed", result.Test, result.Case)
+	}
+
+	for _, want := range []string{"object mismatch", "expected allowed=true, got allowed=false"} {
+		if !strings.Contains(result.Message, want) {
+			t.Errorf("message %q does not contain %q", result.Message, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package policytest
+
+import (
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KindPolicyTest is the kind of test-case documents.
+const KindPolicyTest = "PolicyTest"
+
+// PolicyTest is a declarative collection of test cases which run against all policies loaded with it.
+//
+//	kind: PolicyTest
+//	name: add-annotations
+//	cases:
+//	- name: create deployment
+//	  operation: CREATE
+//	  object: {...}
+//	  expected:
+//	    object: {...}
+//	    allowed: true
+type PolicyTest struct {
+	metav1.TypeMeta `json:",inline"`
+	// Name is the name of test.
+	Name string `json:"name"`
+	// Resources are objects which can be referred by templates of policies, e.g. `k8s` or `owner` value refer.
+	// +optional
+	Resources []map[string]interface{} `json:"resources,omitempty"`
+	// Cases is the list of test cases.
+	Cases []TestCase `json:"cases"`
+}
+
+// TestCase defines an admission request and the expected result of policies.
+type TestCase struct {
+	// Name is the name of test case.
+	Name string `json:"name"`
+	// Operation is the operation of admission request, CREATE, UPDATE or DELETE.
+	Operation admissionv1.Operation `json:"operation"`
+	// Object is the object in admission request.
+	Object map[string]interface{} `json:"object"`
+	// OldObject is the old object in admission request, only used with UPDATE operation.
+	// +optional
+	OldObject map[string]interface{} `json:"oldObject,omitempty"`
+	// UserInfo is the user who made the admission request.
+	// +optional
+	UserInfo authenticationv1.UserInfo `json:"userInfo,omitempty"`
+	// SubResource is the subresource of admission request, empty means the main resource.
+	// +optional
+	SubResource string `json:"subResource,omitempty"`
+	// DryRun indicates the admission request is a dry-run request.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Expected is the expected result.
+	Expected Expectation `json:"expected"`
+}
+
+// Expectation defines expected result of a test case, only fields with value are checked.
+type Expectation struct {
+	// Object is the expected object after override policies applied.
+	// +optional
+	Object map[string]interface{} `json:"object,omitempty"`
+	// Allowed is the expected verdict of validate policies, the object after overridden is validated.
+	// +optional
+	Allowed *bool `json:"allowed,omitempty"`
+	// Reason is a substring expected in the reason of validate result.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// Result is the result of a test case.
+type Result struct {
+	// Test is the name of PolicyTest.
+	Test string
+	// Case is the name of TestCase.
+	Case string
+	// Passed is true if all expectations are satisfied.
+	Passed bool
+	// Message describes why the case failed.
+	Message string
+}
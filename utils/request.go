@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+// serviceAccountUsernamePrefix is the username prefix of service accounts.
+const serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+// RequestInfo is the metadata of an admission request used to match rules.
+type RequestInfo struct {
+	// UserInfo is the user who made the request.
+	UserInfo authenticationv1.UserInfo
+	// SubResource is the subresource of the request, empty means the main resource.
+	SubResource string
+	// DryRun indicates the request is a dry-run request.
+	DryRun bool
+}
+
+// NewRequestInfo returns the RequestInfo of the admission request.
+func NewRequestInfo(req *admissionv1.AdmissionRequest) *RequestInfo {
+	ri := &RequestInfo{
+		UserInfo:    req.UserInfo,
+		SubResource: req.SubResource,
+	}
+	if req.DryRun != nil {
+		ri.DryRun = *req.DryRun
+	}
+
+	return ri
+}
+
+// RequestMatches tells if the request matches the matcher, ri with value nil is treated as a request
+// made by an unknown user on the main resource without dry-run.
+func RequestMatches(ri *RequestInfo, m policyv1alpha1.RequestMatcher) bool {
+	if ri == nil {
+		ri = &RequestInfo{}
+	}
+
+	if m.UserInfo != nil && !UserMatches(&ri.UserInfo, m.UserInfo) {
+		return false
+	}
+
+	if m.ExcludeUserInfo != nil && UserMatches(&ri.UserInfo, m.ExcludeUserInfo) {
+		return false
+	}
+
+	if len(m.SubResources) > 0 && !anyGlobMatches(m.SubResources, ri.SubResource) {
+		return false
+	}
+
+	return m.DryRun == nil || *m.DryRun == ri.DryRun
+}
+
+// UserMatches tells if the user matches any of users, groups and service accounts of the matcher.
+func UserMatches(userInfo *authenticationv1.UserInfo, m *policyv1alpha1.UserInfoMatcher) bool {
+	if userInfo == nil || m == nil || len(userInfo.Username) == 0 {
+		return false
+	}
+
+	for _, pattern := range m.Users {
+		if globMatches(pattern, userInfo.Username) {
+			return true
+		}
+	}
+
+	for _, pattern := range m.Groups {
+		for _, group := range userInfo.Groups {
+			if globMatches(pattern, group) {
+				return true
+			}
+		}
+	}
+
+	if sa := strings.TrimPrefix(userInfo.Username, serviceAccountUsernamePrefix); sa != userInfo.Username {
+		// username of service account is in the format of 'system:serviceaccount:namespace:name'
+		sa = strings.Replace(sa, ":", "/", 1)
+		for _, pattern := range m.ServiceAccounts {
+			if globMatches(pattern, sa) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
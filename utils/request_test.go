@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+func TestRequestMatches(t *testing.T) {
+	dryRun := true
+	tests := []struct {
+		name string
+		ri   *RequestInfo
+		m    policyv1alpha1.RequestMatcher
+		want bool
+	}{
+		{
+			name: "empty matcher",
+			want: true,
+		},
+		{
+			name: "user",
+			ri:   &RequestInfo{UserInfo: authenticationv1.UserInfo{Username: "admin"}},
+			m: policyv1alpha1.RequestMatcher{
+				UserInfo: &policyv1alpha1.UserInfoMatcher{Users: []string{"adm*"}},
+			},
+			want: true,
+		},
+		{
+			name: "unknown user",
+			m: policyv1alpha1.RequestMatcher{
+				UserInfo: &policyv1alpha1.UserInfoMatcher{Users: []string{"*"}},
+			},
+			want: false,
+		},
+		{
+			name: "group",
+			ri:   &RequestInfo{UserInfo: authenticationv1.UserInfo{Username: "alice", Groups: []string{"dev", "ops"}}},
+			m: policyv1alpha1.RequestMatcher{
+				UserInfo: &policyv1alpha1.UserInfoMatcher{Groups: []string{"ops"}},
+			},
+			want: true,
+		},
+		{
+			name: "excluded service account",
+			ri:   &RequestInfo{UserInfo: authenticationv1.UserInfo{Username: "system:serviceaccount:flux-system:helm-controller"}},
+			m: policyv1alpha1.RequestMatcher{
+				ExcludeUserInfo: &policyv1alpha1.UserInfoMatcher{ServiceAccounts: []string{"flux-system/*"}},
+			},
+			want: false,
+		},
+		{
+			name: "main resource",
+			ri:   &RequestInfo{SubResource: "scale"},
+			m: policyv1alpha1.RequestMatcher{
+				SubResources: []string{""},
+			},
+			want: false,
+		},
+		{
+			name: "subresource",
+			ri:   &RequestInfo{SubResource: "scale"},
+			m: policyv1alpha1.RequestMatcher{
+				SubResources: []string{"scale"},
+			},
+			want: true,
+		},
+		{
+			name: "dry run",
+			ri:   &RequestInfo{},
+			m: policyv1alpha1.RequestMatcher{
+				DryRun: &dryRun,
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestMatches(tt.ri, tt.m); got != tt.want {
+				t.Errorf("RequestMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,62 @@
 package utils
 
 import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/restmapper"
 )
 
+// NamespaceLabelsFunc returns labels of the namespace.
+type NamespaceLabelsFunc func(namespace string) (map[string]string, error)
+
+// MatchContext provides information beyond the resource itself to match resource selectors.
+type MatchContext struct {
+	// NamespaceLabels is used to evaluate namespaceSelector, selectors with namespaceSelector never match if it's nil.
+	NamespaceLabels NamespaceLabelsFunc
+	// RESTMapper is used to evaluate resources, selectors with resources never match if it's nil.
+	RESTMapper meta.RESTMapper
+}
+
+// NamespaceLabelsFromLister returns a NamespaceLabelsFunc which gets namespaces from lister.
+func NamespaceLabelsFromLister(lister cache.GenericLister) NamespaceLabelsFunc {
+	return func(namespace string) (map[string]string, error) {
+		obj, err := lister.Get(namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		return accessor.GetLabels(), nil
+	}
+}
+
 // ResourceMatchSelectors tells if the specific resource matches the selectors.
+// Selectors with namespaceSelector or resources never match, use ResourceMatchSelectorsWithContext for them.
 func ResourceMatchSelectors(resource *unstructured.Unstructured, selectors ...policyv1alpha1.ResourceSelector) bool {
+	return ResourceMatchSelectorsWithContext(resource, nil, selectors...)
+}
+
+// ResourceMatchSelectorsWithContext tells if the specific resource matches the selectors.
+// mc can be nil if no selector has namespaceSelector or resources.
+func ResourceMatchSelectorsWithContext(resource *unstructured.Unstructured, mc *MatchContext, selectors ...policyv1alpha1.ResourceSelector) bool {
 	for _, rs := range selectors {
-		if ResourceMatches(resource, rs) {
+		if ResourceMatchesWithContext(resource, mc, rs) {
 			return true
 		}
 	}
@@ -20,10 +64,15 @@ func ResourceMatchSelectors(resource *unstructured.Unstructured, selectors ...po
 }
 
 // ResourceMatches tells if the specific resource matches the selector.
+// Selectors with namespaceSelector or resources never match, use ResourceMatchesWithContext for them.
 func ResourceMatches(resource *unstructured.Unstructured, rs policyv1alpha1.ResourceSelector) bool {
-	if resource.GetAPIVersion() != rs.APIVersion ||
-		resource.GetKind() != rs.Kind ||
-		(len(rs.Namespace) > 0 && resource.GetNamespace() != rs.Namespace) {
+	return ResourceMatchesWithContext(resource, nil, rs)
+}
+
+// ResourceMatchesWithContext tells if the specific resource matches the selector.
+// mc can be nil if the selector has no namespaceSelector or resources.
+func ResourceMatchesWithContext(resource *unstructured.Unstructured, mc *MatchContext, rs policyv1alpha1.ResourceSelector) bool {
+	if !typeMatches(resource, mc, rs) || !namespaceMatches(resource, mc, rs) {
 		return false
 	}
 
@@ -78,3 +127,169 @@ func ResourceMatches(resource *unstructured.Unstructured, rs policyv1alpha1.Reso
 
 	return true
 }
+
+// typeMatches tells if the type of resource matches apiVersion, kind, apiGroups, versions, kinds and resources of the selector.
+func typeMatches(resource *unstructured.Unstructured, mc *MatchContext, rs policyv1alpha1.ResourceSelector) bool {
+	gvk := resource.GroupVersionKind()
+	if (len(rs.APIVersion) > 0 && resource.GetAPIVersion() != rs.APIVersion) ||
+		(len(rs.Kind) > 0 && gvk.Kind != rs.Kind) ||
+		!anyGlobMatches(rs.APIGroups, gvk.Group) ||
+		!anyGlobMatches(rs.Versions, gvk.Version) ||
+		!anyGlobMatches(rs.Kinds, gvk.Kind) {
+		return false
+	}
+
+	if len(rs.Resources) == 0 {
+		return true
+	}
+
+	if mc == nil || mc.RESTMapper == nil {
+		return false
+	}
+
+	gvr, err := restmapper.GetGroupVersionResource(mc.RESTMapper, gvk)
+	if err != nil {
+		klog.ErrorS(err, "get resource of kind failed", "gvk", gvk.String())
+		return false
+	}
+
+	return anyGlobMatches(rs.Resources, gvr.Resource)
+}
+
+// SelectedGVK returns the only GroupVersionKind selected by the selector,
+// it returns false if the selector selects types with wildcards, multiple values or resources.
+func SelectedGVK(rs policyv1alpha1.ResourceSelector) (schema.GroupVersionKind, bool) {
+	gv, err := schema.ParseGroupVersion(rs.APIVersion)
+	if err != nil || len(rs.Resources) > 0 {
+		return schema.GroupVersionKind{}, false
+	}
+
+	gvk := gv.WithKind(rs.Kind)
+	for _, f := range []struct {
+		values []string
+		value  *string
+		// set means the value is set by apiVersion or kind
+		set bool
+	}{
+		{values: rs.APIGroups, value: &gvk.Group, set: len(rs.APIVersion) > 0},
+		{values: rs.Versions, value: &gvk.Version, set: len(rs.APIVersion) > 0},
+		{values: rs.Kinds, value: &gvk.Kind, set: len(rs.Kind) > 0},
+	} {
+		switch {
+		case len(f.values) == 0:
+		case len(f.values) == 1 && !IsGlobPattern(f.values[0]):
+			if f.set && *f.value != f.values[0] {
+				// conflicting values select nothing
+				return schema.GroupVersionKind{}, false
+			}
+			*f.value = f.values[0]
+		default:
+			return schema.GroupVersionKind{}, false
+		}
+	}
+
+	if len(gvk.Version) == 0 || len(gvk.Kind) == 0 {
+		return schema.GroupVersionKind{}, false
+	}
+
+	return gvk, true
+}
+
+// namespaceMatches tells if the namespace of resource matches namespace, namespaceRegex and namespaceSelector of the selector.
+func namespaceMatches(resource *unstructured.Unstructured, mc *MatchContext, rs policyv1alpha1.ResourceSelector) bool {
+	namespace := resource.GetNamespace()
+	if len(rs.Namespace) > 0 && !globMatches(rs.Namespace, namespace) {
+		return false
+	}
+
+	if len(rs.NamespaceRegex) > 0 {
+		re, err := compileRegex(rs.NamespaceRegex)
+		if err != nil {
+			klog.ErrorS(err, "match namespace regex failed", "regex", rs.NamespaceRegex)
+			return false
+		}
+
+		if !re.MatchString(namespace) {
+			return false
+		}
+	}
+
+	if rs.NamespaceSelector == nil {
+		return true
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(rs.NamespaceSelector)
+	if err != nil {
+		klog.ErrorS(err, "match namespace labels failed")
+		return false
+	}
+
+	// namespace selects itself
+	if resource.GetAPIVersion() == "v1" && resource.GetKind() == "Namespace" {
+		return s.Matches(labels.Set(resource.GetLabels()))
+	}
+
+	if namespace == "" || mc == nil || mc.NamespaceLabels == nil {
+		return false
+	}
+
+	nsLabels, err := mc.NamespaceLabels(namespace)
+	if err != nil {
+		klog.ErrorS(err, "get namespace labels failed", "namespace", namespace)
+		return false
+	}
+
+	return s.Matches(labels.Set(nsLabels))
+}
+
+// IsGlobPattern tells if the pattern contains any glob wildcard.
+func IsGlobPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// anyGlobMatches tells if s matches any of the glob patterns, empty patterns means matching all.
+func anyGlobMatches(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if globMatches(pattern, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// globMatches tells if s matches the glob pattern, the pattern without any wildcard must equal to s.
+func globMatches(pattern, s string) bool {
+	if !IsGlobPattern(pattern) {
+		return pattern == s
+	}
+
+	matched, err := path.Match(pattern, s)
+	if err != nil {
+		klog.ErrorS(err, "match glob pattern failed", "pattern", pattern)
+		return false
+	}
+
+	return matched
+}
+
+var regexCache sync.Map // pattern:*regexp.Regexp
+
+// compileRegex compiles the pattern as a fully anchored regular expression, compiled expressions are cached.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Store(pattern, re)
+	return re, nil
+}
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 )
@@ -39,8 +42,25 @@ func newObjectForSelector() *unstructured.Unstructured {
 func TestResourceMatchSelectors(t *testing.T) {
 	type args struct {
 		resource  *unstructured.Unstructured
+		mc        *MatchContext
 		selectors []policyv1alpha1.ResourceSelector
 	}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mc := &MatchContext{
+		NamespaceLabels: func(namespace string) (map[string]string, error) {
+			if namespace != "default" {
+				return nil, fmt.Errorf("namespace %s not found", namespace)
+			}
+			return map[string]string{"env": "prod"}, nil
+		},
+		RESTMapper: restMapper,
+	}
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName("team-a")
+	namespace.SetLabels(map[string]string{"env": "test"})
 	tests := []struct {
 		name string
 		args args
@@ -345,12 +365,258 @@ func TestResourceMatchSelectors(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "namespace glob",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						Namespace:  "def*",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "namespace glob not matched",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						Namespace:  "team-*",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "namespace regex",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion:     "v1",
+						Kind:           "Pod",
+						NamespaceRegex: "default|kube-.+",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "namespace regex is fully matched",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion:     "v1",
+						Kind:           "Pod",
+						NamespaceRegex: "def",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "namespace selector",
+			args: args{
+				resource: newObjectForSelector(),
+				mc:       mc,
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"env": "prod"},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "namespace selector not matched",
+			args: args{
+				resource: newObjectForSelector(),
+				mc:       mc,
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"env": "test"},
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "namespace selector without match context",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion:        "v1",
+						Kind:              "Pod",
+						NamespaceSelector: &metav1.LabelSelector{},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "namespace selector on namespace",
+			args: args{
+				resource: namespace,
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kind:       "Namespace",
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"env": "test"},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "type lists",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIGroups: []string{"", "apps"},
+						Versions:  []string{"*"},
+						Kinds:     []string{"Pod", "Deployment"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "type lists not matched",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIGroups: []string{"apps"},
+						Kinds:     []string{"*"},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "kind glob with apiVersion",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						APIVersion: "v1",
+						Kinds:      []string{"Po*"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "resources",
+			args: args{
+				resource: newObjectForSelector(),
+				mc:       mc,
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						Resources: []string{"pods"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "resources without match context",
+			args: args{
+				resource: newObjectForSelector(),
+				selectors: []policyv1alpha1.ResourceSelector{
+					{
+						Resources: []string{"*"},
+					},
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := ResourceMatchSelectors(tt.args.resource, tt.args.selectors...); got != tt.want {
-				t.Errorf("ResourceMatchSelectors() = %v, want %v", got, tt.want)
+			if got := ResourceMatchSelectorsWithContext(tt.args.resource, tt.args.mc, tt.args.selectors...); got != tt.want {
+				t.Errorf("ResourceMatchSelectorsWithContext() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestSelectedGVK(t *testing.T) {
+	tests := []struct {
+		name   string
+		rs     policyv1alpha1.ResourceSelector
+		want   schema.GroupVersionKind
+		wantOK bool
+	}{
+		{
+			name:   "apiVersion and kind",
+			rs:     policyv1alpha1.ResourceSelector{APIVersion: "apps/v1", Kind: "Deployment"},
+			want:   schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			wantOK: true,
+		},
+		{
+			name:   "single values",
+			rs:     policyv1alpha1.ResourceSelector{APIGroups: []string{""}, Versions: []string{"v1"}, Kinds: []string{"Pod"}},
+			want:   schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			wantOK: true,
+		},
+		{
+			name: "wildcard",
+			rs:   policyv1alpha1.ResourceSelector{APIVersion: "apps/v1", Kinds: []string{"*"}},
+		},
+		{
+			name: "multiple values",
+			rs:   policyv1alpha1.ResourceSelector{APIVersion: "apps/v1", Kinds: []string{"Deployment", "StatefulSet"}},
+		},
+		{
+			name: "conflicting values",
+			rs:   policyv1alpha1.ResourceSelector{APIVersion: "v1", APIGroups: []string{"apps"}, Kind: "Pod"},
+		},
+		{
+			name: "resources",
+			rs:   policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "Pod", Resources: []string{"pods"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SelectedGVK(tt.rs)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("SelectedGVK() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResourceMatches(t *testing.T) {
+	resource := newObjectForSelector()
+	if !ResourceMatches(resource, policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "Pod", Name: "pod1"}) {
+		t.Errorf("ResourceMatches() = false, want true")
+	}
+	if !ResourceMatchSelectors(resource, policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "Service"},
+		policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "Pod"}) {
+		t.Errorf("ResourceMatchSelectors() = false, want true")
+	}
+	// no context to get labels of namespaces
+	if ResourceMatches(resource, policyv1alpha1.ResourceSelector{APIVersion: "v1", Kind: "Pod", NamespaceSelector: &metav1.LabelSelector{}}) {
+		t.Errorf("ResourceMatches() = true, want false for namespaceSelector without context")
+	}
+}
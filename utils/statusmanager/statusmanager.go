@@ -0,0 +1,354 @@
+package statusmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils/util"
+)
+
+// Kinds of policy which have status.
+const (
+	KindOverridePolicy        = "OverridePolicy"
+	KindClusterOverridePolicy = "ClusterOverridePolicy"
+	KindClusterValidatePolicy = "ClusterValidatePolicy"
+	KindValidatePolicy        = "ValidatePolicy"
+)
+
+const (
+	defaultFlushInterval = time.Second * 10
+	// maxNotFoundRetries is the number of flushes a pending status can survive when policy not found,
+	// status recorded on policy creation is flushed before the policy persisted.
+	maxNotFoundRetries = 3
+)
+
+// PolicyKey identifies a policy.
+type PolicyKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (k PolicyKey) String() string {
+	if k.Namespace == "" {
+		return fmt.Sprintf("%s/%s", k.Kind, k.Name)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", k.Kind, k.Namespace, k.Name)
+}
+
+// StatusManager records observed state of policies and writes it to status subresource of policies.
+// All record methods only change status in memory, the status is written to api server when flushed.
+type StatusManager interface {
+	// SetCondition records a condition of the policy.
+	SetCondition(key PolicyKey, condition metav1.Condition)
+	// RecordMatched increases matched count of the policy.
+	RecordMatched(key PolicyKey)
+	// RecordApplied records the policy applied to a resource successfully.
+	RecordApplied(key PolicyKey)
+	// RecordError records the policy got an error when applied to a resource.
+	RecordError(key PolicyKey, err error)
+	// SetAuditSummary records the result of background audit of the policy, it's ignored by override policies.
+	SetAuditSummary(key PolicyKey, summary *policyv1alpha1.AuditSummary)
+	// Flush writes all pending status to api server.
+	Flush(ctx context.Context) error
+	// Start flushes pending status periodically until ctx done.
+	Start(ctx context.Context)
+}
+
+// statusObject is a policy which has PolicyStatus.
+type statusObject interface {
+	client.Object
+	GetPolicyStatus() *policyv1alpha1.PolicyStatus
+}
+
+// validateStatusObject is a validate policy which has ValidatePolicyStatus.
+type validateStatusObject interface {
+	GetValidatePolicyStatus() *policyv1alpha1.ValidatePolicyStatus
+}
+
+type pendingStatus struct {
+	conditions      []metav1.Condition
+	matchedCount    int64
+	lastAppliedTime *metav1.Time
+	lastErrorTime   *metav1.Time
+	lastError       string
+	audit           *policyv1alpha1.AuditSummary
+	notFoundCount   int
+}
+
+type statusManagerImpl struct {
+	client   client.Client
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[PolicyKey]*pendingStatus
+}
+
+// NewStatusManager returns an implement of StatusManager, the status will be flushed at every interval.
+// interval with value '0' means use default flush interval.
+func NewStatusManager(c client.Client, interval time.Duration) StatusManager {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	return &statusManagerImpl{
+		client:   c,
+		interval: interval,
+		pending:  make(map[PolicyKey]*pendingStatus),
+	}
+}
+
+func (s *statusManagerImpl) SetCondition(key PolicyKey, condition metav1.Condition) {
+	s.update(key, func(ps *pendingStatus) {
+		for i := range ps.conditions {
+			if ps.conditions[i].Type == condition.Type {
+				ps.conditions[i] = condition
+				return
+			}
+		}
+
+		ps.conditions = append(ps.conditions, condition)
+	})
+}
+
+func (s *statusManagerImpl) RecordMatched(key PolicyKey) {
+	s.update(key, func(ps *pendingStatus) {
+		ps.matchedCount++
+	})
+}
+
+func (s *statusManagerImpl) RecordApplied(key PolicyKey) {
+	now := metav1.Now()
+	s.update(key, func(ps *pendingStatus) {
+		ps.lastAppliedTime = &now
+	})
+}
+
+func (s *statusManagerImpl) RecordError(key PolicyKey, err error) {
+	if err == nil {
+		return
+	}
+
+	now := metav1.Now()
+	s.update(key, func(ps *pendingStatus) {
+		ps.lastErrorTime = &now
+		ps.lastError = err.Error()
+	})
+}
+
+func (s *statusManagerImpl) SetAuditSummary(key PolicyKey, summary *policyv1alpha1.AuditSummary) {
+	s.update(key, func(ps *pendingStatus) {
+		ps.audit = summary
+	})
+}
+
+func (s *statusManagerImpl) update(key PolicyKey, f func(ps *pendingStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ps, ok := s.pending[key]
+	if !ok {
+		ps = &pendingStatus{}
+		s.pending[key] = ps
+	}
+
+	f(ps)
+}
+
+func (s *statusManagerImpl) Start(ctx context.Context) {
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Flush(ctx); err != nil {
+			klog.ErrorS(err, "flush policy status failed")
+		}
+	}, s.interval)
+}
+
+func (s *statusManagerImpl) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[PolicyKey]*pendingStatus)
+	s.mu.Unlock()
+
+	errs := util.NewErrorSet()
+	for key, ps := range pending {
+		err := s.flushOne(ctx, key, ps)
+		if err == nil {
+			continue
+		}
+
+		if apierrors.IsNotFound(err) {
+			ps.notFoundCount++
+			if ps.notFoundCount < maxNotFoundRetries {
+				s.restore(key, ps)
+			}
+			continue
+		}
+
+		klog.ErrorS(err, "update policy status failed", "policy", key.String())
+		s.restore(key, ps)
+		errs = append(errs, fmt.Errorf("update status of %s got error=%w", key, err))
+	}
+
+	return errs.Err()
+}
+
+// restore puts back a pending status which failed to flush, it merges with status recorded during flushing.
+func (s *statusManagerImpl) restore(key PolicyKey, ps *pendingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur, ok := s.pending[key]
+	if !ok {
+		s.pending[key] = ps
+		return
+	}
+
+	for _, condition := range ps.conditions {
+		if !hasCondition(cur.conditions, condition.Type) {
+			cur.conditions = append(cur.conditions, condition)
+		}
+	}
+	cur.matchedCount += ps.matchedCount
+	if cur.lastAppliedTime == nil {
+		cur.lastAppliedTime = ps.lastAppliedTime
+	}
+	if cur.lastErrorTime == nil {
+		cur.lastErrorTime = ps.lastErrorTime
+		cur.lastError = ps.lastError
+	}
+	if cur.audit == nil {
+		cur.audit = ps.audit
+	}
+	cur.notFoundCount = ps.notFoundCount
+}
+
+func (s *statusManagerImpl) flushOne(ctx context.Context, key PolicyKey, ps *pendingStatus) error {
+	obj, err := newStatusObject(key.Kind)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: key.Name}, obj); err != nil {
+			return err
+		}
+
+		mergeStatus(obj.GetPolicyStatus(), ps, obj.GetGeneration())
+		if vs, ok := obj.(validateStatusObject); ok && ps.audit != nil {
+			vs.GetValidatePolicyStatus().Audit = ps.audit
+		}
+		return s.client.Status().Update(ctx, obj)
+	})
+}
+
+// mergeStatus merges pending status to status of policy and updates ready condition.
+func mergeStatus(status *policyv1alpha1.PolicyStatus, ps *pendingStatus, generation int64) {
+	status.ObservedGeneration = generation
+	status.MatchedCount += ps.matchedCount
+	if ps.lastAppliedTime != nil {
+		status.LastAppliedTime = ps.lastAppliedTime
+	}
+	if ps.lastErrorTime != nil {
+		status.LastErrorTime = ps.lastErrorTime
+		status.LastErrorMessage = ps.lastError
+	}
+	for _, condition := range ps.conditions {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+
+	ready := metav1.Condition{
+		Type:               policyv1alpha1.PolicyConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             policyv1alpha1.PolicyReasonReady,
+		ObservedGeneration: generation,
+	}
+	for _, condition := range status.Conditions {
+		if condition.Type == policyv1alpha1.PolicyConditionReady || condition.Status != metav1.ConditionFalse {
+			continue
+		}
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = condition.Reason
+		ready.Message = condition.Message
+		break
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+}
+
+// NewCondition returns a condition with True status and trueReason if err is nil,
+// otherwise returns a condition with False status, falseReason and err as message.
+func NewCondition(conditionType, trueReason, falseReason string, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  falseReason,
+			Message: err.Error(),
+		}
+	}
+
+	return metav1.Condition{
+		Type:   conditionType,
+		Status: metav1.ConditionTrue,
+		Reason: trueReason,
+	}
+}
+
+func hasCondition(conditions []metav1.Condition, conditionType string) bool {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newStatusObject(kind string) (statusObject, error) {
+	switch kind {
+	case KindOverridePolicy:
+		return &policyv1alpha1.OverridePolicy{}, nil
+	case KindClusterOverridePolicy:
+		return &policyv1alpha1.ClusterOverridePolicy{}, nil
+	case KindClusterValidatePolicy:
+		return &policyv1alpha1.ClusterValidatePolicy{}, nil
+	case KindValidatePolicy:
+		return &policyv1alpha1.ValidatePolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown policy kind(%v)", kind)
+	}
+}
+
+type nopStatusManager struct{}
+
+// NewNopStatusManager returns a StatusManager which drops all status.
+func NewNopStatusManager() StatusManager {
+	return nopStatusManager{}
+}
+
+func (nopStatusManager) SetCondition(PolicyKey, metav1.Condition) {}
+
+func (nopStatusManager) RecordMatched(PolicyKey) {}
+
+func (nopStatusManager) RecordApplied(PolicyKey) {}
+
+func (nopStatusManager) RecordError(PolicyKey, error) {}
+
+func (nopStatusManager) SetAuditSummary(PolicyKey, *policyv1alpha1.AuditSummary) {}
+
+func (nopStatusManager) Flush(context.Context) error { return nil }
+
+func (nopStatusManager) Start(context.Context) {}
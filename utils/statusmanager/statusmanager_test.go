@@ -0,0 +1,94 @@
+package statusmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+func TestStatusManagerImpl_Flush(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := policyv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add to scheme err=%v", err)
+	}
+
+	op := &policyv1alpha1.OverridePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  metav1.NamespaceDefault,
+			Name:       "op",
+			Generation: 2,
+		},
+	}
+	cvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cvp",
+			Generation: 1,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(op, cvp).Build()
+	sm := NewStatusManager(c, 0)
+
+	opKey := PolicyKey{Kind: KindOverridePolicy, Namespace: metav1.NamespaceDefault, Name: "op"}
+	cvpKey := PolicyKey{Kind: KindClusterValidatePolicy, Name: "cvp"}
+	missingKey := PolicyKey{Kind: KindClusterOverridePolicy, Name: "missing"}
+
+	sm.SetCondition(opKey, NewCondition(policyv1alpha1.PolicyConditionCueCompiled,
+		policyv1alpha1.PolicyReasonCompiled, policyv1alpha1.PolicyReasonCompileFailed, nil))
+	sm.RecordMatched(opKey)
+	sm.RecordMatched(opKey)
+	sm.RecordApplied(opKey)
+	sm.SetCondition(cvpKey, NewCondition(policyv1alpha1.PolicyConditionTokenReady,
+		policyv1alpha1.PolicyReasonTokenFetched, policyv1alpha1.PolicyReasonTokenFetchFailed, errors.New("unauthorized")))
+	sm.RecordError(cvpKey, errors.New("cue error"))
+	sm.SetAuditSummary(cvpKey, &policyv1alpha1.AuditSummary{LastAuditTime: metav1.Now(), PassCount: 1, FailCount: 2})
+	sm.RecordMatched(missingKey)
+
+	if err := sm.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() err=%v", err)
+	}
+
+	gotOp := &policyv1alpha1.OverridePolicy{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(op), gotOp); err != nil {
+		t.Fatalf("get op err=%v", err)
+	}
+	if gotOp.Status.ObservedGeneration != 2 || gotOp.Status.MatchedCount != 2 || gotOp.Status.LastAppliedTime == nil {
+		t.Errorf("unexpected op status=%+v", gotOp.Status)
+	}
+	if !meta.IsStatusConditionTrue(gotOp.Status.Conditions, policyv1alpha1.PolicyConditionReady) ||
+		!meta.IsStatusConditionTrue(gotOp.Status.Conditions, policyv1alpha1.PolicyConditionCueCompiled) {
+		t.Errorf("unexpected op conditions=%+v", gotOp.Status.Conditions)
+	}
+
+	gotCvp := &policyv1alpha1.ClusterValidatePolicy{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(cvp), gotCvp); err != nil {
+		t.Fatalf("get cvp err=%v", err)
+	}
+	if gotCvp.Status.LastErrorMessage != "cue error" || gotCvp.Status.LastErrorTime == nil ||
+		gotCvp.Status.Audit == nil || gotCvp.Status.Audit.PassCount != 1 || gotCvp.Status.Audit.FailCount != 2 {
+		t.Errorf("unexpected cvp status=%+v", gotCvp.Status)
+	}
+	ready := meta.FindStatusCondition(gotCvp.Status.Conditions, policyv1alpha1.PolicyConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != policyv1alpha1.PolicyReasonTokenFetchFailed {
+		t.Errorf("unexpected cvp ready condition=%+v", ready)
+	}
+
+	// second flush only adds new matched count
+	sm.RecordMatched(opKey)
+	if err := sm.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() err=%v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(op), gotOp); err != nil {
+		t.Fatalf("get op err=%v", err)
+	}
+	if gotOp.Status.MatchedCount != 3 {
+		t.Errorf("MatchedCount = %v, want 3", gotOp.Status.MatchedCount)
+	}
+}
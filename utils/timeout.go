@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PolicyTimeoutError means applying a policy is cut off since it took longer than the timeoutSeconds of the policy.
+type PolicyTimeoutError struct {
+	// Policy is the name of cluster scoped policy or namespace/name of namespaced policy.
+	Policy string
+	// Timeout is the timeout of the policy.
+	Timeout time.Duration
+	// Err is the error returned when the policy is cut off.
+	Err error
+}
+
+func (e *PolicyTimeoutError) Error() string {
+	return fmt.Sprintf("policy %s timed out after %v: %v", e.Policy, e.Timeout, e.Err)
+}
+
+func (e *PolicyTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// PolicyTimeout returns the duration of timeoutSeconds of a policy, zero means no timeout.
+func PolicyTimeout(timeoutSeconds *int32) time.Duration {
+	if timeoutSeconds == nil || *timeoutSeconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(*timeoutSeconds) * time.Second
+}
+
+// NewPolicyTimeoutError returns a PolicyTimeoutError wrapping err if the deadline of ctx is exceeded,
+// otherwise err is returned as it is.
+func NewPolicyTimeoutError(ctx context.Context, policy string, timeout time.Duration, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return &PolicyTimeoutError{
+		Policy:  policy,
+		Timeout: timeout,
+		Err:     err,
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPolicyTimeoutError(t *testing.T) {
+	err := errors.New("failed")
+	if got := NewPolicyTimeoutError(context.Background(), "policy", time.Second, err); got != err {
+		t.Errorf("NewPolicyTimeoutError() = %v, want the original error", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+
+	got := NewPolicyTimeoutError(ctx, "policy", time.Second, ctx.Err())
+	var timeoutErr *PolicyTimeoutError
+	if !errors.As(got, &timeoutErr) || timeoutErr.Policy != "policy" || timeoutErr.Timeout != time.Second {
+		t.Errorf("NewPolicyTimeoutError() = %v, want PolicyTimeoutError", got)
+	}
+	if !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("NewPolicyTimeoutError() = %v, should wrap the original error", got)
+	}
+
+	if got := NewPolicyTimeoutError(ctx, "policy", time.Second, nil); got != nil {
+		t.Errorf("NewPolicyTimeoutError() = %v, want nil", got)
+	}
+}
+
+func TestPolicyTimeout(t *testing.T) {
+	var zero, ten int32 = 0, 10
+	if got := PolicyTimeout(nil); got != 0 {
+		t.Errorf("PolicyTimeout(nil) = %v, want 0", got)
+	}
+	if got := PolicyTimeout(&zero); got != 0 {
+		t.Errorf("PolicyTimeout(0) = %v, want 0", got)
+	}
+	if got := PolicyTimeout(&ten); got != 10*time.Second {
+		t.Errorf("PolicyTimeout(10) = %v, want 10s", got)
+	}
+}
@@ -0,0 +1,178 @@
+package tokenmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+// JSONTokenOptions defines how to request tokens and read them from JSON responses.
+type JSONTokenOptions struct {
+	// Method is the http method to request tokens, defaults to POST.
+	Method string
+	// TokenPath is the JSONPath of the token in responses.
+	TokenPath string
+	// ExpiryPath is the JSONPath of the expiry in responses, empty means the default expire duration.
+	ExpiryPath string
+	// ExpiryFormat is the format of the expiry, defaults to unix milliseconds.
+	ExpiryFormat policyv1alpha1.TokenExpiryFormat
+}
+
+type jsonTokenGenerator struct {
+	id                    string
+	authURL               string
+	credentials           Credentials
+	opts                  JSONTokenOptions
+	defaultExpireDuration time.Duration
+}
+
+// NewJSONTokenGenerator returns a TokenGenerator requesting tokens from authURL with basic auth of credentials,
+// and reading the token and expiry from the response with JSONPaths of opts.
+func NewJSONTokenGenerator(authURL string, credentials Credentials, opts JSONTokenOptions, defaultExpire time.Duration) TokenGenerator {
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	if opts.ExpiryFormat == "" {
+		opts.ExpiryFormat = policyv1alpha1.TokenExpiryUnixMilliseconds
+	}
+
+	return &jsonTokenGenerator{
+		id:                    fmt.Sprintf("json:%s:%s", urlHost(authURL), credentials),
+		authURL:               authURL,
+		credentials:           credentials,
+		opts:                  opts,
+		defaultExpireDuration: defaultExpire,
+	}
+}
+
+func (tg *jsonTokenGenerator) Generate(ctx context.Context) (token string, expireAt time.Time, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(tg.opts.Method), tg.authURL, nil)
+	if err != nil {
+		return
+	}
+	username, password, err := tg.credentials.Get(ctx)
+	if err != nil {
+		err = fmt.Errorf("get credentials got error=%w", err)
+		return
+	}
+	req.SetBasicAuth(username, password)
+
+	bodyBytes, err := requestToken(req)
+	if err != nil {
+		return
+	}
+
+	var body any
+	if err = json.Unmarshal(bodyBytes, &body); err != nil {
+		return
+	}
+
+	v, err := findJSONPath(body, tg.opts.TokenPath)
+	if err != nil {
+		err = fmt.Errorf("find token got error=%w", err)
+		return
+	}
+	if token, _ = v.(string); token == "" {
+		err = fmt.Errorf("token at %s is not a string or empty", tg.opts.TokenPath)
+		return
+	}
+
+	if tg.opts.ExpiryPath != "" {
+		if v, err = findJSONPath(body, tg.opts.ExpiryPath); err != nil {
+			err = fmt.Errorf("find expiry got error=%w", err)
+			return
+		}
+		if expireAt, err = parseExpiry(v, tg.opts.ExpiryFormat); err != nil {
+			return
+		}
+	}
+
+	return token, expireAtOrDefault(expireAt, tg.defaultExpireDuration), nil
+}
+
+// ParseJSONPath parses path like {.data.token}, braces can be omitted.
+func ParseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+
+	jp := jsonpath.New("token")
+	if err := jp.Parse(path); err != nil {
+		return nil, err
+	}
+
+	return jp, nil
+}
+
+// findJSONPath returns the single value at path of data.
+func findJSONPath(data any, path string) (any, error) {
+	jp, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return nil, fmt.Errorf("%s should match exactly one value", path)
+	}
+
+	return results[0][0].Interface(), nil
+}
+
+// parseExpiry converts v in format to the expiry time.
+func parseExpiry(v any, format policyv1alpha1.TokenExpiryFormat) (time.Time, error) {
+	if format == policyv1alpha1.TokenExpiryRFC3339 {
+		s, _ := v.(string)
+		return time.Parse(time.RFC3339, s)
+	}
+
+	var n int64
+	switch value := v.(type) {
+	case float64:
+		n = int64(value)
+	case string:
+		var err error
+		if n, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid expiry %q: %w", value, err)
+		}
+	default:
+		return time.Time{}, fmt.Errorf("invalid expiry %v", v)
+	}
+
+	switch format {
+	case policyv1alpha1.TokenExpiryUnixSeconds:
+		return time.Unix(n, 0), nil
+	case policyv1alpha1.TokenExpiryExpiresInSeconds:
+		return time.Now().Add(time.Duration(n) * time.Second), nil
+	default:
+		return time.UnixMilli(n), nil
+	}
+}
+
+func (tg *jsonTokenGenerator) Equal(t1 TokenGenerator) bool {
+	v, ok := t1.(*jsonTokenGenerator)
+	if !ok {
+		return false
+	}
+
+	return *tg == *v
+}
+
+func (tg *jsonTokenGenerator) ID() string {
+	return tg.id
+}
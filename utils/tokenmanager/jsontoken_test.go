@@ -0,0 +1,117 @@
+package tokenmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+)
+
+func TestJSONTokenGenerator_Generate(t *testing.T) {
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"accessToken":"token","expiresIn":3600,"expiresAt":"` + expireAt.Format(time.RFC3339) + `"}}`))
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name         string
+		opts         JSONTokenOptions
+		wantToken    string
+		wantExpireAt time.Time
+		wantErr      bool
+	}{
+		{
+			name:      "no expiry",
+			opts:      JSONTokenOptions{Method: http.MethodGet, TokenPath: "{.data.accessToken}"},
+			wantToken: "token",
+		},
+		{
+			name: "expires in",
+			opts: JSONTokenOptions{Method: http.MethodGet, TokenPath: ".data.accessToken", ExpiryPath: ".data.expiresIn",
+				ExpiryFormat: policyv1alpha1.TokenExpiryExpiresInSeconds},
+			wantToken:    "token",
+			wantExpireAt: expireAt,
+		},
+		{
+			name: "rfc3339",
+			opts: JSONTokenOptions{Method: http.MethodGet, TokenPath: ".data.accessToken", ExpiryPath: ".data.expiresAt",
+				ExpiryFormat: policyv1alpha1.TokenExpiryRFC3339},
+			wantToken:    "token",
+			wantExpireAt: expireAt,
+		},
+		{
+			name:    "unsupported method",
+			opts:    JSONTokenOptions{TokenPath: ".data.accessToken"},
+			wantErr: true,
+		},
+		{
+			name:    "token not found",
+			opts:    JSONTokenOptions{Method: http.MethodGet, TokenPath: ".data.token"},
+			wantErr: true,
+		},
+		{
+			name:    "token not string",
+			opts:    JSONTokenOptions{Method: http.MethodGet, TokenPath: ".data.expiresIn"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := NewJSONTokenGenerator(s.URL, NewStaticCredentials("user", "pass"), tt.opts, time.Minute)
+			token, gotExpireAt, err := tg.Generate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				t.Errorf("Generate() token = %v, want %v", token, tt.wantToken)
+			}
+			if err != nil {
+				return
+			}
+			if tt.wantExpireAt.IsZero() {
+				tt.wantExpireAt = time.Now().Add(time.Minute)
+			}
+			if d := gotExpireAt.Sub(tt.wantExpireAt); d > 2*time.Second || d < -2*time.Second {
+				t.Errorf("Generate() expireAt = %v, want %v", gotExpireAt, tt.wantExpireAt)
+			}
+		})
+	}
+}
+
+func Test_parseExpiry(t *testing.T) {
+	at := time.Unix(1663744200, 0)
+	tests := []struct {
+		name    string
+		v       any
+		format  policyv1alpha1.TokenExpiryFormat
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "milliseconds", v: float64(1663744200000), format: policyv1alpha1.TokenExpiryUnixMilliseconds, want: at},
+		{name: "seconds", v: float64(1663744200), format: policyv1alpha1.TokenExpiryUnixSeconds, want: at},
+		{name: "seconds in string", v: "1663744200", format: policyv1alpha1.TokenExpiryUnixSeconds, want: at},
+		{name: "rfc3339", v: at.UTC().Format(time.RFC3339), format: policyv1alpha1.TokenExpiryRFC3339, want: at},
+		{name: "invalid", v: "tomorrow", format: policyv1alpha1.TokenExpiryUnixSeconds, wantErr: true},
+		{name: "invalid type", v: true, format: policyv1alpha1.TokenExpiryUnixSeconds, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpiry(tt.v, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExpiry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
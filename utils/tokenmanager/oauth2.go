@@ -0,0 +1,103 @@
+package tokenmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type oauth2TokenGenerator struct {
+	id                    string
+	tokenURL              string
+	credentials           Credentials
+	scopes                []string
+	audience              string
+	defaultExpireDuration time.Duration
+}
+
+// NewOAuth2TokenGenerator returns a TokenGenerator requesting tokens from tokenURL with the OAuth2 client credentials
+// grant, see RFC 6749 4.4. The client id and secret are the username and password of credentials.
+func NewOAuth2TokenGenerator(tokenURL string, credentials Credentials, scopes []string, audience string, defaultExpire time.Duration) TokenGenerator {
+	return &oauth2TokenGenerator{
+		id:                    fmt.Sprintf("oauth2:%s:%s", urlHost(tokenURL), credentials),
+		tokenURL:              tokenURL,
+		credentials:           credentials,
+		scopes:                scopes,
+		audience:              audience,
+		defaultExpireDuration: defaultExpire,
+	}
+}
+
+// oauth2Token is the successful response of token requests, see RFC 6749 5.1.
+type oauth2Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in,omitempty"`
+}
+
+func (tg *oauth2TokenGenerator) Generate(ctx context.Context) (token string, expireAt time.Time, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	clientID, clientSecret, err := tg.credentials.Get(ctx)
+	if err != nil {
+		err = fmt.Errorf("get credentials got error=%w", err)
+		return
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(tg.scopes) > 0 {
+		form.Set("scope", strings.Join(tg.scopes, " "))
+	}
+	if tg.audience != "" {
+		form.Set("audience", tg.audience)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tg.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	bodyBytes, err := requestToken(req)
+	if err != nil {
+		return
+	}
+
+	t := new(oauth2Token)
+	if err = json.Unmarshal(bodyBytes, t); err != nil {
+		return
+	}
+	if t.AccessToken == "" {
+		err = errors.New("no access_token in response")
+		return
+	}
+
+	if t.ExpiresIn > 0 {
+		expireAt = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+
+	return t.AccessToken, expireAtOrDefault(expireAt, tg.defaultExpireDuration), nil
+}
+
+func (tg *oauth2TokenGenerator) Equal(t1 TokenGenerator) bool {
+	v, ok := t1.(*oauth2TokenGenerator)
+	if !ok {
+		return false
+	}
+
+	return reflect.DeepEqual(tg, v)
+}
+
+func (tg *oauth2TokenGenerator) ID() string {
+	return tg.id
+}
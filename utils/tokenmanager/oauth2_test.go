@@ -0,0 +1,81 @@
+package tokenmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOAuth2TokenGenerator_Generate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		if r.PostFormValue("grant_type") != "client_credentials" || r.PostFormValue("scope") != "read write" ||
+			r.PostFormValue("audience") != "api" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_request"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		wantToken   string
+		wantErr     bool
+	}{
+		{
+			name:        "normal",
+			credentials: NewStaticCredentials("client", "secret"),
+			wantToken:   "access",
+		},
+		{
+			name:        "invalid client",
+			credentials: NewStaticCredentials("client", "wrong"),
+			wantErr:     true,
+		},
+		{
+			name:        "credentials unavailable",
+			credentials: errCredentials{},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := NewOAuth2TokenGenerator(s.URL, tt.credentials, []string{"read", "write"}, "api", time.Minute)
+			token, expireAt, err := tg.Generate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				t.Errorf("Generate() token = %v, want %v", token, tt.wantToken)
+			}
+			if err == nil && time.Until(expireAt) < 59*time.Minute {
+				t.Errorf("Generate() expireAt = %v, want expires_in of the response", expireAt)
+			}
+		})
+	}
+}
+
+func TestOAuth2TokenGenerator_Equal(t *testing.T) {
+	tg := NewOAuth2TokenGenerator("https://auth.io/token", NewStaticCredentials("client", "secret"), []string{"read"}, "", 0)
+	if !tg.Equal(NewOAuth2TokenGenerator("https://auth.io/token", NewStaticCredentials("client", "secret"), []string{"read"}, "", 0)) {
+		t.Errorf("Equal() should be true with the same settings")
+	}
+	if tg.Equal(NewOAuth2TokenGenerator("https://auth.io/token", NewStaticCredentials("client", "secret"), []string{"write"}, "", 0)) {
+		t.Errorf("Equal() should be false with different scopes")
+	}
+	if basic := NewTokenGenerator("https://auth.io/token", "client", "secret", 0); tg.Equal(basic) || tg.ID() == basic.ID() {
+		t.Errorf("oauth2 generators should be different from basic ones")
+	}
+}
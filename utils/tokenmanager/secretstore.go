@@ -0,0 +1,212 @@
+package tokenmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	clientretry "k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultTokenStoreName = "kcloudlabs-policy-tokens"
+	defaultLeaseDuration  = 15 * time.Second
+	defaultRenewDeadline  = 10 * time.Second
+	defaultRetryPeriod    = 2 * time.Second
+)
+
+// SecretTokenStoreOptions configures the TokenStore backed by a Secret and a Lease.
+type SecretTokenStoreOptions struct {
+	// Namespace is the namespace of the Secret and the Lease.
+	Namespace string
+	// SecretName is the name of the Secret storing tokens, defaults to kcloudlabs-policy-tokens.
+	SecretName string
+	// LeaseName is the name of the Lease to elect the leader, defaults to kcloudlabs-policy-tokens.
+	LeaseName string
+	// Identity is the identity of this replica in leader election, defaults to the hostname with a random suffix.
+	Identity string
+	// LeaseDuration, RenewDeadline and RetryPeriod are the settings of leader election, see leaderelection.LeaderElectionConfig.
+	// They default to 15s, 10s and 2s.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+type secretTokenStore struct {
+	client     kubernetes.Interface
+	namespace  string
+	secretName string
+	config     leaderelection.LeaderElectionConfig
+	leader     int32
+}
+
+// NewSecretTokenStore returns a TokenStore storing tokens in a Secret, the leader is elected with a Lease
+// until done is closed.
+func NewSecretTokenStore(client kubernetes.Interface, opts SecretTokenStoreOptions, done <-chan struct{}) (TokenStore, error) {
+	if opts.SecretName == "" {
+		opts.SecretName = defaultTokenStoreName
+	}
+	if opts.LeaseName == "" {
+		opts.LeaseName = defaultTokenStoreName
+	}
+	if opts.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		opts.Identity = hostname + "_" + string(uuid.NewUUID())
+	}
+	if opts.LeaseDuration <= 0 {
+		opts.LeaseDuration = defaultLeaseDuration
+	}
+	if opts.RenewDeadline <= 0 {
+		opts.RenewDeadline = defaultRenewDeadline
+	}
+	if opts.RetryPeriod <= 0 {
+		opts.RetryPeriod = defaultRetryPeriod
+	}
+
+	s := &secretTokenStore{
+		client:     client,
+		namespace:  opts.Namespace,
+		secretName: opts.SecretName,
+	}
+	s.config = leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: opts.Namespace, Name: opts.LeaseName},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: opts.Identity},
+		},
+		LeaseDuration:   opts.LeaseDuration,
+		RenewDeadline:   opts.RenewDeadline,
+		RetryPeriod:     opts.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            opts.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(_ context.Context) {
+				klog.InfoS("Started leading token refreshing.", "identity", opts.Identity)
+				atomic.StoreInt32(&s.leader, 1)
+			},
+			OnStoppedLeading: func() {
+				klog.InfoS("Stopped leading token refreshing.", "identity", opts.Identity)
+				atomic.StoreInt32(&s.leader, 0)
+			},
+		},
+	}
+
+	// validate the config before running
+	if _, err := leaderelection.NewLeaderElector(s.config); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-done
+		cancel()
+	}()
+	go wait.UntilWithContext(ctx, s.elect, opts.RetryPeriod)
+
+	return s, nil
+}
+
+// elect runs leader election until the leadership is lost or ctx is done.
+func (s *secretTokenStore) elect(ctx context.Context) {
+	le, err := leaderelection.NewLeaderElector(s.config)
+	if err != nil {
+		klog.ErrorS(err, "Failed to create leader elector.")
+		return
+	}
+
+	le.Run(ctx)
+}
+
+func (s *secretTokenStore) IsLeader() bool {
+	return atomic.LoadInt32(&s.leader) == 1
+}
+
+func (s *secretTokenStore) Get(ctx context.Context, id string) (string, time.Time, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, s.secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", time.Time{}, ErrTokenNotFound
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	b, ok := secret.Data[tokenKey(id)]
+	if !ok {
+		return "", time.Time{}, ErrTokenNotFound
+	}
+
+	t := storedToken{}
+	if err = json.Unmarshal(b, &t); err != nil {
+		return "", time.Time{}, fmt.Errorf("unmarshal stored token got error=%w", err)
+	}
+
+	return t.Token, t.ExpireAt, nil
+}
+
+func (s *secretTokenStore) Set(ctx context.Context, id, token string, expireAt time.Time) error {
+	b, err := json.Marshal(storedToken{Token: token, ExpireAt: expireAt})
+	if err != nil {
+		return err
+	}
+
+	secrets := s.client.CoreV1().Secrets(s.namespace)
+	return clientretry.OnError(clientretry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret, err := secrets.Get(ctx, s.secretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.secretName},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{tokenKey(id): b},
+			}
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		pruneExpiredTokens(secret.Data)
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[tokenKey(id)] = b
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// tokenKey returns the key of token id in the Secret, since ids might contain characters not allowed in keys.
+func tokenKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// pruneExpiredTokens removes expired tokens, e.g. tokens of deleted policies, so the Secret doesn't grow forever.
+func pruneExpiredTokens(data map[string][]byte) {
+	now := time.Now()
+	for key, b := range data {
+		t := storedToken{}
+		if err := json.Unmarshal(b, &t); err != nil || t.ExpireAt.Before(now) {
+			delete(data, key)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tokenmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ServiceAccountTokenOptions is the operator configuration of the projected ServiceAccount token sent to
+// http references with serviceAccountToken auth. It's not configurable in policies, so policy authors
+// can't make the webhook read other files or send tokens of other audiences.
+type ServiceAccountTokenOptions struct {
+	// Path is the path of the projected token file, serviceAccountToken auth is disabled if it's empty.
+	// It should be a token projected for the referred apis rather than the token to access kube-apiserver.
+	Path string
+	// Audience must be in the aud claim of the token if it's not empty.
+	Audience string
+}
+
+// Enabled returns true if the token is configured.
+func (o ServiceAccountTokenOptions) Enabled() bool {
+	return o.Path != ""
+}
+
+type serviceAccountTokenGenerator struct {
+	opts                  ServiceAccountTokenOptions
+	defaultExpireDuration time.Duration
+}
+
+// NewServiceAccountTokenGenerator returns a TokenGenerator reading tokens from the projected ServiceAccount token file
+// of opts. The expiry of tokens is read from the exp claim, and tokens without the audience of opts are rejected.
+func NewServiceAccountTokenGenerator(opts ServiceAccountTokenOptions, defaultExpire time.Duration) TokenGenerator {
+	return &serviceAccountTokenGenerator{
+		opts:                  opts,
+		defaultExpireDuration: defaultExpire,
+	}
+}
+
+func (tg *serviceAccountTokenGenerator) Generate(_ context.Context) (token string, expireAt time.Time, err error) {
+	if !tg.opts.Enabled() {
+		err = errors.New("service account token is not configured")
+		return
+	}
+
+	b, err := os.ReadFile(tg.opts.Path)
+	if err != nil {
+		return
+	}
+
+	token = strings.TrimSpace(string(b))
+	if token == "" {
+		err = fmt.Errorf("token file %s is empty", tg.opts.Path)
+		return
+	}
+
+	claims, err := parseJWTClaims(token)
+	if err != nil {
+		return
+	}
+	if tg.opts.Audience != "" && !claims.Aud.contains(tg.opts.Audience) {
+		err = fmt.Errorf("token file %s is not issued for audience %s", tg.opts.Path, tg.opts.Audience)
+		return
+	}
+
+	if claims.Exp > 0 {
+		expireAt = time.Unix(claims.Exp, 0)
+	}
+
+	return token, expireAtOrDefault(expireAt, tg.defaultExpireDuration), nil
+}
+
+type jwtClaims struct {
+	Aud jwtAudience `json:"aud"`
+	Exp int64       `json:"exp"`
+}
+
+// jwtAudience is the aud claim, which is either a string or an array of strings.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+func (a jwtAudience) contains(audience string) bool {
+	for _, aud := range a {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseJWTClaims returns claims of the JWT token without verifying it.
+func parseJWTClaims(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode JWT payload got error=%w", err)
+	}
+
+	claims := &jwtClaims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("unmarshal JWT claims got error=%w", err)
+	}
+
+	return claims, nil
+}
+
+func (tg *serviceAccountTokenGenerator) Equal(t1 TokenGenerator) bool {
+	v, ok := t1.(*serviceAccountTokenGenerator)
+	if !ok {
+		return false
+	}
+
+	return *tg == *v
+}
+
+func (tg *serviceAccountTokenGenerator) ID() string {
+	return "file:" + tg.opts.Path
+}
@@ -0,0 +1,92 @@
+package tokenmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newJWT(claims string) string {
+	enc := base64.RawURLEncoding
+	return fmt.Sprintf("%s.%s.%s", enc.EncodeToString([]byte(`{"alg":"RS256"}`)), enc.EncodeToString([]byte(claims)), enc.EncodeToString([]byte("sig")))
+}
+
+func TestServiceAccountTokenGenerator_Generate(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name         string
+		content      string
+		audience     string
+		wantExpireAt time.Time
+		wantErr      bool
+	}{
+		{
+			name:         "projected token",
+			content:      newJWT(fmt.Sprintf(`{"aud":["api"],"exp":%d}`, exp.Unix())) + "\n",
+			audience:     "api",
+			wantExpireAt: exp,
+		},
+		{
+			name:     "no exp claim",
+			content:  newJWT(`{"aud":"api"}`),
+			audience: "api",
+		},
+		{
+			name:    "no audience required",
+			content: newJWT(`{"aud":["https://kubernetes.default.svc"]}`),
+		},
+		{
+			name:     "other audience",
+			content:  newJWT(`{"aud":["https://kubernetes.default.svc"]}`),
+			audience: "api",
+			wantErr:  true,
+		},
+		{
+			name:    "not jwt",
+			content: "token",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "token")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			tg := NewServiceAccountTokenGenerator(ServiceAccountTokenOptions{Path: path, Audience: tt.audience}, time.Minute)
+			token, expireAt, err := tg.Generate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if token != strings.TrimSpace(tt.content) {
+				t.Errorf("Generate() token = %q, want the trimmed content", token)
+			}
+			if !tt.wantExpireAt.IsZero() && !expireAt.Equal(tt.wantExpireAt) {
+				t.Errorf("Generate() expireAt = %v, want %v", expireAt, tt.wantExpireAt)
+			}
+			if tt.wantExpireAt.IsZero() && time.Until(expireAt) > time.Minute {
+				t.Errorf("Generate() expireAt = %v, want the default expire duration", expireAt)
+			}
+		})
+	}
+
+	if _, _, err := NewServiceAccountTokenGenerator(ServiceAccountTokenOptions{Path: filepath.Join(t.TempDir(), "none")}, 0).Generate(context.Background()); err == nil {
+		t.Errorf("Generate() should fail if the token file does not exist")
+	}
+	if _, _, err := NewServiceAccountTokenGenerator(ServiceAccountTokenOptions{}, 0).Generate(context.Background()); err == nil {
+		t.Errorf("Generate() should fail if the token file is not configured")
+	}
+}
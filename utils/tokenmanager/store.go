@@ -0,0 +1,64 @@
+package tokenmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound means there's no token of the id in the store.
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore stores tokens shared by token managers of all replicas. Only the leader refreshes tokens
+// and writes them to the store, the others read tokens from the store instead of requesting auth apis.
+type TokenStore interface {
+	// Get returns the token of id, ErrTokenNotFound is returned if it's not stored.
+	Get(ctx context.Context, id string) (token string, expireAt time.Time, err error)
+	// Set stores the token of id.
+	Set(ctx context.Context, id, token string, expireAt time.Time) error
+	// IsLeader returns true if tokens should be refreshed by this replica.
+	IsLeader() bool
+}
+
+type memoryTokenStore struct {
+	lock   sync.RWMutex
+	tokens map[string]storedToken
+}
+
+type storedToken struct {
+	Token    string    `json:"token"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// NewMemoryTokenStore returns a TokenStore keeping tokens in memory, which is always the leader
+// since tokens are not shared with other replicas.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{
+		tokens: make(map[string]storedToken),
+	}
+}
+
+func (s *memoryTokenStore) Get(_ context.Context, id string) (string, time.Time, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	t, ok := s.tokens[id]
+	if !ok {
+		return "", time.Time{}, ErrTokenNotFound
+	}
+
+	return t.Token, t.ExpireAt, nil
+}
+
+func (s *memoryTokenStore) Set(_ context.Context, id, token string, expireAt time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tokens[id] = storedToken{Token: token, ExpireAt: expireAt}
+	return nil
+}
+
+func (s *memoryTokenStore) IsLeader() bool {
+	return true
+}
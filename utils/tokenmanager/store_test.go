@@ -0,0 +1,120 @@
+package tokenmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTokenStores(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	secretStore, err := NewSecretTokenStore(fake.NewSimpleClientset(), SecretTokenStoreOptions{Namespace: "ns", Identity: "a"}, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]TokenStore{
+		"memory": NewMemoryTokenStore(),
+		"secret": secretStore,
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, _, err := s.Get(ctx, "auth.io:user"); !errors.Is(err, ErrTokenNotFound) {
+				t.Fatalf("Get() err = %v, want not found", err)
+			}
+
+			expireAt := time.Now().Add(time.Hour).Truncate(time.Second)
+			for _, token := range []string{"t1", "t2"} {
+				if err := s.Set(ctx, "auth.io:user", token, expireAt); err != nil {
+					t.Fatalf("Set() err = %v", err)
+				}
+				got, gotExpireAt, err := s.Get(ctx, "auth.io:user")
+				if err != nil || got != token || !gotExpireAt.Equal(expireAt) {
+					t.Errorf("Get() = %v, %v, %v, want %v, %v", got, gotExpireAt, err, token, expireAt)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretTokenStore_PruneExpired(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	client := fake.NewSimpleClientset()
+	s, err := NewSecretTokenStore(client, SecretTokenStoreOptions{Namespace: "ns", SecretName: "tokens", Identity: "a"}, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	_ = s.Set(ctx, "expired", "t1", time.Now().Add(-time.Minute))
+	_ = s.Set(ctx, "valid", "t2", time.Now().Add(time.Hour))
+
+	secret, err := client.CoreV1().Secrets("ns").Get(ctx, "tokens", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := secret.Data[tokenKey("expired")]; ok || len(secret.Data) != 1 {
+		t.Errorf("Set() should prune expired tokens, got keys %d", len(secret.Data))
+	}
+}
+
+func TestSecretTokenStore_IsLeader(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	opts := SecretTokenStoreOptions{
+		Namespace:     "ns",
+		LeaseDuration: time.Second,
+		RenewDeadline: 500 * time.Millisecond,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+
+	newStore := func(identity string) (TokenStore, chan struct{}) {
+		done := make(chan struct{})
+		o := opts
+		o.Identity = identity
+		s, err := NewSecretTokenStore(client, o, done)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s, done
+	}
+
+	a, doneA := newStore("a")
+	if !eventually(a.IsLeader) {
+		t.Fatalf("the only replica should be the leader")
+	}
+
+	b, doneB := newStore("b")
+	defer close(doneB)
+	time.Sleep(300 * time.Millisecond)
+	if b.IsLeader() {
+		t.Errorf("there should be only one leader")
+	}
+
+	close(doneA)
+	if !eventually(b.IsLeader) {
+		t.Errorf("another replica should be the leader once the leader stopped")
+	}
+	if a.IsLeader() {
+		t.Errorf("the stopped replica should not be the leader")
+	}
+}
+
+func eventually(condition func() bool) bool {
+	for i := 0; i < 50; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return false
+}
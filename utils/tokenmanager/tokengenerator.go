@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/k-cloud-labs/pkg/utils/httpclient"
 )
 
 type TokenGenerator interface {
@@ -19,28 +21,95 @@ type TokenGenerator interface {
 	ID() string
 }
 
+// Credentials provides the username and password to request tokens with.
+// Implementations must be comparable, generators with equal credentials are considered the same.
+type Credentials interface {
+	// Get returns the username and password, it's called before every request of tokens
+	// so the latest credentials are used.
+	Get(ctx context.Context) (username, password string, err error)
+	// String identifies the credentials without revealing secrets.
+	String() string
+}
+
+// VersionedCredentials is implemented by Credentials which change without changing policies,
+// e.g. ones read from Secrets. Tokens are refreshed once the version changes.
+type VersionedCredentials interface {
+	Credentials
+	// Version returns the current version of the credentials, e.g. resourceVersions of Secrets.
+	Version(ctx context.Context) (string, error)
+}
+
+// credentialsVersion returns the version of credentials of tg,
+// ok is false if credentials of tg never change.
+func credentialsVersion(ctx context.Context, tg TokenGenerator) (version string, ok bool, err error) {
+	var credentials Credentials
+	switch g := tg.(type) {
+	case *tokenGeneratorImpl:
+		credentials = g.credentials
+	case *oauth2TokenGenerator:
+		credentials = g.credentials
+	case *jsonTokenGenerator:
+		credentials = g.credentials
+	}
+
+	vc, ok := credentials.(VersionedCredentials)
+	if !ok {
+		return "", false, nil
+	}
+
+	version, err = vc.Version(ctx)
+	return version, true, err
+}
+
+type staticCredentials struct {
+	username string
+	password string
+}
+
+// NewStaticCredentials returns Credentials with constant username and password.
+func NewStaticCredentials(username, password string) Credentials {
+	return staticCredentials{username: username, password: password}
+}
+
+func (c staticCredentials) Get(_ context.Context) (string, string, error) {
+	return c.username, c.password, nil
+}
+
+func (c staticCredentials) String() string {
+	return c.username
+}
+
 type tokenGeneratorImpl struct {
 	id                    string
 	authUrl               string
-	username              string
-	password              string
+	credentials           Credentials
 	defaultExpireDuration time.Duration
 }
 
 var (
 	defaultExpireDuration = time.Minute * 5 // 5min as default token expire time.
+
+	// defaultClient is the client to request tokens, token manager retries failed requests itself.
+	defaultClient = httpclient.New(httpclient.Options{
+		Name:    "token",
+		Timeout: time.Second,
+	})
 )
 
 func NewTokenGenerator(authUrl, username, password string, defaultExpire time.Duration) TokenGenerator {
+	return NewTokenGeneratorWithCredentials(authUrl, NewStaticCredentials(username, password), defaultExpire)
+}
+
+// NewTokenGeneratorWithCredentials returns a TokenGenerator requesting tokens from authUrl with credentials.
+func NewTokenGeneratorWithCredentials(authUrl string, credentials Credentials, defaultExpire time.Duration) TokenGenerator {
 	tg := &tokenGeneratorImpl{
 		authUrl:               authUrl,
-		username:              username,
-		password:              password,
+		credentials:           credentials,
 		defaultExpireDuration: defaultExpire,
 	}
 
 	// parse url
-	tg.id = fmt.Sprintf("%s:%s", tg.getHost(), username)
+	tg.id = fmt.Sprintf("%s:%s", tg.getHost(), credentials)
 	return tg
 }
 
@@ -53,33 +122,23 @@ func (tg *tokenGeneratorImpl) Generate(ctx context.Context) (token string, expir
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	// set timeout
-	ctx2, cancel := context.WithTimeout(ctx, time.Second)
-	defer cancel()
 
-	req, err := http.NewRequest(http.MethodPost, tg.authUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tg.authUrl, nil)
 	if err != nil {
 		return
 	}
-	req = req.WithContext(ctx2)
-	req.SetBasicAuth(tg.username, tg.password)
-
-	resp, err := http.DefaultClient.Do(req)
+	username, password, err := tg.credentials.Get(ctx)
 	if err != nil {
+		err = fmt.Errorf("get credentials got error=%w", err)
 		return
 	}
-	defer noErr(resp.Body.Close)
+	req.SetBasicAuth(username, password)
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := requestToken(req)
 	if err != nil {
 		return
 	}
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		err = fmt.Errorf("request for get token failed with statuscode=%v, body=%v", resp.StatusCode, string(bodyBytes))
-		return
-	}
-
 	t := new(Token)
 	if err = json.Unmarshal(bodyBytes, t); err != nil {
 		return
@@ -87,16 +146,44 @@ func (tg *tokenGeneratorImpl) Generate(ctx context.Context) (token string, expir
 
 	if t.ExpireAt > 0 {
 		expireAt = time.Unix(t.ExpireAt/1000, 0)
-		return t.Token, expireAt, nil
+	}
+
+	return t.Token, expireAtOrDefault(expireAt, tg.defaultExpireDuration), nil
+}
+
+// requestToken sends req and returns the response body if it succeeded.
+func requestToken(req *http.Request) ([]byte, error) {
+	resp, err := defaultClient.Do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer noErr(resp.Body.Close)
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("request for get token failed with statuscode=%v, body=%v", resp.StatusCode, string(bodyBytes))
+	}
+
+	return bodyBytes, nil
+}
+
+// expireAtOrDefault returns expireAt if it's set, otherwise now plus defaultExpire or the global default one.
+func expireAtOrDefault(expireAt time.Time, defaultExpire time.Duration) time.Time {
+	if !expireAt.IsZero() {
+		return expireAt
 	}
 
 	// use default expire duration
-	if tg.defaultExpireDuration > 0 {
-		return t.Token, time.Now().Add(tg.defaultExpireDuration), nil
+	if defaultExpire > 0 {
+		return time.Now().Add(defaultExpire)
 	}
 
 	// if user neo set own default expire duration
-	return t.Token, time.Now().Add(defaultExpireDuration), nil
+	return time.Now().Add(defaultExpireDuration)
 }
 
 func (tg *tokenGeneratorImpl) Equal(t1 TokenGenerator) bool {
@@ -105,7 +192,7 @@ func (tg *tokenGeneratorImpl) Equal(t1 TokenGenerator) bool {
 		return false
 	}
 
-	return tg.authUrl == v.authUrl && tg.username == v.username && tg.password == v.password
+	return tg.authUrl == v.authUrl && tg.credentials == v.credentials
 }
 
 func noErr(f func() error) {
@@ -117,9 +204,14 @@ func (tg *tokenGeneratorImpl) ID() string {
 }
 
 func (tg *tokenGeneratorImpl) getHost() string {
-	u, err := url.ParseRequestURI(tg.authUrl)
+	return urlHost(tg.authUrl)
+}
+
+// urlHost returns the host of rawURL, or rawURL itself if it's invalid.
+func urlHost(rawURL string) string {
+	u, err := url.ParseRequestURI(rawURL)
 	if err != nil {
-		return tg.authUrl
+		return rawURL
 	}
 
 	return u.Host
@@ -3,6 +3,7 @@ package tokenmanager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -211,3 +212,31 @@ func Test_tokenGeneratorImpl_Equal(t *testing.T) {
 		})
 	}
 }
+
+type errCredentials struct{}
+
+func (errCredentials) Get(_ context.Context) (string, string, error) {
+	return "", "", errors.New("secret not found")
+}
+
+func (errCredentials) String() string {
+	return "err"
+}
+
+func TestNewTokenGeneratorWithCredentials(t *testing.T) {
+	tg := NewTokenGeneratorWithCredentials("http://127.0.0.1:8090/api/v1/auth", errCredentials{}, time.Hour)
+	if _, _, err := tg.Generate(context.Background()); err == nil {
+		t.Errorf("Generate() should fail if credentials are not available")
+	}
+	if tg.ID() != "127.0.0.1:8090:err" {
+		t.Errorf("ID() = %v, want host and credentials", tg.ID())
+	}
+
+	static := NewTokenGenerator("http://127.0.0.1:8090/api/v1/auth", "user", "pass", time.Hour)
+	if !static.Equal(NewTokenGeneratorWithCredentials("http://127.0.0.1:8090/api/v1/auth", NewStaticCredentials("user", "pass"), time.Hour)) {
+		t.Errorf("Equal() should be true with equal credentials")
+	}
+	if static.Equal(NewTokenGenerator("http://127.0.0.1:8090/api/v1/auth", "user", "other", time.Hour)) || static.Equal(tg) {
+		t.Errorf("Equal() should be false with different credentials")
+	}
+}
@@ -3,6 +3,7 @@ package tokenmanager
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -15,6 +16,11 @@ type IdentifiedCallback interface {
 	Callback(token string, expireAt time.Time) error
 }
 
+// FailureCallback is an optional interface of IdentifiedCallback, it will be notified when refresh token failed.
+type FailureCallback interface {
+	OnFailure(err error)
+}
+
 // TokenManager provides cache and maintain token ability.
 type TokenManager interface {
 	// AddToken add new token to manager
@@ -28,6 +34,7 @@ type TokenManager interface {
 type tokenManagerImpl struct {
 	tokenMap map[string]*tokenMaintainer
 	mu       *sync.RWMutex
+	store    TokenStore
 }
 
 func (t *tokenManagerImpl) AddToken(generator TokenGenerator, ic IdentifiedCallback) {
@@ -44,6 +51,7 @@ func (t *tokenManagerImpl) AddToken(generator TokenGenerator, ic IdentifiedCallb
 			callbackMap: sync.Map{},
 			stopChan:    make(chan struct{}, 1),
 			valueLock:   new(sync.RWMutex),
+			store:       t.store,
 		}
 	}
 
@@ -51,7 +59,7 @@ func (t *tokenManagerImpl) AddToken(generator TokenGenerator, ic IdentifiedCallb
 	t.tokenMap[generator.ID()] = info
 	if !ok {
 		go info.daemon()
-	} else {
+	} else if info.isLeader() {
 		// callback immediately
 		go func() {
 			_ = info.callback(ic)
@@ -93,9 +101,22 @@ func (t *tokenManagerImpl) Stop() {
 
 // NewTokenManager return an implement of TokenManager.
 func NewTokenManager() TokenManager {
+	return NewTokenManagerWithStore(nil)
+}
+
+// NewTokenManagerWithStore returns a TokenManager sharing tokens with other replicas through store,
+// tokens are only refreshed by the leader and read from store by the others. Callbacks are only called by
+// the leader since they write tokens into policies, the others keep tokens in memory.
+// If store is nil, tokens are kept in memory.
+func NewTokenManagerWithStore(store TokenStore) TokenManager {
+	if store == nil {
+		store = NewMemoryTokenStore()
+	}
+
 	return &tokenManagerImpl{
 		tokenMap: make(map[string]*tokenMaintainer),
 		mu:       new(sync.RWMutex),
+		store:    store,
 	}
 }
 
@@ -103,6 +124,12 @@ type tokenMaintainer struct {
 	name      string
 	generator TokenGenerator
 	stopChan  chan struct{}
+	// store shares tokens with other replicas, nil means tokens are not shared
+	store TokenStore
+	// calledBack is true if all callbacks succeeded with the current token
+	calledBack bool
+	// credentialsVersion is the version of credentials the current token is generated with
+	credentialsVersion string
 
 	valueLock *sync.RWMutex
 	token     string
@@ -147,6 +174,16 @@ func (t *tokenMaintainer) callbackAll() error {
 	return eg.Wait()
 }
 
+func (t *tokenMaintainer) failureCallbackAll(err error) {
+	t.callbackMap.Range(func(_, value any) bool {
+		if fc, ok := value.(FailureCallback); ok {
+			fc.OnFailure(err)
+		}
+
+		return true
+	})
+}
+
 // return true if callbackAll map is empty
 func (t *tokenMaintainer) removeCallback(ic IdentifiedCallback) bool {
 	t.callbackMap.Delete(ic.ID())
@@ -162,21 +199,90 @@ func (t *tokenMaintainer) stop() {
 	t.stopChan <- struct{}{}
 }
 
-func (t *tokenMaintainer) refreshToken() error {
+// minTokenValidDuration is the minimal remaining lifetime of fetched tokens.
+const minTokenValidDuration = time.Minute
+
+// errWaitingForLeader means the token is not refreshed by the leader yet, e.g. the leader is not elected.
+var errWaitingForLeader = errors.New("waiting for the leader to refresh the token")
+
+// isLeader returns true if tokens are generated and callbacks are called by this replica.
+func (t *tokenMaintainer) isLeader() bool {
+	return t.store == nil || t.store.IsLeader()
+}
+
+// refreshToken refreshes the token and returns true if it's changed.
+func (t *tokenMaintainer) refreshToken() (bool, error) {
 	// fetch token first
-	token, expireAt, err := t.generator.Generate(context.Background())
+	token, expireAt, err := t.fetchToken(context.Background())
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	_, _, fetchedAt := t.getValues()
-	// token must be at least valid for one minute
-	if expireAt.Before(fetchedAt) || expireAt.Sub(fetchedAt).Seconds() < 60 {
-		return errors.New("token valid duration too short or expired already, please extend the valid time")
+	// token must be at least valid for one minute from now
+	if time.Until(expireAt) < minTokenValidDuration {
+		return false, errors.New("token valid duration too short or expired already, please extend the valid time")
 	}
 
+	oldToken, oldExpireAt, _ := t.getValues()
 	t.setValues(token, expireAt)
-	return nil
+	return token != oldToken || !expireAt.Equal(oldExpireAt), nil
+}
+
+// fetchToken generates a new token if this replica is the leader, otherwise reads the token refreshed by the leader.
+func (t *tokenMaintainer) fetchToken(ctx context.Context) (string, time.Time, error) {
+	if !t.isLeader() {
+		token, expireAt, err := t.store.Get(ctx, t.name)
+		if errors.Is(err, ErrTokenNotFound) {
+			return "", time.Time{}, errWaitingForLeader
+		}
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("get token from store got error=%w", err)
+		}
+		// the leader rotates tokens before they're too short to use
+		if time.Until(expireAt) < minTokenValidDuration {
+			return "", time.Time{}, fmt.Errorf("token in store is expiring: %w", errWaitingForLeader)
+		}
+
+		return token, expireAt, nil
+	}
+
+	version, _, err := credentialsVersion(ctx, t.generator)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get version of credentials got error=%w", err)
+	}
+
+	token, expireAt, err := t.generator.Generate(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	t.credentialsVersion = version
+
+	if t.store != nil {
+		if err := t.store.Set(ctx, t.name, token, expireAt); err != nil {
+			// other replicas will retry until the token is stored
+			klog.ErrorS(err, "store token got error", "id", t.name)
+		}
+	}
+
+	return token, expireAt, nil
+}
+
+// credentialsChanged returns true if credentials changed since the current token is generated by this replica.
+func (t *tokenMaintainer) credentialsChanged() bool {
+	if !t.isLeader() {
+		return false
+	}
+
+	version, ok, err := credentialsVersion(context.Background(), t.generator)
+	if !ok {
+		return false
+	}
+	if err != nil {
+		klog.ErrorS(err, "get version of credentials got error", "id", t.name)
+		return false
+	}
+
+	return version != t.credentialsVersion
 }
 
 func (t *tokenMaintainer) setValues(token string, expireAt time.Time) {
@@ -195,13 +301,29 @@ func (t *tokenMaintainer) getValues() (token string, expireAt, fetchAt time.Time
 	return t.token, t.expireAt, t.fetchedAt
 }
 
+// refreshAndCallback refreshes the token and calls callbacks if this replica is the leader,
+// failures are not reported by followers since policies are only updated by the leader.
 func (t *tokenMaintainer) refreshAndCallback() error {
-	if err := t.refreshToken(); err != nil {
+	changed, err := t.refreshToken()
+	if !t.isLeader() {
+		// keep the token in memory, callbacks are called by the leader
+		t.calledBack = false
+		return err
+	}
+	if err != nil {
 		klog.ErrorS(err, "refresh token got error", "id", t.generator.ID())
+		t.failureCallbackAll(err)
 		return err
 	}
 
-	return t.callbackAll()
+	// no need to update policies again if the token is not changed
+	if !changed && t.calledBack {
+		return nil
+	}
+
+	err = t.callbackAll()
+	t.calledBack = err == nil
+	return err
 }
 
 func (t *tokenMaintainer) daemon() {
@@ -217,6 +339,11 @@ func (t *tokenMaintainer) daemon() {
 		if err == nil {
 			break
 		}
+		if errors.Is(err, errWaitingForLeader) {
+			klog.V(4).InfoS("waiting for the leader to refresh token", "id", t.generator.ID())
+			time.Sleep(time.Second)
+			continue
+		}
 		klog.ErrorS(err, "refresh token got error", "id", t.generator.ID())
 		time.Sleep(time.Millisecond * 100)
 	}
@@ -242,11 +369,19 @@ func (t *tokenMaintainer) daemon() {
 				continue
 			}
 		case <-heartBeat.C:
+			// refresh token with the latest credentials once they changed, e.g. Secrets are updated
+			if t.credentialsChanged() {
+				klog.V(4).InfoS("credentials changed, refresh token", "id", t.name)
+				refreshFailed = true
+			}
+
 			// refreshFailed default value is true, so it will refresh token at first here
 			if refreshFailed {
 				// will retry 3 times inside
 				if err := t.refreshAndCallback(); err != nil {
-					klog.ErrorS(err, "refresh token got error", "id", t.generator.ID())
+					if !errors.Is(err, errWaitingForLeader) {
+						klog.ErrorS(err, "refresh token got error", "id", t.generator.ID())
+					}
 					continue
 				}
 				// reset
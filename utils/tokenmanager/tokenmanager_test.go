@@ -2,8 +2,10 @@ package tokenmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -86,7 +88,7 @@ func Test_tokenMaintainer_callback(t1 *testing.T) {
 				valueLock:   new(sync.RWMutex),
 			}
 			t.updateCallbacks(tt.fields.cb)
-			if err := t.refreshToken(); err != nil {
+			if _, err := t.refreshToken(); err != nil {
 				t1.Error(err)
 				return
 			}
@@ -394,3 +396,138 @@ func Test_retry(t *testing.T) {
 		})
 	}
 }
+
+// followerStore is a memory store of a replica which is not the leader.
+type followerStore struct {
+	TokenStore
+}
+
+func (followerStore) IsLeader() bool {
+	return false
+}
+
+type countingGenerator struct {
+	test_tokenGeneratorImpl
+	count int32
+}
+
+func (tg *countingGenerator) Generate(ctx context.Context) (string, time.Time, error) {
+	atomic.AddInt32(&tg.count, 1)
+	return tg.test_tokenGeneratorImpl.Generate(ctx)
+}
+
+// failureCountingCallback counts failures reported to it.
+type failureCountingCallback struct {
+	test_callback
+	failures int32
+}
+
+func (c *failureCountingCallback) OnFailure(_ error) {
+	atomic.AddInt32(&c.failures, 1)
+}
+
+func Test_tokenMaintainer_refreshAndCallbackFromStore(t1 *testing.T) {
+	store := followerStore{NewMemoryTokenStore()}
+	generator := &countingGenerator{test_tokenGeneratorImpl: test_tokenGeneratorImpl{id: "t1"}}
+	var callbacks int32
+	t := &tokenMaintainer{
+		name:        generator.ID(),
+		generator:   generator,
+		callbackMap: sync.Map{},
+		stopChan:    make(chan struct{}, 1),
+		valueLock:   new(sync.RWMutex),
+		store:       store,
+	}
+	cb := &failureCountingCallback{test_callback: test_callback{id: "cb", callback: func(token string, expireAt time.Time) error {
+		atomic.AddInt32(&callbacks, 1)
+		return nil
+	}}}
+	t.updateCallbacks(cb)
+
+	if err := t.refreshAndCallback(); !errors.Is(err, errWaitingForLeader) {
+		t1.Errorf("refreshAndCallback() err = %v, want waiting for the leader to store the token", err)
+	}
+
+	// tokens close to expiry are waiting for the leader to rotate them
+	_ = store.Set(context.Background(), "t1", "expiring", time.Now().Add(30*time.Second))
+	if err := t.refreshAndCallback(); !errors.Is(err, errWaitingForLeader) {
+		t1.Errorf("refreshAndCallback() err = %v, want waiting for the leader to rotate the token", err)
+	}
+
+	_ = store.Set(context.Background(), "t1", "shared", time.Now().Add(time.Hour))
+	for i := 0; i < 2; i++ {
+		if err := t.refreshAndCallback(); err != nil {
+			t1.Fatalf("refreshAndCallback() err = %v", err)
+		}
+	}
+
+	if generator.count != 0 {
+		t1.Errorf("followers should not generate tokens, generated %d times", generator.count)
+	}
+	if callbacks != 0 {
+		t1.Errorf("callbacks = %d, want 0 since policies are only updated by the leader", callbacks)
+	}
+	if cb.failures != 0 {
+		t1.Errorf("failures = %d, want 0 while waiting for the leader", cb.failures)
+	}
+	if token, _, _ := t.getValues(); token != "shared" {
+		t1.Errorf("token = %s, want the token in store kept in memory", token)
+	}
+}
+
+type versionedCredentials struct {
+	staticCredentials
+	version *string
+}
+
+func (c versionedCredentials) Version(_ context.Context) (string, error) {
+	return *c.version, nil
+}
+
+func Test_tokenMaintainer_credentialsChanged(t1 *testing.T) {
+	version := "1"
+	t := &tokenMaintainer{
+		generator: NewTokenGeneratorWithCredentials("http://auth", versionedCredentials{version: &version}, 0),
+		store:     NewMemoryTokenStore(),
+	}
+	t.credentialsVersion = version
+
+	if t.credentialsChanged() {
+		t1.Errorf("credentialsChanged() = true, want false before credentials change")
+	}
+
+	version = "2"
+	if !t.credentialsChanged() {
+		t1.Errorf("credentialsChanged() = false, want true after credentials change")
+	}
+
+	t.store = followerStore{NewMemoryTokenStore()}
+	if t.credentialsChanged() {
+		t1.Errorf("credentialsChanged() = true, want false since followers don't generate tokens")
+	}
+
+	t.generator = NewTokenGenerator("http://auth", "u", "p", 0)
+	t.store = nil
+	if t.credentialsChanged() {
+		t1.Errorf("credentialsChanged() = true, want false for static credentials")
+	}
+}
+
+func Test_tokenMaintainer_refreshTokenValidDuration(t1 *testing.T) {
+	t := &tokenMaintainer{
+		generator: &test_tokenGeneratorImpl{id: "t1", defaultExpireDuration: 30 * time.Second},
+		valueLock: new(sync.RWMutex),
+	}
+	// the previous token was fetched long ago, the new one is still too short to use
+	t.setValues("old", time.Now().Add(time.Hour))
+	t.fetchedAt = time.Now().Add(-time.Hour)
+
+	if _, err := t.refreshToken(); err == nil {
+		t1.Errorf("refreshToken() should fail if the token expires in less than %v", minTokenValidDuration)
+	}
+
+	t.generator = &test_tokenGeneratorImpl{id: "t1", defaultExpireDuration: 2 * time.Minute}
+	if changed, err := t.refreshToken(); err != nil || !changed {
+		t1.Errorf("refreshToken() = %v, %v, want changed", changed, err)
+	}
+}
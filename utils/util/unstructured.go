@@ -123,6 +123,16 @@ func ConvertToClusterValidatePolicy(obj *unstructured.Unstructured) (*policyv1al
 	return typedObj, nil
 }
 
+// ConvertToValidatePolicy converts a ValidatePolicy Object from unstructured to typed
+func ConvertToValidatePolicy(obj *unstructured.Unstructured) (*policyv1alpha1.ValidatePolicy, error) {
+	typedObj := &policyv1alpha1.ValidatePolicy{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), typedObj); err != nil {
+		return nil, err
+	}
+
+	return typedObj, nil
+}
+
 // ConvertToClusterOverridePolicy converts a ClusterOverridePolicy Object from unstructured to typed
 func ConvertToClusterOverridePolicy(obj *unstructured.Unstructured) (*policyv1alpha1.ClusterOverridePolicy, error) {
 	typedObj := &policyv1alpha1.ClusterOverridePolicy{}
@@ -0,0 +1,129 @@
+package validatemanager
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
+	"github.com/k-cloud-labs/pkg/utils"
+)
+
+// ValidateMode defines how ValidateManager evaluates matched validate policies.
+type ValidateMode string
+
+const (
+	// ValidateModeFailFast stops evaluating at the first rejection, it's the default mode.
+	ValidateModeFailFast ValidateMode = "FailFast"
+	// ValidateModeAggregate evaluates all matched policies and rules, and returns all violations at once.
+	ValidateModeAggregate ValidateMode = "Aggregate"
+)
+
+// ValidateResult is the result of validate policies applied to an object.
+type ValidateResult struct {
+	// Reason is the message of violations.
+	Reason string `json:"reason"`
+	// Valid is false if there is any violation.
+	Valid bool `json:"valid"`
+	// Violations is the list of rules the object violated, only rules with deny enforcement action are included.
+	Violations []Violation `json:"violations,omitempty"`
+	// Warnings is the list of admission warnings of violated rules with warn enforcement action.
+	Warnings []string `json:"warnings,omitempty"`
+	// SkippedPolicies is the list of policies skipped since their rules failed with failurePolicy Ignore.
+	SkippedPolicies []utils.SkippedPolicy `json:"skippedPolicies,omitempty"`
+}
+
+// Violation describes a validate rule an object violated.
+type Violation struct {
+	// PolicyName is the name of policy, namespaced policy is in the format of namespace/name.
+	// It's empty if the violation is not caused by policy rules.
+	PolicyName string `json:"policyName"`
+	// RuleIndex is the index of rule in validateRules of the policy.
+	RuleIndex int `json:"ruleIndex"`
+	// Message is the reason why the object is rejected.
+	Message string `json:"message,omitempty"`
+	// FieldPath is the path of the field which violated the rule, it may be empty.
+	FieldPath string `json:"fieldPath,omitempty"`
+	// EnforcementAction is the enforcement action of the rule, it's only set by audit.
+	EnforcementAction policyv1alpha1.EnforcementAction `json:"enforcementAction,omitempty"`
+}
+
+// String returns readable text of the violation.
+func (v Violation) String() string {
+	if v.PolicyName == "" {
+		// not a violation of policy rules, e.g. the opt-out annotation is rejected
+		return fmt.Sprintf("field %s: %s", v.FieldPath, v.Message)
+	}
+
+	s := fmt.Sprintf("policy %s rule[%d]", v.PolicyName, v.RuleIndex)
+	if v.FieldPath != "" {
+		s += fmt.Sprintf(" field %s", v.FieldPath)
+	}
+	if v.Message != "" {
+		s += ": " + v.Message
+	}
+
+	return s
+}
+
+// StatusCauses converts violations to causes which can be set in details of admission response status.
+func (r *ValidateResult) StatusCauses() []metav1.StatusCause {
+	causes := make([]metav1.StatusCause, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: v.String(),
+			Field:   v.FieldPath,
+		})
+	}
+
+	return causes
+}
+
+// newValidateResult builds ValidateResult from violations and warnings.
+// Reason keeps the message of violation if there is only one violation,
+// otherwise it contains all violations.
+func newValidateResult(violations []Violation, warnings []string) *ValidateResult {
+	result := &ValidateResult{
+		Valid:      len(violations) == 0,
+		Violations: violations,
+		Warnings:   warnings,
+	}
+
+	switch len(violations) {
+	case 0:
+	case 1:
+		result.Reason = violations[0].Message
+	default:
+		msgs := make([]string, 0, len(violations))
+		for _, v := range violations {
+			msgs = append(msgs, v.String())
+		}
+		result.Reason = strings.Join(msgs, "; ")
+	}
+
+	return result
+}
+
+// ignoredFailureError is a runtime error of a rule with failurePolicy Ignore,
+// the policy of the rule is skipped rather than failing the operation.
+type ignoredFailureError struct {
+	ruleIndex int
+	err       error
+}
+
+func (e *ignoredFailureError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ignoredFailureError) Unwrap() error {
+	return e.err
+}
+
+// ruleResult is the output of cue of validate rule.
+type ruleResult struct {
+	Reason    string `json:"reason"`
+	Valid     bool   `json:"valid"`
+	FieldPath string `json:"fieldPath,omitempty"`
+}
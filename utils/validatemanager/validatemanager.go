@@ -4,102 +4,328 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/client/listers/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cel"
 	"github.com/k-cloud-labs/pkg/utils/cue"
 	"github.com/k-cloud-labs/pkg/utils/dynamiclister"
 	"github.com/k-cloud-labs/pkg/utils/metrics"
+	"github.com/k-cloud-labs/pkg/utils/statusmanager"
 	"github.com/k-cloud-labs/pkg/utils/util"
 )
 
 // ValidateManager managers validate policies for operation
 type ValidateManager interface {
 	// ApplyValidatePolicies validate the object if one or more matched validate policy exist.
+	// The object is rejected if the requesting user in ctx is not allowed to set the utils.SkipPolicies annotation,
+	// and no policy is applied if the object opted out.
+	// Rules are matched with the admission request metadata in ctx, see utils.ContextWithRequestInfo.
+	// Apply order is:
+	// - First apply ClusterValidatePolicy;
+	// - Then apply ValidatePolicy in the namespace of the object;
 	ApplyValidatePolicies(ctx context.Context, obj *unstructured.Unstructured, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (*ValidateResult, error)
+	// AuditValidatePolicy validates an existing object with the policy as a CREATE operation.
+	// It returns violations of all rules regardless of enforcement action,
+	// and neither emits events nor records status and metrics of admission.
+	// skipped is true if the object is not matched or excluded by the policy, or opted out.
+	AuditValidatePolicy(ctx context.Context, policy GeneralValidatePolicy, obj *unstructured.Unstructured) (violations []Violation, skipped bool, err error)
 }
 
+// GeneralValidatePolicy is an abstract object of ClusterValidatePolicy and ValidatePolicy
+type GeneralValidatePolicy interface {
+	// GetName returns the name of ValidatePolicy
+	GetName() string
+	// GetNamespace returns the namespace of ValidatePolicy
+	GetNamespace() string
+	// GetUID returns the UID of ValidatePolicy
+	GetUID() types.UID
+	// GetGeneration returns the generation of ValidatePolicy
+	GetGeneration() int64
+	// GetValidatePolicySpec returns the ClusterValidatePolicySpec of ValidatePolicy
+	GetValidatePolicySpec() policyv1alpha1.ClusterValidatePolicySpec
+}
+
+// auditViolationReason is the reason of event emitted for violations of rules with audit enforcement action.
+const auditViolationReason = "AuditViolation"
+
 type validateManagerImpl struct {
 	dynamicClient dynamiclister.DynamicResourceLister
 	cvpLister     v1alpha1.ClusterValidatePolicyLister
+	vpLister      v1alpha1.ValidatePolicyLister
+	statusManager statusmanager.StatusManager
+	mode          ValidateMode
+	recorder      record.EventRecorder
+	optOut        *utils.OptOutAllowList
+	// audit means the manager is used to audit existing objects.
+	audit bool
 }
 
-type ValidateResult struct {
-	Reason string `json:"reason"`
-	Valid  bool   `json:"valid"`
+// ValidateManagerOptions configures optional settings of ValidateManager.
+type ValidateManagerOptions struct {
+	// StatusManager records status of policies, nil means status of policies will not be recorded.
+	StatusManager statusmanager.StatusManager
+	// Mode is the validate mode, empty value means ValidateModeFailFast.
+	Mode ValidateMode
+	// Recorder emits events for violations of rules with audit enforcement action, nil means no event will be emitted.
+	Recorder record.EventRecorder
+	// OptOut allows users to opt out objects with annotation, nil means the opt-out annotation of objects is ignored.
+	OptOut *utils.OptOutAllowList
 }
 
-func NewValidateManager(dynamicClient dynamiclister.DynamicResourceLister, cvpLister v1alpha1.ClusterValidatePolicyLister) ValidateManager {
+// NewValidateManager returns an implement of ValidateManager.
+// If vpLister is nil, only ClusterValidatePolicy will be applied.
+func NewValidateManager(dynamicClient dynamiclister.DynamicResourceLister, cvpLister v1alpha1.ClusterValidatePolicyLister,
+	vpLister v1alpha1.ValidatePolicyLister, opts ValidateManagerOptions) ValidateManager {
+	if opts.StatusManager == nil {
+		opts.StatusManager = statusmanager.NewNopStatusManager()
+	}
+	if opts.Mode == "" {
+		opts.Mode = ValidateModeFailFast
+	}
+
 	return &validateManagerImpl{
 		dynamicClient: dynamicClient,
 		cvpLister:     cvpLister,
+		vpLister:      vpLister,
+		statusManager: opts.StatusManager,
+		mode:          opts.Mode,
+		recorder:      opts.Recorder,
+		optOut:        opts.OptOut,
 	}
 }
 
 func (m *validateManagerImpl) ApplyValidatePolicies(ctx context.Context, rawObj *unstructured.Unstructured, oldObj *unstructured.Unstructured, operation admissionv1.Operation) (*ValidateResult, error) {
 	defer traceStep(ctx, "ApplyValidatePolicies finished")
+	optedOut, err := utils.ObjectOptedOut(ctx, rawObj, oldObj, m.optOut)
+	if err != nil {
+		klog.V(2).InfoS("Rejected opt-out annotation.", "resource", klog.KObj(rawObj), "operation", operation, "err", err)
+		return newValidateResult([]Violation{{
+			Message:   err.Error(),
+			FieldPath: fmt.Sprintf("metadata.annotations[%s]", utils.SkipPolicies),
+		}}, nil), nil
+	}
+	if optedOut {
+		klog.V(2).InfoS("Skip validate policies for object opted out.", "resource", klog.KObj(rawObj), "operation", operation)
+		return &ValidateResult{
+			Valid: true,
+		}, nil
+	}
+
 	traceStep(ctx, "About to list cvp")
 	cvps, err := m.cvpLister.List(labels.Everything())
 	traceStep(ctx, "List cvp done")
 	if err != nil {
-		klog.ErrorS(err, "Failed to list validate policies.", "resource", klog.KObj(rawObj), "operation", operation)
+		klog.ErrorS(err, "Failed to list cluster validate policies.", "resource", klog.KObj(rawObj), "operation", operation)
 		return nil, err
 	}
 
-	if len(cvps) == 0 {
+	policies := make([]GeneralValidatePolicy, 0, len(cvps))
+	for i := range cvps {
+		policies = append(policies, cvps[i])
+	}
+
+	if rawObj.GetNamespace() != "" && m.vpLister != nil {
+		traceStep(ctx, "About to list vp")
+		vps, err := m.vpLister.ValidatePolicies(rawObj.GetNamespace()).List(labels.Everything())
+		traceStep(ctx, "List vp done")
+		if err != nil {
+			klog.ErrorS(err, "Failed to list validate policies.", "namespace", rawObj.GetNamespace(), "resource", klog.KObj(rawObj), "operation", operation)
+			return nil, err
+		}
+
+		for i := range vps {
+			policies = append(policies, vps[i])
+		}
+	}
+
+	if len(policies) == 0 {
 		klog.V(2).InfoS("No validate policy.", "resource", klog.KObj(rawObj), "operation", operation)
 		return &ValidateResult{
 			Valid: true,
 		}, nil
 	}
 
-	for _, cvp := range cvps {
-		result, err := m.applyValidatePolicy(ctx, cvp, rawObj, oldObj, operation)
+	var (
+		violations []Violation
+		warnings   []string
+		skipped    []utils.SkippedPolicy
+	)
+	for _, policy := range policies {
+		policyViolations, policyWarnings, err := m.applyValidatePolicy(ctx, policy, rawObj, oldObj, operation)
+		var ignored *ignoredFailureError
+		if errors.As(err, &ignored) {
+			klog.ErrorS(err, "Skipped validate policy since failure is ignored.", "validatepolicy", policyName(policy),
+				"ruleIndex", ignored.ruleIndex, "resource", klog.KObj(rawObj), "operation", operation)
+			metrics.PolicyGotError(policyName(policy), rawObj.GroupVersionKind(), metrics.ErrorTypeFailureIgnored)
+			skipped = append(skipped, utils.SkippedPolicy{
+				PolicyName: policyName(policy),
+				RuleIndex:  ignored.ruleIndex,
+				Reason:     err.Error(),
+			})
+			continue
+		}
 		if err != nil {
 			klog.ErrorS(err, "Failed to applyValidatePolicy.",
-				"validatepolicy", cvp.Name, "resource", klog.KObj(rawObj), "operation", operation)
+				"validatepolicy", policyName(policy), "resource", klog.KObj(rawObj), "operation", operation)
 			return nil, err
 		}
 
-		metrics.PolicySuccess(cvp.Name, rawObj.GroupVersionKind())
+		metrics.PolicySuccess(policyName(policy), rawObj.GroupVersionKind())
 
-		if !result.Valid {
-			return result, nil
+		violations = append(violations, policyViolations...)
+		warnings = append(warnings, policyWarnings...)
+		if len(violations) > 0 && m.mode != ValidateModeAggregate {
+			break
 		}
 	}
 
-	return &ValidateResult{
-		Valid: true,
-	}, nil
+	result := newValidateResult(violations, warnings)
+	result.SkippedPolicies = skipped
+	return result, nil
 }
 
-func (m *validateManagerImpl) applyValidatePolicy(ctx context.Context, cvp *policyv1alpha1.ClusterValidatePolicy, rawObj, oldObj *unstructured.Unstructured,
-	operation admissionv1.Operation) (*ValidateResult, error) {
-	if len(cvp.Spec.ResourceSelectors) > 0 && !utils.ResourceMatchSelectors(rawObj, cvp.Spec.ResourceSelectors...) {
+func (m *validateManagerImpl) AuditValidatePolicy(ctx context.Context, policy GeneralValidatePolicy, obj *unstructured.Unstructured) ([]Violation, bool, error) {
+	if optedOut, _ := utils.ObjectOptedOut(ctx, obj, obj, m.optOut); optedOut {
+		return nil, true, nil
+	}
+
+	auditor := &validateManagerImpl{
+		dynamicClient: m.dynamicClient,
+		statusManager: statusmanager.NewNopStatusManager(),
+		mode:          ValidateModeAggregate,
+		audit:         true,
+	}
+	if !auditor.matchesPolicy(ctx, policy, obj) {
+		return nil, true, nil
+	}
+
+	violations, _, err := auditor.applyValidatePolicy(ctx, policy, obj, nil, admissionv1.Create)
+	return violations, false, err
+}
+
+// matchContext returns the context used to match resource selectors of policies, objects are got with ctx.
+func (m *validateManagerImpl) matchContext(ctx context.Context) *utils.MatchContext {
+	return dynamiclister.NewMatchContext(ctx, m.dynamicClient)
+}
+
+// matchesPolicy returns true if rawObj is matched and not excluded by resource selectors of the policy.
+func (m *validateManagerImpl) matchesPolicy(ctx context.Context, policy GeneralValidatePolicy, rawObj *unstructured.Unstructured) bool {
+	spec := policy.GetValidatePolicySpec()
+	mc := m.matchContext(ctx)
+	if len(spec.ResourceSelectors) > 0 && !utils.ResourceMatchSelectorsWithContext(rawObj, mc, spec.ResourceSelectors...) {
 		//no matched
-		return &ValidateResult{Valid: true}, nil
+		return false
 	}
 
-	metrics.ValidatePolicyMatched(cvp.Name, rawObj.GroupVersionKind())
-	klog.V(4).InfoS("resource matched a validate policy", "operation", operation, "policy", cvp.GroupVersionKind(),
+	if len(spec.ExcludeResourceSelectors) > 0 && utils.ResourceMatchSelectorsWithContext(rawObj, mc, spec.ExcludeResourceSelectors...) {
+		klog.V(2).InfoS("Resource excluded by validate policy.", "validatepolicy", policyName(policy), "resource", klog.KObj(rawObj))
+		return false
+	}
+
+	return true
+}
+
+// applyValidatePolicy applies validate rules of the policy to the object and returns violations and warnings,
+// it returns at the first violation in ValidateModeFailFast mode.
+func (m *validateManagerImpl) applyValidatePolicy(ctx context.Context, policy GeneralValidatePolicy, rawObj, oldObj *unstructured.Unstructured,
+	operation admissionv1.Operation) (_ []Violation, _ []string, err error) {
+	spec := policy.GetValidatePolicySpec()
+	if !m.matchesPolicy(ctx, policy, rawObj) {
+		return nil, nil, nil
+	}
+
+	name := policyName(policy)
+	if timeout := utils.PolicyTimeout(spec.TimeoutSeconds); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		defer func() {
+			var timeoutErr *utils.PolicyTimeoutError
+			if err = utils.NewPolicyTimeoutError(ctx, name, timeout, err); errors.As(err, &timeoutErr) {
+				metrics.PolicyGotError(name, rawObj.GroupVersionKind(), metrics.ErrorTypeTimeout)
+			}
+		}()
+	}
+	if !m.audit {
+		metrics.ValidatePolicyMatched(name, rawObj.GroupVersionKind())
+	}
+	statusKey := policyStatusKey(policy)
+	programKey := cue.ProgramKey{UID: policy.GetUID(), Generation: policy.GetGeneration()}
+	m.statusManager.RecordMatched(statusKey)
+	klog.V(4).InfoS("resource matched a validate policy", "operation", operation, "policy", name,
 		"resource", fmt.Sprintf("%v/%v/%v", rawObj.GroupVersionKind(), rawObj.GetNamespace(), rawObj.GetName()))
-	for _, rule := range cvp.Spec.ValidateRules {
+
+	var (
+		violations []Violation
+		warnings   []string
+	)
+	// reject handles a violated rule by its enforcement action, returns true if no need to evaluate other rules.
+	reject := func(index int, rule *policyv1alpha1.ValidateRuleWithOperation, result *ruleResult) bool {
+		violation := Violation{
+			PolicyName: name,
+			RuleIndex:  index,
+			Message:    result.Reason,
+			FieldPath:  result.FieldPath,
+		}
+		action := enforcementAction(&spec, rule)
+		if m.audit {
+			violation.EnforcementAction = action
+			violations = append(violations, violation)
+			return false
+		}
+
+		metrics.ValidatePolicyReject(name, rawObj.GroupVersionKind(), string(action))
+		switch action {
+		case policyv1alpha1.EnforcementActionWarn:
+			warnings = append(warnings, violation.String())
+			return false
+		case policyv1alpha1.EnforcementActionAudit:
+			m.recordAuditEvent(policy, rawObj, operation, violation)
+			return false
+		default:
+			violations = append(violations, violation)
+			return m.mode != ValidateModeAggregate
+		}
+	}
+
+	// fail records the runtime error of a rule, and marks the error as ignored if the rule ignores failures.
+	fail := func(index int, rule *policyv1alpha1.ValidateRuleWithOperation, err error) error {
+		m.statusManager.RecordError(statusKey, err)
+		if utils.IgnoreFailure(rule.FailurePolicy) {
+			return &ignoredFailureError{ruleIndex: index, err: err}
+		}
+
+		return err
+	}
+
+	ri := utils.RequestInfoFromContext(ctx)
+	for i, rule := range spec.ValidateRules {
 		if len(rule.TargetOperations) > 0 && !util.Exists(rule.TargetOperations, operation) {
 			// no matched
 			continue
 		}
+		if !utils.RequestMatches(ri, rule.RequestMatcher) {
+			continue
+		}
 		if operation != admissionv1.Update {
 			oldObj = nil
 		}
 
+		// extraParams is resolved from valueRef of template, and shared with the cel expression of the rule
+		var extraParams map[string]any
 		if rule.Template != nil && rule.RenderedCue != "" {
 			params := &cue.CueParams{
 				Object:    rawObj,
@@ -107,50 +333,107 @@ func (m *validateManagerImpl) applyValidatePolicy(ctx context.Context, cvp *poli
 			}
 
 			traceStep(ctx, "Before execute template cue")
-			result, err := m.executeTemplate(params, &rule, cvp.Name)
+			result, err := m.executeTemplate(ctx, params, &rule, statusKey, programKey)
 			traceStep(ctx, "After execute template cue")
 			if err != nil {
 				klog.ErrorS(err, "Failed to execute rendered cue.",
-					"validatepolicy", cvp.Name, "resource", klog.KObj(rawObj), "operation", operation)
-				return nil, err
+					"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+				return nil, nil, fail(i, &rule, err)
 			}
+			extraParams = params.ExtraParams
 
 			if result != nil {
 				klog.V(2).InfoS("Applied validate policy.",
-					"validatepolicy", cvp.Name, "resource", klog.KObj(rawObj), "operation", operation)
-				if !result.Valid {
-					metrics.ValidatePolicyReject(cvp.Name, rawObj.GroupVersionKind())
-					return result, nil
+					"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+				if !result.Valid && reject(i, &rule, result) {
+					m.statusManager.RecordApplied(statusKey)
+					return violations, warnings, nil
 				}
 			}
 		}
 
 		if rule.Cue != "" {
 			traceStep(ctx, "Before execute normal cue")
-			result, err := executeCue(rawObj, oldObj, rule.Cue)
+			result, err := executeCue(ctx, programKey, rawObj, oldObj, rule.Cue)
 			traceStep(ctx, "After execute normal cue")
 			if err != nil {
 				metrics.PolicyGotError(rawObj.GetName(), rawObj.GroupVersionKind(), metrics.ErrorTypeCueExecute)
 				klog.ErrorS(err, "Failed to apply validate policy.",
-					"validatepolicy", cvp.Name, "resource", klog.KObj(rawObj), "operation", operation)
-				return nil, err
+					"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+				return nil, nil, fail(i, &rule, err)
+			}
+			klog.V(2).InfoS("Applied validate policy.",
+				"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+			if !result.Valid && reject(i, &rule, result) {
+				m.statusManager.RecordApplied(statusKey)
+				return violations, warnings, nil
+			}
+		}
+
+		if rule.Cel != "" {
+			traceStep(ctx, "Before execute cel")
+			result, err := executeCel(ctx, rawObj, oldObj, rule.Cel, extraParams, cel.NewRequest(operation, ri))
+			traceStep(ctx, "After execute cel")
+			if err != nil {
+				metrics.PolicyGotError(name, rawObj.GroupVersionKind(), metrics.ErrorTypeCelExecute)
+				klog.ErrorS(err, "Failed to apply validate policy.",
+					"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+				return nil, nil, fail(i, &rule, err)
 			}
 			klog.V(2).InfoS("Applied validate policy.",
-				"validatepolicy", cvp.Name, "resource", klog.KObj(rawObj), "operation", operation)
-			if !result.Valid {
-				metrics.ValidatePolicyReject(cvp.Name, rawObj.GroupVersionKind())
-				return result, nil
+				"validatepolicy", name, "resource", klog.KObj(rawObj), "operation", operation)
+			if !result.Valid && reject(i, &rule, result) {
+				m.statusManager.RecordApplied(statusKey)
+				return violations, warnings, nil
 			}
 		}
 	}
 
-	return &ValidateResult{
-		Valid: true,
-	}, nil
+	m.statusManager.RecordApplied(statusKey)
+	return violations, warnings, nil
+}
+
+// recordAuditEvent emits a warning event on the policy for a violated rule with audit enforcement action.
+func (m *validateManagerImpl) recordAuditEvent(policy GeneralValidatePolicy, rawObj *unstructured.Unstructured,
+	operation admissionv1.Operation, violation Violation) {
+	klog.V(2).InfoS("Resource violated audit rule.", "validatepolicy", violation.PolicyName, "ruleIndex", violation.RuleIndex,
+		"resource", klog.KObj(rawObj), "operation", operation, "message", violation.Message)
+	if m.recorder == nil {
+		return
+	}
+
+	obj, ok := policy.(runtime.Object)
+	if !ok {
+		return
+	}
+
+	m.recorder.Eventf(obj, corev1.EventTypeWarning, auditViolationReason, "%s %s %s/%s violated rule[%d]: %s",
+		operation, rawObj.GetKind(), rawObj.GetNamespace(), rawObj.GetName(), violation.RuleIndex, violation.Message)
+}
+
+// enforcementAction returns the enforcement action of rule, it falls back to the one of policy if not set.
+func enforcementAction(spec *policyv1alpha1.ClusterValidatePolicySpec, rule *policyv1alpha1.ValidateRuleWithOperation) policyv1alpha1.EnforcementAction {
+	if rule.EnforcementAction != "" {
+		return rule.EnforcementAction
+	}
+	if spec.EnforcementAction != "" {
+		return spec.EnforcementAction
+	}
+
+	return policyv1alpha1.EnforcementActionDeny
 }
 
-func (m *validateManagerImpl) executeTemplate(params *cue.CueParams, rule *policyv1alpha1.ValidateRuleWithOperation, cvpName string) (*ValidateResult, error) {
-	extraParams, err := cue.BuildCueParamsViaValidatePolicy(m.dynamicClient, params.Object, rule.Template)
+func (m *validateManagerImpl) executeTemplate(ctx context.Context, params *cue.CueParams, rule *policyv1alpha1.ValidateRuleWithOperation, statusKey statusmanager.PolicyKey,
+	programKey cue.ProgramKey) (*ruleResult, error) {
+	cvpName := statusKey.Name
+	if statusKey.Namespace != "" {
+		cvpName = statusKey.Namespace + "/" + statusKey.Name
+	}
+	extraParams, err := cue.BuildCueParamsViaValidatePolicy(ctx, m.dynamicClient, params.Object, rule.Template)
+	if rule.Template.Condition != nil && (rule.Template.Condition.DataRef != nil || rule.Template.Condition.ValueRef != nil) {
+		m.statusManager.SetCondition(statusKey, statusmanager.NewCondition(policyv1alpha1.PolicyConditionReferencesResolved,
+			policyv1alpha1.PolicyReasonResolved, policyv1alpha1.PolicyReasonResolveFailed, err))
+	}
 	if err != nil {
 		metrics.PolicyGotError(cvpName, params.Object.GroupVersionKind(), metrics.ErrTypePrepareCueParams)
 		klog.ErrorS(err, "Failed to build validate policy params.",
@@ -169,7 +452,7 @@ func (m *validateManagerImpl) executeTemplate(params *cue.CueParams, rule *polic
 
 	}
 	params.ExtraParams = extraParams.ExtraParams
-	result, err := executeCueV2(rule.RenderedCue, []cue.Parameter{
+	result, err := executeCueV2(ctx, programKey, rule.RenderedCue, []cue.Parameter{
 		{
 			Name:   utils.DataParameterName,
 			Object: params,
@@ -188,14 +471,36 @@ func (m *validateManagerImpl) executeTemplate(params *cue.CueParams, rule *polic
 		result.Valid = !result.Valid
 	}
 
+	if !result.Valid && result.FieldPath == "" && rule.Template != nil && rule.Template.Condition != nil &&
+		rule.Template.Condition.DataRef != nil && rule.Template.Condition.DataRef.From == policyv1alpha1.FromCurrentObject {
+		result.FieldPath = rule.Template.Condition.DataRef.Path
+	}
+
 	return result, nil
 }
 
-func executeCueV2(cueStr string, parameters []cue.Parameter) (*ValidateResult, error) {
-	result := ValidateResult{
+// policyName returns name of cluster scoped policy or namespace/name of namespaced policy.
+func policyName(policy GeneralValidatePolicy) string {
+	if policy.GetNamespace() == "" {
+		return policy.GetName()
+	}
+
+	return policy.GetNamespace() + "/" + policy.GetName()
+}
+
+func policyStatusKey(policy GeneralValidatePolicy) statusmanager.PolicyKey {
+	if policy.GetNamespace() == "" {
+		return statusmanager.PolicyKey{Kind: statusmanager.KindClusterValidatePolicy, Name: policy.GetName()}
+	}
+
+	return statusmanager.PolicyKey{Kind: statusmanager.KindValidatePolicy, Namespace: policy.GetNamespace(), Name: policy.GetName()}
+}
+
+func executeCueV2(ctx context.Context, key cue.ProgramKey, cueStr string, parameters []cue.Parameter) (*ruleResult, error) {
+	result := ruleResult{
 		Valid: true,
 	}
-	if err := cue.CueDoAndReturn(cueStr, parameters, utils.ValidateOutputName, &result); err != nil {
+	if err := cueDoAndReturn(ctx, key, cueStr, parameters, &result); err != nil {
 		return nil, err
 	}
 
@@ -203,8 +508,8 @@ func executeCueV2(cueStr string, parameters []cue.Parameter) (*ValidateResult, e
 	return &result, nil
 }
 
-func executeCue(rawObj *unstructured.Unstructured, oldObj *unstructured.Unstructured, template string) (*ValidateResult, error) {
-	result := ValidateResult{
+func executeCue(ctx context.Context, key cue.ProgramKey, rawObj *unstructured.Unstructured, oldObj *unstructured.Unstructured, template string) (*ruleResult, error) {
+	result := ruleResult{
 		Valid: true,
 	}
 	parameters := []cue.Parameter{
@@ -220,7 +525,7 @@ func executeCue(rawObj *unstructured.Unstructured, oldObj *unstructured.Unstruct
 			Object: oldObj,
 		})
 	}
-	if err := cue.CueDoAndReturn(template, parameters, utils.ValidateOutputName, &result); err != nil {
+	if err := cueDoAndReturn(ctx, key, template, parameters, &result); err != nil {
 		return nil, err
 	}
 
@@ -228,6 +533,53 @@ func executeCue(rawObj *unstructured.Unstructured, oldObj *unstructured.Unstruct
 	return &result, nil
 }
 
+// cueDoAndReturn executes the cue template with the program compiled once for the policy generation of key.
+func cueDoAndReturn(ctx context.Context, key cue.ProgramKey, template string, parameters []cue.Parameter, output interface{}) error {
+	p, err := cue.DefaultProgramCache.Program(key, template)
+	if err != nil {
+		return err
+	}
+
+	return p.DoAndReturn(ctx, parameters, utils.ValidateOutputName, output)
+}
+
+// executeCel evaluates the CEL expression of a validate rule, the result is either a bool, true means valid,
+// or a string, empty means valid and others are the reason, or a map in the form of ruleResult.
+func executeCel(ctx context.Context, rawObj, oldObj *unstructured.Unstructured, expression string, extraParams map[string]any,
+	request *cel.Request) (*ruleResult, error) {
+	var output interface{}
+	if err := cel.CelDoAndReturn(ctx, expression, &cel.Parameters{
+		Object:      rawObj,
+		OldObject:   oldObj,
+		ExtraParams: extraParams,
+		Request:     request,
+	}, &output); err != nil {
+		return nil, err
+	}
+
+	switch v := output.(type) {
+	case bool:
+		return &ruleResult{Valid: v}, nil
+	case string:
+		return &ruleResult{Valid: v == "", Reason: v}, nil
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result := ruleResult{
+			Valid: true,
+		}
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("unsupported result %v of cel expression, expect bool, string or map", output)
+	}
+}
+
 func getPodPhase(obj *unstructured.Unstructured) corev1.PodPhase {
 	if obj == nil {
 		return ""
@@ -8,16 +8,19 @@ import (
 
 	"github.com/golang/mock/gomock"
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	policyv1alpha1 "github.com/k-cloud-labs/pkg/apis/policy/v1alpha1"
 	"github.com/k-cloud-labs/pkg/test/helper"
 	"github.com/k-cloud-labs/pkg/test/mock"
 	"github.com/k-cloud-labs/pkg/utils"
+	"github.com/k-cloud-labs/pkg/utils/cel"
 	"github.com/k-cloud-labs/pkg/utils/cue"
 	utilhelper "github.com/k-cloud-labs/pkg/utils/util"
 )
@@ -92,6 +95,27 @@ validate: {
         valid:   false
     }
 }
+`,
+				}}}}
+
+	validatePolicy4 := &policyv1alpha1.ValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "validatepolicy4",
+		},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					TargetOperations: []admissionv1.Operation{admissionv1.Create},
+					Cue: `
+object: _ @tag(object)
+
+validate: {
+	if object.metadata.name == "test" {
+		valid:  false
+		reason: "name test is reserved"
+	}
+}
 `,
 				}}}}
 
@@ -111,6 +135,12 @@ validate: {
 			wantedErr: nil,
 			wantedResult: &ValidateResult{
 				Valid: false,
+				Violations: []Violation{
+					{
+						PolicyName: validatePolicy3.Name,
+						RuleIndex:  0,
+					},
+				},
 			},
 		},
 		{
@@ -131,6 +161,30 @@ validate: {
 			wantedErr: nil,
 			wantedResult: &ValidateResult{
 				Valid: false,
+				Violations: []Violation{
+					{
+						PolicyName: validatePolicy3.Name,
+						RuleIndex:  0,
+					},
+				},
+			},
+		},
+		{
+			name:      "ut-validate-policy-namespaced-create",
+			operation: admissionv1.Create,
+			object:    podObj,
+			oldObject: nil,
+			wantedErr: nil,
+			wantedResult: &ValidateResult{
+				Reason: "name test is reserved",
+				Valid:  false,
+				Violations: []Violation{
+					{
+						PolicyName: "default/validatepolicy4",
+						RuleIndex:  0,
+						Message:    "name test is reserved",
+					},
+				},
 			},
 		},
 	}
@@ -139,7 +193,14 @@ validate: {
 	defer ctrl.Finish()
 
 	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
-	m := NewValidateManager(nil, cvpLister)
+	vpLister := mock.NewMockValidatePolicyLister(ctrl)
+	vpNamespaceLister := mock.NewMockValidatePolicyNamespaceLister(ctrl)
+	m := NewValidateManager(nil, cvpLister, vpLister, ValidateManagerOptions{})
+
+	vpLister.EXPECT().ValidatePolicies(metav1.NamespaceDefault).Return(vpNamespaceLister).AnyTimes()
+	vpNamespaceLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ValidatePolicy{
+		validatePolicy4,
+	}, nil).AnyTimes()
 
 	cvpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterValidatePolicy{
 		validatePolicy1,
@@ -157,6 +218,147 @@ validate: {
 	}
 }
 
+func TestValidateManagerImpl_ApplyValidatePolicies_Aggregate(t *testing.T) {
+	pod := helper.NewPod(metav1.NamespaceDefault, "test")
+	podObj, _ := utilhelper.ToUnstructured(pod)
+
+	rejectCue := `
+object: _ @tag(object)
+
+validate: {
+	valid:     false
+	reason:    "name is invalid"
+	fieldPath: "metadata.name"
+}
+`
+	cvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cvp",
+		},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					TargetOperations: []admissionv1.Operation{admissionv1.Delete},
+					Cue:              rejectCue,
+				},
+				{
+					Cue: rejectCue,
+				},
+			}}}
+	vp := &policyv1alpha1.ValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "vp",
+		},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					Cue: rejectCue,
+				},
+			}}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
+	cvpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterValidatePolicy{cvp}, nil).AnyTimes()
+	vpLister := mock.NewMockValidatePolicyLister(ctrl)
+	vpNamespaceLister := mock.NewMockValidatePolicyNamespaceLister(ctrl)
+	vpLister.EXPECT().ValidatePolicies(metav1.NamespaceDefault).Return(vpNamespaceLister).AnyTimes()
+	vpNamespaceLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ValidatePolicy{vp}, nil).AnyTimes()
+
+	tests := []struct {
+		name           string
+		mode           ValidateMode
+		wantViolations []Violation
+	}{
+		{
+			name: "fail fast",
+			mode: ValidateModeFailFast,
+			wantViolations: []Violation{
+				{PolicyName: "cvp", RuleIndex: 1, Message: "name is invalid", FieldPath: "metadata.name"},
+			},
+		},
+		{
+			name: "aggregate",
+			mode: ValidateModeAggregate,
+			wantViolations: []Violation{
+				{PolicyName: "cvp", RuleIndex: 1, Message: "name is invalid", FieldPath: "metadata.name"},
+				{PolicyName: "default/vp", RuleIndex: 0, Message: "name is invalid", FieldPath: "metadata.name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewValidateManager(nil, cvpLister, vpLister, ValidateManagerOptions{Mode: tt.mode})
+			result, err := m.ApplyValidatePolicies(context.Background(), podObj, nil, admissionv1.Create)
+			if err != nil {
+				t.Fatalf("ApplyValidatePolicies() err=%v", err)
+			}
+			if result.Valid || !reflect.DeepEqual(result.Violations, tt.wantViolations) {
+				t.Errorf("ApplyValidatePolicies() = %+v, want violations %+v", result, tt.wantViolations)
+			}
+			if len(result.StatusCauses()) != len(tt.wantViolations) {
+				t.Errorf("StatusCauses() = %v, want %v causes", result.StatusCauses(), len(tt.wantViolations))
+			}
+		})
+	}
+}
+
+func TestValidateManagerImpl_ApplyValidatePolicies_EnforcementAction(t *testing.T) {
+	pod := helper.NewPod(metav1.NamespaceDefault, "test")
+	podObj, _ := utilhelper.ToUnstructured(pod)
+
+	rejectCue := `
+object: _ @tag(object)
+
+validate: {
+	valid:  false
+	reason: "name is invalid"
+}
+`
+	cvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cvp",
+		},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					Cue: rejectCue,
+				},
+				{
+					Cue:               rejectCue,
+					EnforcementAction: policyv1alpha1.EnforcementActionAudit,
+				},
+			},
+			EnforcementAction: policyv1alpha1.EnforcementActionWarn,
+		}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
+	cvpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterValidatePolicy{cvp}, nil).AnyTimes()
+
+	recorder := record.NewFakeRecorder(10)
+	m := NewValidateManager(nil, cvpLister, nil, ValidateManagerOptions{Recorder: recorder})
+	result, err := m.ApplyValidatePolicies(context.Background(), podObj, nil, admissionv1.Create)
+	if err != nil {
+		t.Fatalf("ApplyValidatePolicies() err=%v", err)
+	}
+
+	want := &ValidateResult{
+		Valid:    true,
+		Warnings: []string{"policy cvp rule[0]: name is invalid"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("ApplyValidatePolicies() = %+v, want %+v", result, want)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expect 1 audit event, but got %v", len(recorder.Events))
+	}
+}
+
 func Test_executeCueV2(t *testing.T) {
 	type args struct {
 		cueStr     string
@@ -165,7 +367,7 @@ func Test_executeCueV2(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    *ValidateResult
+		want    *ruleResult
 		wantErr bool
 	}{
 		{
@@ -191,7 +393,7 @@ if data.object.name == "cue" {
 					},
 				},
 			},
-			want: &ValidateResult{
+			want: &ruleResult{
 				Reason: "name cannot be cue",
 				Valid:  false,
 			},
@@ -200,7 +402,7 @@ if data.object.name == "cue" {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := executeCueV2(tt.args.cueStr, tt.args.parameters)
+			got, err := executeCueV2(context.Background(), cue.ProgramKey{}, tt.args.cueStr, tt.args.parameters)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("executeCueV2() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -212,6 +414,61 @@ if data.object.name == "cue" {
 	}
 }
 
+func Test_executeCel(t *testing.T) {
+	pod, _ := utilhelper.ToUnstructured(helper.NewPod(metav1.NamespaceDefault, "cel"))
+	tests := []struct {
+		name       string
+		expression string
+		request    *cel.Request
+		want       *ruleResult
+		wantErr    bool
+	}{
+		{
+			name:       "bool",
+			expression: `object.metadata.name != "cel"`,
+			want:       &ruleResult{Valid: false},
+		},
+		{
+			name:       "empty string",
+			expression: `object.metadata.name == "cel" ? "" : "invalid name"`,
+			want:       &ruleResult{Valid: true},
+		},
+		{
+			name:       "string",
+			expression: `request.operation == "DELETE" ? "cannot be deleted" : ""`,
+			request:    cel.NewRequest(admissionv1.Delete, nil),
+			want:       &ruleResult{Valid: false, Reason: "cannot be deleted"},
+		},
+		{
+			name:       "map",
+			expression: `{"valid": false, "reason": "name cannot be cel", "fieldPath": "metadata.name"}`,
+			want:       &ruleResult{Valid: false, Reason: "name cannot be cel", FieldPath: "metadata.name"},
+		},
+		{
+			name:       "map without valid",
+			expression: `{"reason": "ignored"}`,
+			want:       &ruleResult{Valid: true, Reason: "ignored"},
+		},
+		{
+			name:       "unsupported result",
+			expression: `1`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeCel(context.Background(), pod, nil, tt.expression, nil, tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("executeCel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("executeCel() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_getPodPhase(t *testing.T) {
 	type args struct {
 		obj *unstructured.Unstructured
@@ -301,3 +558,183 @@ func Test_getPodPhase(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateManagerImpl_ApplyValidatePolicies_Exclude(t *testing.T) {
+	rejectCue := `
+object: _ @tag(object)
+
+validate: {
+	valid:  false
+	reason: "rejected"
+}
+`
+	cvp := &policyv1alpha1.ClusterValidatePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cvp",
+		},
+		Spec: policyv1alpha1.ClusterValidatePolicySpec{
+			ResourceSelectors: []policyv1alpha1.ResourceSelector{
+				{APIVersion: "v1", Kind: "Pod"},
+			},
+			ExcludeResourceSelectors: []policyv1alpha1.ResourceSelector{
+				{APIVersion: "v1", Kind: "Pod", Namespace: "kube-system"},
+			},
+			ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+				{
+					Cue: rejectCue,
+				},
+			}}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
+	cvpLister.EXPECT().List(labels.Everything()).Return([]*policyv1alpha1.ClusterValidatePolicy{cvp}, nil).AnyTimes()
+	allowList := &utils.OptOutAllowList{Groups: []string{"system:masters"}}
+	m := NewValidateManager(nil, cvpLister, nil, ValidateManagerOptions{OptOut: allowList})
+
+	newPod := func(namespace string, optOut bool) *unstructured.Unstructured {
+		pod := helper.NewPod(namespace, "test")
+		if optOut {
+			pod.Annotations = map[string]string{utils.SkipPolicies: utils.SkipPoliciesValue}
+		}
+		obj, _ := utilhelper.ToUnstructured(pod)
+		return obj
+	}
+	admin := authenticationv1.UserInfo{Username: "admin", Groups: []string{"system:masters"}}
+	developer := authenticationv1.UserInfo{Username: "developer", Groups: []string{"dev"}}
+
+	tests := []struct {
+		name      string
+		userInfo  authenticationv1.UserInfo
+		object    *unstructured.Unstructured
+		oldObject *unstructured.Unstructured
+		operation admissionv1.Operation
+		wantValid bool
+	}{
+		{
+			name:      "matched",
+			object:    newPod(metav1.NamespaceDefault, false),
+			operation: admissionv1.Create,
+			wantValid: false,
+		},
+		{
+			name:      "excluded",
+			object:    newPod("kube-system", false),
+			operation: admissionv1.Create,
+			wantValid: true,
+		},
+		{
+			name:      "opted out by allowed user",
+			userInfo:  admin,
+			object:    newPod(metav1.NamespaceDefault, true),
+			operation: admissionv1.Create,
+			wantValid: true,
+		},
+		{
+			name:      "opted out by user not allowed",
+			userInfo:  developer,
+			object:    newPod(metav1.NamespaceDefault, true),
+			operation: admissionv1.Create,
+			wantValid: false,
+		},
+		{
+			name:      "opted out before",
+			userInfo:  developer,
+			object:    newPod(metav1.NamespaceDefault, true),
+			oldObject: newPod(metav1.NamespaceDefault, true),
+			operation: admissionv1.Update,
+			wantValid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := utils.ContextWithRequestInfo(context.Background(), &utils.RequestInfo{UserInfo: tt.userInfo})
+			result, err := m.ApplyValidatePolicies(ctx, tt.object, tt.oldObject, tt.operation)
+			if err != nil {
+				t.Fatalf("ApplyValidatePolicies() err=%v", err)
+			}
+			if result.Valid != tt.wantValid {
+				t.Errorf("ApplyValidatePolicies() = %+v, want valid %v", result, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestValidateManagerImpl_ApplyValidatePolicies_FailurePolicy(t *testing.T) {
+	newPolicies := func(failurePolicy policyv1alpha1.FailurePolicyType) []*policyv1alpha1.ClusterValidatePolicy {
+		return []*policyv1alpha1.ClusterValidatePolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "broken",
+				},
+				Spec: policyv1alpha1.ClusterValidatePolicySpec{
+					ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+						{
+							Cel:           `object.spec.notExist == 1`,
+							FailurePolicy: failurePolicy,
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "reject",
+				},
+				Spec: policyv1alpha1.ClusterValidatePolicySpec{
+					ValidateRules: []policyv1alpha1.ValidateRuleWithOperation{
+						{
+							Cel: `"rejected"`,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		failurePolicy policyv1alpha1.FailurePolicyType
+		wantErr       bool
+	}{
+		{
+			name:    "fail by default",
+			wantErr: true,
+		},
+		{
+			name:          "fail",
+			failurePolicy: policyv1alpha1.FailurePolicyFail,
+			wantErr:       true,
+		},
+		{
+			name:          "ignore",
+			failurePolicy: policyv1alpha1.FailurePolicyIgnore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cvpLister := mock.NewMockClusterValidatePolicyLister(ctrl)
+			cvpLister.EXPECT().List(labels.Everything()).Return(newPolicies(tt.failurePolicy), nil).AnyTimes()
+			m := NewValidateManager(nil, cvpLister, nil, ValidateManagerOptions{})
+
+			obj, _ := utilhelper.ToUnstructured(helper.NewPod(metav1.NamespaceDefault, "test"))
+			result, err := m.ApplyValidatePolicies(context.Background(), obj, nil, admissionv1.Create)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyValidatePolicies() err=%v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if result.Valid || result.Reason != "rejected" {
+				t.Errorf("ApplyValidatePolicies() = %+v, want rejected by other policies", result)
+			}
+			if len(result.SkippedPolicies) != 1 || result.SkippedPolicies[0].PolicyName != "broken" || result.SkippedPolicies[0].RuleIndex != 0 {
+				t.Errorf("ApplyValidatePolicies() skipped=%v, want the broken policy", result.SkippedPolicies)
+			}
+		})
+	}
+}